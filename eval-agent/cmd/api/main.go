@@ -39,7 +39,7 @@ func main() {
 		os.Exit(1)
 	}
 	// API
-	handler := api.NewHandler(deps.Executor, deps.JudgeExecutor, &logger)
+	handler := api.NewHandler(deps.Executor, deps.JudgeExecutor, deps.ComparisonExecutor, &logger)
 	container := restful.NewContainer()
 	container.Filter(middleware.Logger)
 	container.Filter(middleware.RecoverPanic)
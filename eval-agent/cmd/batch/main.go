@@ -3,16 +3,21 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/batch"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/setup"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -26,13 +31,21 @@ func main() {
 
 	input := flag.String("input", "", "Input file relative path")
 	output := flag.String("output", "", "Output file relative path")
-	format := flag.String("format", "jsonl", "Output file format. Supported formats: 'jsonl', 'summary'")
-	summary := flag.String("summary", "", "Optional separate summary file")
+	format := flag.String("format", "jsonl", "Output file format. Supported formats: 'jsonl', 'json', 'csv', 'html', 'summary'")
+	summary := flag.String("summary", "", "Optional separate summary file, written alongside any format")
 	workers := flag.Int("workers", 5, "Concurrent evaluators workers")
 	continueOnError := flag.Bool("continue-on-error", true, "Continue on evaluation failures")
 	dryRun := flag.Bool("dry-run", false, "Validate input without evaluating")
 	validate := flag.Bool("validate", false, "Validation mode: compute correlation with human annotations")
 	corrThreshold := flag.Float64("correlation-threshold", 0.3, "Kendall's tau threshold for validation")
+	judgeCache := flag.String("cache", "", "Judge result cache backend: 'file' or 'redis' (default: JUDGE_CACHE)")
+	cacheDir := flag.String("cache-dir", "", "Directory of the file judge cache (default: JUDGE_CACHE_DIR or .cache/judges)")
+	compare := flag.String("compare", "", "Comparison mode: baseline file whose answers are compared with -input, paired by event_id")
+	compareJudges := flag.String("compare-judges", "", "Comma-separated judges used by -compare (default: all)")
+	progressInterval := flag.Duration("progress", 10*time.Second, "Interval of progress reports, 0 disables them")
+	checkpointPath := flag.String("checkpoint", "", "Checkpoint file of completed event_ids (default: <output>.checkpoint)")
+	resume := flag.Bool("resume", false, "Skip the records completed in the checkpoint and append to -output")
+	force := flag.Bool("force", false, "Resume even if the judges, prechecks, aggregation or profiles config changed")
 
 	flag.Parse()
 
@@ -41,6 +54,13 @@ func main() {
 	}
 	formatValidator(format)
 
+	if *checkpointPath == "" && *output != "" {
+		*checkpointPath = *output + ".checkpoint"
+	}
+	if *resume && (*output == "" || *format != "jsonl") {
+		log.Fatal().Msg("-resume requires -output with the jsonl format")
+	}
+
 	if err := godotenv.Load(); err != nil {
 		log.Warn().Msg("No .env file found, using environment variables")
 	}
@@ -49,14 +69,21 @@ func main() {
 	defer cancel()
 
 	cfg := setup.LoadConfig()
+	if *judgeCache != "" {
+		cfg.JudgeCache = *judgeCache
+	}
+	if *cacheDir != "" {
+		cfg.JudgeCacheDir = *cacheDir
+	}
 
 	deps, err := setup.Wire(ctx, cfg, &log.Logger)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to wire dependencies")
 	}
 
-	// Open input file
+	// Open input file, its size is unknown on stdin
 	var inputFile io.Reader
+	var inputSize int64
 	if *input == "-" {
 		inputFile = os.Stdin
 		log.Info().Msg("Reading from stdin")
@@ -66,37 +93,74 @@ func main() {
 			log.Fatal().Err(err).Str("file", *input).Msg("Failed to open input file")
 		}
 		defer f.Close()
+		if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+			inputSize = info.Size()
+		}
 		inputFile = f
-		log.Info().Str("file", *input).Msg("Reading input file")
+		log.Info().Str("file", *input).Int64("bytes", inputSize).Msg("Reading input file")
 	}
 
-	// Read records
+	// Records are streamed from the input, not loaded into memory
 	reader := batch.NewReader(inputFile, deps.Logger)
 	recordsCh := reader.ReadAll(ctx)
 
-	var records []batch.InputRecord
-	for record := range recordsCh {
-		records = append(records, record)
-	}
-
-	log.Info().Int("total", len(records)).Msg("Input file parsed")
-
 	// Dry run validation
 	if *dryRun {
-		dryRunAndExit(records)
+		dryRunAndExit(recordsCh)
 	}
 
 	// Validation mode
 	if *validate {
-		runValidationMode(ctx, records, deps, *corrThreshold)
+		runValidationMode(ctx, collectRecords(recordsCh), deps, *corrThreshold)
 		return
 	}
 
-	// Open output file
+	// Comparison mode, records are paired by event_id so both inputs are read first
+	if *compare != "" {
+		runComparisonMode(ctx, collectRecords(recordsCh), deps, comparisonOptions{
+			baseline: *compare,
+			judges:   splitList(*compareJudges),
+			output:   *output,
+			summary:  *summary,
+			workers:  *workers,
+		})
+		return
+	}
+
+	progress := batch.NewProgress(inputSize)
+
+	// Checkpoint of the completed event_ids, only written next to an output file
+	var checkpoint *batch.Checkpoint
+	if *checkpointPath != "" {
+		checkpoint = openCheckpoint(*checkpointPath, *resume, *force)
+		defer checkpoint.Close()
+	}
+	if *resume {
+		recordsCh = checkpoint.Pending(ctx, recordsCh, progress.Skip)
+	}
+
+	// Open output file, a resumed run appends to it
 	var outputFile io.Writer
 	if *output == "" {
 		outputFile = os.Stdout
 		log.Info().Msg("Writing to stdout")
+	} else if *resume {
+		// Results of the events evaluated again are dropped, so each event_id has one line
+		dropped, err := checkpoint.PruneOutput(*output)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Fatal().Err(err).Str("file", *output).Msg("Failed to repair output file")
+		}
+		if dropped > 0 {
+			log.Info().Int("dropped", dropped).Str("file", *output).Msg("Dropped the results of uncompleted events")
+		}
+
+		f, err := os.OpenFile(*output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", *output).Msg("Failed to open output file")
+		}
+		defer f.Close()
+		outputFile = f
+		log.Info().Str("file", *output).Msg("Appending to output file")
 	} else {
 		f, err := os.Create(*output)
 		if err != nil {
@@ -112,19 +176,46 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to create writer")
 	}
-	defer writer.Close()
 
-	// Process with worker pool
+	// Summary statistics of the run, created up front so that a bad path fails early
+	var summaryWriter *batch.SummaryWriter
+	if *summary != "" {
+		summaryFile, err := os.Create(*summary)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", *summary).Msg("Failed to create summary file")
+		}
+		defer summaryFile.Close()
+		summaryWriter = batch.NewSummaryWriter(summaryFile, deps.Logger)
+	}
+
+	// Process with worker pool, results are written as they complete
 	processor := batch.NewProcessor(deps.Executor, *workers, deps.Logger)
-	results := processor.Process(ctx, records)
+	results := processor.ProcessStream(ctx, recordsCh)
+
+	stopProgress := reportProgress(progress, *progressInterval)
 
-	// Write results
 	successCount := 0
 	errorCount := 0
+	parseErrors := 0
+	var usage models.TokenUsage
+
+	for record := range results {
+		progress.Record(record)
+		if record.Error != nil {
+			parseErrors++
+			continue
+		}
+
+		result := record.Result
+		if result.Usage != nil {
+			usage.Add(*result.Usage)
+		}
+		if summaryWriter != nil {
+			summaryWriter.Write(result)
+		}
 
-	for result := range results {
 		if err := writer.Write(result); err != nil {
-			log.Error().Err(err).Str("id", result.ID).Msg("Failed to write result")
+			log.Error().Err(err).Str("id", result.ID).Int("line", record.LineNumber).Msg("Failed to write result")
 			errorCount++
 
 			if !*continueOnError {
@@ -132,17 +223,36 @@ func main() {
 			}
 		} else {
 			successCount++
+			if checkpoint != nil {
+				if err := checkpoint.Mark(record); err != nil {
+					log.Error().Err(err).Str("id", result.ID).Msg("Failed to update checkpoint")
+				}
+			}
 		}
 	}
 
+	stopProgress()
+	snapshot := progress.Snapshot()
+
+	// Formats that aggregate the results write them on close
+	if err := writer.Close(); err != nil {
+		log.Fatal().Err(err).Str("format", *format).Msg("Failed to write output")
+	}
+
 	log.Info().
 		Int("success", successCount).
 		Int("errors", errorCount).
+		Int("parse_errors", parseErrors).
+		Int("skipped", snapshot.Skipped).
+		Float64("records_per_sec", snapshot.RecordsPerSec).
+		Int("input_tokens", usage.InputTokens).
+		Int("output_tokens", usage.OutputTokens).
+		Float64("estimated_cost_usd", usage.Cost).
 		Dur("duration", time.Since(startTime)).
 		Msg("Processing complete")
 
-	if *summary != "" {
-		writeSummary(summary)
+	if summaryWriter != nil {
+		writeSummary(summaryWriter, *summary)
 	}
 
 	log.Info().Msg("Batch processing complete")
@@ -164,28 +274,119 @@ func setupGracefulShutdown() (context.Context, context.CancelFunc) {
 }
 
 func formatValidator(format *string) {
-	validFormats := map[string]bool{"jsonl": true, "summary": true}
-	if !validFormats[*format] {
+	if !slices.Contains(batch.Formats, *format) {
 		log.Fatal().
 			Str("format", *format).
-			Msg("Invalid format. Supported: jsonl, summary")
+			Msg("Invalid format. Supported: " + strings.Join(batch.Formats, ", "))
+	}
+}
+
+func writeSummary(summaryWriter *batch.SummaryWriter, path string) {
+	if err := summaryWriter.Close(); err != nil {
+		log.Fatal().Err(err).Str("file", path).Msg("Failed to write summary file")
 	}
+	log.Info().Str("file", path).Msg("Summary written")
 }
 
-func writeSummary(summary *string) {
-	summaryFile, err := os.Create(*summary)
+// openCheckpoint starts a new checkpoint or, with resume, reopens the one of the earlier
+// run. Resuming is refused when a config that changes results differs from the one the
+// checkpoint was written with, unless forced, since old and new results wouldn't compare.
+func openCheckpoint(path string, resume bool, force bool) *batch.Checkpoint {
+	fingerprint, err := config.Fingerprint()
 	if err != nil {
-		log.Fatal().Err(err).Str("file", *summary).Msg("Failed to create summary file")
+		log.Fatal().Err(err).Msg("Failed to fingerprint config")
 	}
-	defer summaryFile.Close()
 
-	// TODO: Write summary stats (can reuse summary writer logic)
-	log.Info().Str("file", *summary).Msg("Summary written")
+	if !resume {
+		checkpoint, err := batch.CreateCheckpoint(path, fingerprint)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to create checkpoint")
+		}
+		log.Info().Str("file", path).Msg("Writing checkpoint")
+		return checkpoint
+	}
+
+	checkpoint, err := batch.OpenCheckpoint(path)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to open checkpoint")
+	}
+
+	if changed := checkpoint.ConfigChanges(fingerprint); len(changed) > 0 {
+		if !force {
+			log.Fatal().
+				Strs("configs", changed).
+				Msg("Config changed since the checkpoint was written, refusing to resume (use -force to resume anyway)")
+		}
+		log.Warn().
+			Strs("configs", changed).
+			Msg("Config changed since the checkpoint was written, resuming anyway")
+		if err := checkpoint.SetConfig(fingerprint); err != nil {
+			log.Fatal().Err(err).Msg("Failed to update checkpoint")
+		}
+	}
+
+	log.Info().
+		Str("file", path).
+		Int("completed", checkpoint.Len()).
+		Msg("Resuming from checkpoint")
+	return checkpoint
+}
+
+// reportProgress logs the progress of the run every interval until the returned stop
+// function is called
+func reportProgress(progress *batch.Progress, interval time.Duration) func() {
+	if interval <= 0 {
+		return func() {}
+	}
+
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				snapshot := progress.Snapshot()
+				event := log.Info().
+					Int("done", snapshot.Done).
+					Int("parse_errors", snapshot.Errors).
+					Int("skipped", snapshot.Skipped).
+					Float64("records_per_sec", snapshot.RecordsPerSec).
+					Str("elapsed", snapshot.Elapsed.Round(time.Second).String())
+				if snapshot.Percent > 0 {
+					event = event.
+						Float64("percent", snapshot.Percent).
+						Str("eta", snapshot.ETA.Round(time.Second).String())
+				}
+				event.Msg("Progress")
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
 }
 
-func dryRunAndExit(records []batch.InputRecord) {
+// collectRecords reads the whole input, for the modes that need every record up front
+func collectRecords(recordsCh <-chan batch.InputRecord) []batch.InputRecord {
+	var records []batch.InputRecord
+	for record := range recordsCh {
+		records = append(records, record)
+	}
+
+	log.Info().Int("total", len(records)).Msg("Input file parsed")
+	return records
+}
+
+func dryRunAndExit(recordsCh <-chan batch.InputRecord) {
+	total := 0
 	errorCount := 0
-	for _, record := range records {
+	for record := range recordsCh {
+		total++
 		if record.Error != nil {
 			log.Error().
 				Int("line", record.LineNumber).
@@ -199,7 +400,7 @@ func dryRunAndExit(records []batch.InputRecord) {
 		log.Fatal().Int("errors", errorCount).Msg("Validation failed")
 	}
 
-	log.Info().Msg("Validation successful")
+	log.Info().Int("total", total).Msg("Validation successful")
 	os.Exit(0)
 }
 
@@ -289,6 +490,113 @@ func runValidationMode(ctx context.Context, records []batch.InputRecord, deps *s
 	log.Info().Msg("Safe to evaluate full dataset with these judge prompts")
 }
 
+type comparisonOptions struct {
+	baseline string   // Input file of the baseline answers
+	judges   []string // Judges to compare with, all when empty
+	output   string   // Comparison results, stdout when empty
+	summary  string   // Optional win/tie/loss report file
+	workers  int
+}
+
+func runComparisonMode(ctx context.Context, candidates []batch.InputRecord, deps *setup.Dependencies, opts comparisonOptions) {
+	log.Info().Str("baseline", opts.baseline).Msg("Comparison mode enabled")
+
+	f, err := os.Open(opts.baseline)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", opts.baseline).Msg("Failed to open baseline file")
+	}
+	defer f.Close()
+
+	var baseline []batch.InputRecord
+	for record := range batch.NewReader(f, deps.Logger).ReadAll(ctx) {
+		baseline = append(baseline, record)
+	}
+
+	pairs, unmatched := batch.PairRecords(baseline, candidates)
+	if len(unmatched) > 0 {
+		log.Warn().
+			Int("unmatched", len(unmatched)).
+			Strs("event_ids", unmatched).
+			Msg("Records without a counterpart in the other file are not compared")
+	}
+	if len(pairs) == 0 {
+		log.Fatal().Msg("No records share an event_id with the baseline file")
+	}
+
+	// Open output file
+	var outputFile io.Writer = os.Stdout
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", opts.output).Msg("Failed to create output file")
+		}
+		defer f.Close()
+		outputFile = f
+	}
+
+	processor := batch.NewComparisonProcessor(deps.ComparisonExecutor, opts.judges, opts.workers, deps.Logger)
+	encoder := json.NewEncoder(outputFile)
+
+	var results []models.ComparisonResult
+	for result := range processor.Process(ctx, pairs) {
+		if err := encoder.Encode(result); err != nil {
+			log.Error().Err(err).Str("id", result.ID).Msg("Failed to write comparison result")
+		}
+		results = append(results, result)
+	}
+
+	report := batch.NewComparisonReport(pairs[0].Baseline.Agent.Version, pairs[0].Candidate.Agent.Version, results)
+	printComparisonReport(report)
+
+	if opts.summary != "" {
+		reportJSON, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to marshal comparison report")
+		}
+		if err := os.WriteFile(opts.summary, reportJSON, 0644); err != nil {
+			log.Fatal().Err(err).Str("file", opts.summary).Msg("Failed to write comparison report")
+		}
+		log.Info().Str("file", opts.summary).Msg("Comparison report written")
+	}
+}
+
+func printComparisonReport(report batch.ComparisonReport) {
+	for _, name := range report.JudgeNames() {
+		record := report.Judges[name]
+		log.Info().
+			Str("judge", name).
+			Int("wins", record.Wins).
+			Int("ties", record.Ties).
+			Int("losses", record.Losses).
+			Int("errors", record.Errors).
+			Int("inconsistent", record.Inconsistent).
+			Msg("Judge preferences")
+	}
+
+	log.Info().
+		Str("baseline", report.Baseline).
+		Str("candidate", report.Candidate).
+		Int("pairs", report.Total).
+		Int("wins", report.Wins).
+		Int("ties", report.Ties).
+		Int("losses", report.Losses).
+		Int("errors", report.Errors).
+		Float64("win_rate", report.WinRate).
+		Float64("estimated_cost_usd", report.Usage.Cost).
+		Msg("Comparison complete")
+}
+
+// splitList splits a comma-separated flag value, empty values are dropped
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func printValidationSummary(result *batch.ValidationResult) {
 	status := "PASSED"
 	if !result.Passed {
@@ -5,18 +5,12 @@ import (
 	"errors"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/aggregator"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/bedrock"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/prechecks"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/redis"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/setup"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/stream"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -36,73 +30,25 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	// Initialize Bedrock client for embeddings
-	region := os.Getenv("AWS_REGION")
-	modelID := os.Getenv("CLAUDE_MODEL_ID")
-	bedrockClient, err := bedrock.NewClient(ctx, region, modelID)
+	// Wire evaluation pipeline (prechecks, judges, aggregator)
+	cfg := setup.LoadConfig()
+	deps, err := setup.Wire(ctx, cfg, &logger)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create Bedrock client")
+		log.Fatal().Err(err).Msg("Failed to wire dependencies")
 	}
 
 	// Redis client
-	streamCfg := stream.NewStreamConfig(
-		os.Getenv("REDIS_ADDR"), // "localhost:6379"
-		"eval-events",           // stream name
-		"eval-group",            // consumer group
-		os.Getenv("HOSTNAME"),   // unique consumer name
-	)
+	streamCfg := stream.LoadStreamConfig()
 
-	// Aggregator weights
-	precheckWeight, err := strconv.ParseFloat(os.Getenv("PRECHECK_WEIGHT"), 64)
-	if err != nil {
-		precheckWeight = 0.3
-	}
-	llmJudgeWeight, err := strconv.ParseFloat(os.Getenv("LLM_JUDGE_WEIGHT"), 64)
-	if err != nil {
-		llmJudgeWeight = 0.7
-	}
-
-	redisClient, err := redis.ConnectRedis(ctx, os.Getenv("REDIS_ADDR"), os.Getenv("REDIS_PASSWORD"), 5)
+	redisClient, err := redis.ConnectRedis(ctx, streamCfg.RedisAddr, os.Getenv("REDIS_PASSWORD"), 5)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to Redis")
 	}
 
-	// Wire Components
-	// Stage 1 — PreChecks
-	stageRunner := prechecks.NewStageRunner([]prechecks.Checker{
-		&prechecks.LengthChecker{},
-		&prechecks.OverlapChecker{MinOverlapThreshold: 0.3},
-		&prechecks.FormatChecker{},
-	})
+	// Evaluation results are published to the results stream
+	sink := stream.NewRedisStreamSink(redisClient, streamCfg.ResultsStream, streamCfg.ResultsMaxLen)
 
-	// Stage 2 — LLM Judges (from YAML config)
-	judgesConfig, err := config.LoadJudgesConfig()
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to load judges config")
-	}
-
-	judgePool := judge.NewJudgePool(bedrockClient, &logger)
-	judges, err := judgePool.BuildFromConfig(judgesConfig)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to build judges from config")
-	}
-
-	judgeRunner := judge.NewJudgeRunner(judges, &logger)
-
-	// Aggregator
-	agg := aggregator.NewAggregator(aggregator.Weights{
-		PreChecks: precheckWeight,
-		LLMJudge:  llmJudgeWeight,
-	}, &logger)
-
-	// Executor
-	earlyExit, _ := strconv.ParseFloat(os.Getenv("EARLY_EXIT_THRESHOLD"), 64)
-	if earlyExit == 0 {
-		earlyExit = 0.2
-	}
-	exec := executor.NewExecutor(stageRunner, judgeRunner, agg, earlyExit, &logger)
-
-	consumer := stream.NewConsumer(redisClient, streamCfg.Stream, streamCfg.Group, streamCfg.ConsumerName, exec, &logger)
+	consumer := stream.NewConsumer(redisClient, streamCfg, deps.Executor, sink, &logger)
 
 	// Setup consumer
 	err = consumer.Setup(ctx)
@@ -111,15 +57,20 @@ func main() {
 	}
 
 	// Start consumer
+	consumerDone := make(chan error, 1)
 	go func() {
-		if err := consumer.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
-			logger.Error().Err(err).Msg("Consumer stopped with error")
-		}
+		consumerDone <- consumer.Start(ctx)
 	}()
 
 	// Wait for context to be done
 	<-ctx.Done()
 	logger.Info().Msg("Shutting down...")
 
+	// Wait for the consumer to drain in-flight evaluations
+	if err := <-consumerDone; err != nil && !errors.Is(err, context.Canceled) {
+		logger.Error().Err(err).Msg("Consumer stopped with error")
+	}
+	redisClient.Close()
+
 	log.Info().Msg("Eval Agent stopped")
 }
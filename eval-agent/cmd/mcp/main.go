@@ -74,5 +74,10 @@ func createMCPServer(deps *setup.Dependencies) *mcp.Server {
 		Name:        "evaluate_single_judge",
 		Description: "Evaluate with a single judge (relevance, faithfulness, coherence, completeness, or instruction). Faster than full pipeline.",
 	}, mcpadapter.NewEvaluateSingleJudgeHandler(deps.JudgeExecutor))
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "compare_responses",
+		Description: "Compare two AI agent responses to the same query (e.g. from two agent versions). Each judge states which answer is better, asked in both answer orders to cancel position bias.",
+	}, mcpadapter.NewCompareHandler(deps.ComparisonExecutor))
 	return server
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	red "github.com/povarna/generative-ai-agents/eval-agent/internal/redis"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/stream"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	dlq := flag.String("dlq", "eval-events-dlq", "Dead-letter stream to replay from")
+	target := flag.String("stream", "eval-events", "Stream to replay entries into")
+	id := flag.String("id", "", "Replay a single dead-letter entry by ID")
+	count := flag.Int64("count", 0, "Maximum number of entries to replay (0 = all)")
+	keep := flag.Bool("keep", false, "Keep replayed entries in the dead-letter stream")
+	dryRun := flag.Bool("dry-run", false, "List the entries that would be replayed without replaying them")
+	flag.Parse()
+
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+
+	if err := run(*dlq, *target, *id, *count, *keep, *dryRun); err != nil {
+		log.Error().Err(err).Msg("replay failed")
+		os.Exit(1)
+	}
+}
+
+func run(dlq, target, id string, count int64, keep, dryRun bool) error {
+	_ = godotenv.Load()
+
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+
+	ctx := context.Background()
+	client, err := red.ConnectRedis(ctx, addr, os.Getenv("REDIS_PASSWORD"), 3)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	start, end := "-", "+"
+	if id != "" {
+		start, end = id, id
+	}
+
+	var entries []redis.XMessage
+	if count > 0 {
+		entries, err = client.XRangeN(ctx, dlq, start, end, count).Result()
+	} else {
+		entries, err = client.XRange(ctx, dlq, start, end).Result()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read dead-letter stream %s: %w", dlq, err)
+	}
+
+	if len(entries) == 0 {
+		log.Info().Str("dlq", dlq).Msg("No dead-letter entries to replay")
+		return nil
+	}
+
+	replayed, skipped := 0, 0
+	for _, entry := range entries {
+		payload, _ := entry.Values[stream.DeadLetterPayload].(string)
+		reason, _ := entry.Values[stream.DeadLetterReason].(string)
+
+		if payload == "" {
+			log.Warn().Str("id", entry.ID).Str("reason", reason).Msg("Entry has no payload, skipping")
+			skipped++
+			continue
+		}
+
+		if dryRun {
+			log.Info().Str("id", entry.ID).Str("reason", reason).Msg("Would replay entry")
+			continue
+		}
+
+		newID, err := client.XAdd(ctx, &redis.XAddArgs{
+			Stream: target,
+			Values: map[string]any{"payload": payload},
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("failed to replay entry %s: %w", entry.ID, err)
+		}
+
+		if !keep {
+			if err := client.XDel(ctx, dlq, entry.ID).Err(); err != nil {
+				return fmt.Errorf("failed to delete replayed entry %s: %w", entry.ID, err)
+			}
+		}
+
+		log.Info().Str("id", entry.ID).Str("new_id", newID).Str("reason", reason).Msg("Replayed entry")
+		replayed++
+	}
+
+	log.Info().
+		Str("dlq", dlq).
+		Str("stream", target).
+		Int("replayed", replayed).
+		Int("skipped", skipped).
+		Bool("dry_run", dryRun).
+		Msg("Replay complete")
+	return nil
+}
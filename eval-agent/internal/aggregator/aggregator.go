@@ -1,6 +1,10 @@
 package aggregator
 
 import (
+	"math"
+	"strings"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
@@ -10,61 +14,401 @@ type Weights struct {
 	LLMJudge  float64
 }
 
+// Bands are the confidence cutoffs for the pass and review verdicts
+type Bands struct {
+	Pass   float64
+	Review float64
+}
+
+// Veto forces a verdict when the named stage scores below the threshold
+type Veto struct {
+	Stage   string
+	Below   float64
+	Verdict models.Verdict
+}
+
+// Policy describes how stage results are combined into a confidence and verdict
+type Policy struct {
+	Name             string
+	Strategy         string
+	Weights          Weights
+	StageWeights     map[string]float64
+	Vetoes           []Veto
+	Bands            Bands
+	FailedStages     string  // How non-ok stages are aggregated: exclude or zero
+	MaxFailedJudges  float64 // Share of judges that may fail before the verdict is review
+	MaxJudgeVariance float64 // Score variance of a sampled judge above which a pass is review, 0 disables
+}
+
+// DefaultPolicy is the weighted mean policy with the standard 0.8/0.5 verdict bands
+func DefaultPolicy(weights Weights) Policy {
+	return Policy{
+		Name:            "default",
+		Strategy:        config.StrategyWeightedMean,
+		Weights:         weights,
+		Bands:           Bands{Pass: 0.8, Review: 0.5},
+		FailedStages:    config.FailedStagesExclude,
+		MaxFailedJudges: config.DefaultMaxFailedJudges,
+	}
+}
+
 type Aggregator struct {
-	Weights Weights
-	logger  *zerolog.Logger
+	defaultPolicy Policy
+	agentPolicies map[string]Policy
+	logger        *zerolog.Logger
 }
 
 func NewAggregator(weights Weights, logger *zerolog.Logger) *Aggregator {
+	return NewPolicyAggregator(DefaultPolicy(weights), nil, logger)
+}
+
+// NewPolicyAggregator creates an aggregator that selects a policy by agent name,
+// falling back to the default policy for unknown agents.
+func NewPolicyAggregator(defaultPolicy Policy, agentPolicies map[string]Policy, logger *zerolog.Logger) *Aggregator {
+	if agentPolicies == nil {
+		agentPolicies = make(map[string]Policy)
+	}
+
 	return &Aggregator{
-		Weights: weights,
-		logger:  logger,
+		defaultPolicy: defaultPolicy,
+		agentPolicies: agentPolicies,
+		logger:        logger,
 	}
 }
 
-func (a *Aggregator) Aggregate(id string, stage1 []models.StageResult, stage2 []models.StageResult) models.EvaluationResult {
-	result := models.EvaluationResult{
-		ID:     id,
-		Stages: append(stage1, stage2...),
+// NewAggregatorFromConfig builds the policies from configuration
+func NewAggregatorFromConfig(cfg *config.PoliciesConfig, logger *zerolog.Logger) *Aggregator {
+	policies := PoliciesFromConfig(cfg)
+
+	agentPolicies := make(map[string]Policy, len(cfg.Aggregation.Agents))
+	for agent, policyName := range cfg.Aggregation.Agents {
+		agentPolicies[agent] = policies[policyName]
+	}
+
+	return NewPolicyAggregator(policies[cfg.Aggregation.DefaultPolicy], agentPolicies, logger)
+}
+
+// PoliciesFromConfig converts the configured policies into runtime policies keyed by name
+func PoliciesFromConfig(cfg *config.PoliciesConfig) map[string]Policy {
+	policies := make(map[string]Policy, len(cfg.Aggregation.Policies))
+	for _, policyCfg := range cfg.Aggregation.Policies {
+		policies[policyCfg.Name] = PolicyFromConfig(policyCfg)
+	}
+	return policies
+}
+
+// PolicyFromConfig converts a single configured policy into a runtime policy
+func PolicyFromConfig(policyCfg config.AggregationPolicy) Policy {
+	policy := Policy{
+		Name:     policyCfg.Name,
+		Strategy: policyCfg.Strategy,
+		Weights: Weights{
+			PreChecks: policyCfg.PrecheckWeight,
+			LLMJudge:  policyCfg.JudgeWeight,
+		},
+		StageWeights: policyCfg.StageWeights,
+		Bands: Bands{
+			Pass:   policyCfg.Verdicts.Pass,
+			Review: policyCfg.Verdicts.Review,
+		},
+		FailedStages:     policyCfg.FailedStages,
+		MaxFailedJudges:  config.DefaultMaxFailedJudges,
+		MaxJudgeVariance: policyCfg.MaxJudgeVariance,
 	}
 
-	stage1Score, stage2Score := 0.0, 0.0
+	if policyCfg.MaxFailedJudges != nil {
+		policy.MaxFailedJudges = *policyCfg.MaxFailedJudges
+	}
+
+	for _, veto := range policyCfg.Vetoes {
+		policy.Vetoes = append(policy.Vetoes, Veto{
+			Stage:   veto.Stage,
+			Below:   veto.Below,
+			Verdict: models.Verdict(veto.Verdict),
+		})
+	}
 
-	for _, stage := range stage1 {
-		stage1Score += stage.Score
+	return policy
+}
+
+// PolicyFor returns the policy used for the given agent
+func (a *Aggregator) PolicyFor(agentName string) Policy {
+	if policy, ok := a.agentPolicies[agentName]; ok {
+		return policy
 	}
+	return a.defaultPolicy
+}
 
-	for _, stage := range stage2 {
-		stage2Score += stage.Score
+func (a *Aggregator) Aggregate(evalCtx models.EvaluationContext, stage1 []models.StageResult, stage2 []models.StageResult) models.EvaluationResult {
+	policy := a.PolicyFor(evalCtx.Agent.Name)
+
+	result := models.EvaluationResult{
+		ID:     evalCtx.RequestID,
+		Stages: append(stage1, stage2...),
+		Policy: policy.Name,
 	}
+	result.Usage = models.TotalUsage(result.Stages)
 
 	if len(stage1) == 0 || len(stage2) == 0 {
 		result.Verdict = models.VerdictFail
 		return result
 	}
 
-	stage1Avg := stage1Score / float64(len(stage1))
-	stage2Avg := stage2Score / float64(len(stage2))
-
-	confidence := (stage1Avg * a.Weights.PreChecks) + (stage2Avg * a.Weights.LLMJudge)
+	scored1, scored2 := policy.scored(stage1), policy.scored(stage2)
+	confidence := policy.confidence(scored1, scored2)
 
 	result.Confidence = confidence
-	result.Verdict = a.calculateVerdict(confidence)
+	result.Verdict = policy.calculateVerdict(confidence)
+
+	// Too many failed judges make the confidence unreliable, whatever its value
+	if failed, ratio := policy.tooManyFailures(stage2); failed {
+		a.logger.
+			Warn().
+			Float64("failed_ratio", ratio).
+			Float64("max_failed_judges", policy.MaxFailedJudges).
+			Str("policy", policy.Name).
+			Msg("too many judges failed, verdict set to review")
+
+		result.Verdict = models.VerdictReview
+	}
+
+	// A judge whose samples disagree is not confident enough to pass on its own
+	if stage, uncertain := policy.uncertainJudge(scored2); uncertain && result.Verdict == models.VerdictPass {
+		a.logger.
+			Warn().
+			Str("stage", stage.Name).
+			Float64("variance", stage.Samples.Variance).
+			Float64("max_judge_variance", policy.MaxJudgeVariance).
+			Str("policy", policy.Name).
+			Msg("judge samples disagree, verdict set to review")
+
+		result.Verdict = models.VerdictReview
+	}
+
+	if veto, stage, vetoed := policy.veto(append(scored1, scored2...)); vetoed {
+		a.logger.
+			Info().
+			Str("stage", stage.Name).
+			Float64("score", stage.Score).
+			Float64("below", veto.Below).
+			Str("policy", policy.Name).
+			Msg("veto triggered")
+
+		// A veto can only make the verdict stricter
+		if verdictRank(veto.Verdict) < verdictRank(result.Verdict) {
+			result.Verdict = veto.Verdict
+		}
+		result.VetoedBy = stage.Name
+	}
 
 	a.logger.
 		Info().
+		Str("policy", policy.Name).
 		Float64("confidence", confidence).
 		Str("verdict", string(result.Verdict)).
 		Msg("aggregation complete")
 	return result
 }
 
-func (a *Aggregator) calculateVerdict(confidence float64) models.Verdict {
-	if confidence > 0.8 {
+// scored returns the stages that take part in the confidence and vetoes. Stages that
+// didn't produce a score are left out unless the policy counts them as zero.
+func (p Policy) scored(stages []models.StageResult) []models.StageResult {
+	if p.FailedStages == config.FailedStagesZero {
+		return stages
+	}
+
+	scored := make([]models.StageResult, 0, len(stages))
+	for _, stage := range stages {
+		if stage.OK() {
+			scored = append(scored, stage)
+		}
+	}
+	return scored
+}
+
+// tooManyFailures reports whether the share of judges that errored or timed out exceeds
+// the policy limit. Skipped judges don't count as run.
+func (p Policy) tooManyFailures(judges []models.StageResult) (bool, float64) {
+	run, failed := 0, 0
+	for _, judge := range judges {
+		if judge.Status == models.StageStatusSkipped {
+			continue
+		}
+		run++
+		if judge.Failed() {
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		return false, 0.0
+	}
+
+	ratio := float64(failed) / float64(run)
+	return ratio > p.MaxFailedJudges, ratio
+}
+
+// uncertainJudge returns the first judge whose samples vary more than the policy allows
+func (p Policy) uncertainJudge(judges []models.StageResult) (models.StageResult, bool) {
+	if p.MaxJudgeVariance <= 0.0 {
+		return models.StageResult{}, false
+	}
+
+	for _, judge := range judges {
+		if judge.Samples != nil && judge.Samples.Count >= 2 && judge.Samples.Variance > p.MaxJudgeVariance {
+			return judge, true
+		}
+	}
+	return models.StageResult{}, false
+}
+
+// confidence combines the stage scores. When one of the stages has no scored results
+// the other one carries the full weight.
+func (p Policy) confidence(stage1 []models.StageResult, stage2 []models.StageResult) float64 {
+	weights := p.Weights
+	switch {
+	case len(stage1) == 0 && len(stage2) == 0:
+		return 0.0
+	case len(stage1) == 0:
+		weights = Weights{PreChecks: 0.0, LLMJudge: 1.0}
+	case len(stage2) == 0:
+		weights = Weights{PreChecks: 1.0, LLMJudge: 0.0}
+	}
+
+	switch p.Strategy {
+	case config.StrategyMin:
+		return p.minimum(append(append([]models.StageResult{}, stage1...), stage2...))
+	case config.StrategyGeometricMean:
+		totalWeight := weights.PreChecks + weights.LLMJudge
+		if totalWeight == 0.0 {
+			return 0.0
+		}
+		stage1Mean := p.geometricMean(stage1)
+		stage2Mean := p.geometricMean(stage2)
+		return math.Pow(stage1Mean, weights.PreChecks/totalWeight) * math.Pow(stage2Mean, weights.LLMJudge/totalWeight)
+	default:
+		return (p.weightedMean(stage1) * weights.PreChecks) + (p.weightedMean(stage2) * weights.LLMJudge)
+	}
+}
+
+// weightOf returns the policy weight for a stage, falling back to the stage's own weight.
+// The full stage name is looked up before the short one, so that a policy configuring
+// both "relevance" and "relevance-judge" always applies the latter.
+func (p Policy) weightOf(stage models.StageResult) float64 {
+	if weight, ok := p.StageWeights[stage.Name]; ok {
+		return weight
+	}
+	for _, suffix := range []string{"-judge", "-checker"} {
+		if name, ok := strings.CutSuffix(stage.Name, suffix); ok {
+			if weight, ok := p.StageWeights[name]; ok {
+				return weight
+			}
+		}
+	}
+	return stage.EffectiveWeight()
+}
+
+func (p Policy) weightedMean(stages []models.StageResult) float64 {
+	totalScore, totalWeight := 0.0, 0.0
+	for _, stage := range stages {
+		weight := p.weightOf(stage)
+		totalScore += stage.Score * weight
+		totalWeight += weight
+	}
+
+	if totalWeight == 0.0 {
+		return 0.0
+	}
+	return totalScore / totalWeight
+}
+
+func (p Policy) geometricMean(stages []models.StageResult) float64 {
+	logSum, totalWeight := 0.0, 0.0
+	for _, stage := range stages {
+		weight := p.weightOf(stage)
+		if weight == 0.0 {
+			continue
+		}
+		if stage.Score <= 0.0 {
+			return 0.0
+		}
+		logSum += math.Log(stage.Score) * weight
+		totalWeight += weight
+	}
+
+	if totalWeight == 0.0 {
+		return 0.0
+	}
+	return math.Exp(logSum / totalWeight)
+}
+
+func (p Policy) minimum(stages []models.StageResult) float64 {
+	lowest := math.Inf(1)
+	for _, stage := range stages {
+		if p.weightOf(stage) == 0.0 {
+			continue
+		}
+		lowest = math.Min(lowest, stage.Score)
+	}
+
+	if math.IsInf(lowest, 1) {
+		return 0.0
+	}
+	return lowest
+}
+
+// veto returns the strictest veto triggered by the given stages or their own veto
+func (p Policy) veto(stages []models.StageResult) (Veto, models.StageResult, bool) {
+	var (
+		triggered Veto
+		culprit   models.StageResult
+		found     bool
+	)
+
+	// A stage vetoing on its own, e.g. the PII checker, always fails the evaluation
+	for _, stage := range stages {
+		if stage.Veto {
+			return Veto{Stage: stage.Name, Verdict: models.VerdictFail}, stage, true
+		}
+	}
+
+	for _, veto := range p.Vetoes {
+		for _, stage := range stages {
+			if !matchesStage(veto.Stage, stage.Name) || stage.Score >= veto.Below {
+				continue
+			}
+			if !found || verdictRank(veto.Verdict) < verdictRank(triggered.Verdict) {
+				triggered, culprit, found = veto, stage, true
+			}
+		}
+	}
+
+	return triggered, culprit, found
+}
+
+func (p Policy) calculateVerdict(confidence float64) models.Verdict {
+	if confidence > p.Bands.Pass {
 		return models.VerdictPass
 	}
-	if confidence > 0.5 {
+	if confidence > p.Bands.Review {
 		return models.VerdictReview
 	}
 	return models.VerdictFail
 }
+
+// matchesStage reports whether a configured name refers to the stage. Both the full
+// stage name ("faithfulness-judge") and the short name ("faithfulness") are accepted.
+func matchesStage(name string, stageName string) bool {
+	return stageName == name || stageName == name+"-judge" || stageName == name+"-checker"
+}
+
+func verdictRank(verdict models.Verdict) int {
+	switch verdict {
+	case models.VerdictPass:
+		return 2
+	case models.VerdictReview:
+		return 1
+	default:
+		return 0
+	}
+}
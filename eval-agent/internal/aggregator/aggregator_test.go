@@ -1,9 +1,11 @@
 package aggregator
 
 import (
+	"math"
 	"testing"
 	"time"
 
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
@@ -20,7 +22,7 @@ func TestAggregate_Pass(t *testing.T) {
 	stage1 := []models.StageResult{{Name: "precheck", Score: 0.8, Reason: "ok", Duration: 100 * time.Millisecond}}
 	stage2 := []models.StageResult{{Name: "judge", Score: 0.9, Reason: "good", Duration: 1 * time.Second}}
 
-	result := agg.Aggregate("test", stage1, stage2)
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
 
 	// (0.8 * 0.3) + (0.9 * 0.7) = 0.87 > 0.8 → Pass
 	if result.Verdict != models.VerdictPass {
@@ -35,7 +37,7 @@ func TestAggregate_Review(t *testing.T) {
 	stage1 := []models.StageResult{{Name: "precheck", Score: 0.6, Reason: "ok", Duration: 100 * time.Millisecond}}
 	stage2 := []models.StageResult{{Name: "judge", Score: 0.7, Reason: "ok", Duration: 1 * time.Second}}
 
-	result := agg.Aggregate("test", stage1, stage2)
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
 
 	// (0.6 * 0.3) + (0.7 * 0.7) = 0.67, 0.5 < 0.67 <= 0.8 → Review
 	if result.Verdict != models.VerdictReview {
@@ -50,7 +52,7 @@ func TestAggregate_Fail(t *testing.T) {
 	stage1 := []models.StageResult{{Name: "precheck", Score: 0.2, Reason: "bad", Duration: 100 * time.Millisecond}}
 	stage2 := []models.StageResult{{Name: "judge", Score: 0.4, Reason: "bad", Duration: 1 * time.Second}}
 
-	result := agg.Aggregate("test", stage1, stage2)
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
 
 	// (0.2 * 0.3) + (0.4 * 0.7) = 0.34 <= 0.5 → Fail
 	if result.Verdict != models.VerdictFail {
@@ -63,14 +65,475 @@ func TestAggregate_EmptyStages_Fail(t *testing.T) {
 	agg := NewAggregator(weights, newTestLogger())
 
 	// Test empty stage1
-	result := agg.Aggregate("test", []models.StageResult{}, []models.StageResult{{Name: "j", Score: 1.0, Reason: "ok", Duration: 1 * time.Second}})
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, []models.StageResult{}, []models.StageResult{{Name: "j", Score: 1.0, Reason: "ok", Duration: 1 * time.Second}})
 	if result.Verdict != models.VerdictFail {
 		t.Error("expected Fail for empty stage1")
 	}
 
 	// Test empty stage2
-	result = agg.Aggregate("test", []models.StageResult{{Name: "p", Score: 1.0, Reason: "ok", Duration: 100 * time.Millisecond}}, []models.StageResult{})
+	result = agg.Aggregate(models.EvaluationContext{RequestID: "test"}, []models.StageResult{{Name: "p", Score: 1.0, Reason: "ok", Duration: 100 * time.Millisecond}}, []models.StageResult{})
 	if result.Verdict != models.VerdictFail {
 		t.Error("expected Fail for empty stage2")
 	}
 }
+
+func TestAggregate_WeightedPrechecks(t *testing.T) {
+	weights := Weights{PreChecks: 0.5, LLMJudge: 0.5}
+	agg := NewAggregator(weights, newTestLogger())
+
+	stage1 := []models.StageResult{
+		{Name: "length-checker", Score: 1.0, Weight: 3.0},
+		{Name: "overlap-checker", Score: 0.0, Weight: 1.0},
+	}
+	stage2 := []models.StageResult{{Name: "judge", Score: 1.0}}
+
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+	// stage1 = (1.0*3 + 0.0*1) / 4 = 0.75; (0.75 * 0.5) + (1.0 * 0.5) = 0.875
+	if math.Abs(result.Confidence-0.875) > 1e-9 {
+		t.Errorf("expected confidence 0.875, got %f", result.Confidence)
+	}
+}
+
+func TestAggregate_Strategies(t *testing.T) {
+	stage1 := []models.StageResult{
+		{Name: "length-checker", Score: 0.9},
+		{Name: "format-checker", Score: 0.4},
+	}
+	stage2 := []models.StageResult{{Name: "relevance-judge", Score: 0.9}}
+
+	tests := []struct {
+		name     string
+		strategy string
+		want     float64
+	}{
+		// (0.65 * 0.5) + (0.9 * 0.5) = 0.775
+		{name: "weighted mean", strategy: config.StrategyWeightedMean, want: 0.775},
+		// stage1 = sqrt(0.9 * 0.4) = 0.6; 0.6^0.5 * 0.9^0.5
+		{name: "geometric mean", strategy: config.StrategyGeometricMean, want: math.Sqrt(0.6 * 0.9)},
+		{name: "min", strategy: config.StrategyMin, want: 0.4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultPolicy(Weights{PreChecks: 0.5, LLMJudge: 0.5})
+			policy.Strategy = tt.strategy
+			agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+			result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+			if math.Abs(result.Confidence-tt.want) > 1e-9 {
+				t.Errorf("expected confidence %f, got %f", tt.want, result.Confidence)
+			}
+		})
+	}
+}
+
+func TestAggregate_StageWeightsOverride(t *testing.T) {
+	policy := DefaultPolicy(Weights{PreChecks: 0.0, LLMJudge: 1.0})
+	policy.StageWeights = map[string]float64{"relevance": 0.0, "faithfulness": 1.0}
+	agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+	stage1 := []models.StageResult{{Name: "length-checker", Score: 1.0}}
+	stage2 := []models.StageResult{
+		{Name: "relevance-judge", Score: 0.1, Weight: 5.0},
+		{Name: "faithfulness-judge", Score: 0.9},
+	}
+
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+	// relevance is excluded by the policy weight of 0
+	if math.Abs(result.Confidence-0.9) > 1e-9 {
+		t.Errorf("expected confidence 0.9, got %f", result.Confidence)
+	}
+}
+
+func TestAggregate_StageWeightsFullNameWins(t *testing.T) {
+	policy := DefaultPolicy(Weights{PreChecks: 0.0, LLMJudge: 1.0})
+	policy.StageWeights = map[string]float64{"relevance": 0.0, "relevance-judge": 1.0}
+	agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+	stage1 := []models.StageResult{{Name: "length-checker", Score: 1.0}}
+	stage2 := []models.StageResult{
+		{Name: "relevance-judge", Score: 0.1},
+		{Name: "faithfulness-judge", Score: 0.9},
+	}
+
+	// Map order must not matter, the full name's weight of 1 applies every time
+	for range 20 {
+		result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+		if math.Abs(result.Confidence-0.5) > 1e-9 {
+			t.Fatalf("expected confidence 0.5, got %f", result.Confidence)
+		}
+	}
+}
+
+func TestAggregate_Vetoes(t *testing.T) {
+	stage1 := []models.StageResult{{Name: "length-checker", Score: 1.0}}
+
+	tests := []struct {
+		name         string
+		vetoes       []Veto
+		faithfulness float64
+		wantVerdict  models.Verdict
+		wantVetoedBy string
+	}{
+		{
+			name:         "fail veto triggered",
+			vetoes:       []Veto{{Stage: "faithfulness", Below: 0.5, Verdict: models.VerdictFail}},
+			faithfulness: 0.4,
+			wantVerdict:  models.VerdictFail,
+			wantVetoedBy: "faithfulness-judge",
+		},
+		{
+			name:         "review veto triggered",
+			vetoes:       []Veto{{Stage: "faithfulness-judge", Below: 0.5, Verdict: models.VerdictReview}},
+			faithfulness: 0.4,
+			wantVerdict:  models.VerdictReview,
+			wantVetoedBy: "faithfulness-judge",
+		},
+		{
+			name:         "score at threshold does not veto",
+			vetoes:       []Veto{{Stage: "faithfulness", Below: 0.5, Verdict: models.VerdictFail}},
+			faithfulness: 0.5,
+			wantVerdict:  models.VerdictPass,
+		},
+		{
+			name: "strictest veto wins",
+			vetoes: []Veto{
+				{Stage: "faithfulness", Below: 0.5, Verdict: models.VerdictReview},
+				{Stage: "faithfulness", Below: 0.45, Verdict: models.VerdictFail},
+			},
+			faithfulness: 0.4,
+			wantVerdict:  models.VerdictFail,
+			wantVetoedBy: "faithfulness-judge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Weight the faithfulness judge out so only the veto can change the verdict
+			policy := DefaultPolicy(Weights{PreChecks: 0.3, LLMJudge: 0.7})
+			policy.StageWeights = map[string]float64{"faithfulness": 0.0}
+			policy.Vetoes = tt.vetoes
+			agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+			stage2 := []models.StageResult{
+				{Name: "relevance-judge", Score: 1.0},
+				{Name: "faithfulness-judge", Score: tt.faithfulness},
+			}
+
+			result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+			if result.Verdict != tt.wantVerdict {
+				t.Errorf("expected %s, got %s", tt.wantVerdict, result.Verdict)
+			}
+			if result.VetoedBy != tt.wantVetoedBy {
+				t.Errorf("expected vetoed_by %q, got %q", tt.wantVetoedBy, result.VetoedBy)
+			}
+		})
+	}
+}
+
+func TestAggregate_VetoNeverLoosensVerdict(t *testing.T) {
+	policy := DefaultPolicy(Weights{PreChecks: 0.3, LLMJudge: 0.7})
+	policy.Vetoes = []Veto{{Stage: "relevance", Below: 0.5, Verdict: models.VerdictReview}}
+	agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+	stage1 := []models.StageResult{{Name: "length-checker", Score: 0.1}}
+	stage2 := []models.StageResult{{Name: "relevance-judge", Score: 0.1}}
+
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+	if result.Verdict != models.VerdictFail {
+		t.Errorf("expected Fail, got %s", result.Verdict)
+	}
+}
+
+func TestAggregate_StageVeto(t *testing.T) {
+	policy := DefaultPolicy(Weights{PreChecks: 0.3, LLMJudge: 0.7})
+	policy.Vetoes = []Veto{{Stage: "relevance", Below: 0.5, Verdict: models.VerdictReview}}
+	agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+	stage1 := []models.StageResult{
+		{Name: "length-checker", Score: 1.0},
+		{Name: "pii-checker", Score: 0.0, Veto: true},
+	}
+	stage2 := []models.StageResult{{Name: "relevance-judge", Score: 0.4}}
+
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+	// The stage's own veto is a fail, stricter than the policy's review veto
+	if result.Verdict != models.VerdictFail {
+		t.Errorf("expected Fail, got %s", result.Verdict)
+	}
+	if result.VetoedBy != "pii-checker" {
+		t.Errorf("expected vetoed_by pii-checker, got %q", result.VetoedBy)
+	}
+}
+
+func TestAggregate_VerdictBands(t *testing.T) {
+	policy := DefaultPolicy(Weights{PreChecks: 0.3, LLMJudge: 0.7})
+	policy.Bands = Bands{Pass: 0.9, Review: 0.7}
+	agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+	stage1 := []models.StageResult{{Name: "precheck", Score: 0.8}}
+	stage2 := []models.StageResult{{Name: "judge", Score: 0.9}}
+
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+	// 0.87 is a pass with the default bands but a review with pass=0.9
+	if result.Verdict != models.VerdictReview {
+		t.Errorf("expected Review, got %s", result.Verdict)
+	}
+}
+
+func TestAggregate_SelectsPolicyByAgent(t *testing.T) {
+	cfg := &config.PoliciesConfig{
+		Aggregation: config.Aggregation{
+			DefaultPolicy: "standard",
+			Policies: []config.AggregationPolicy{
+				{
+					Name:           "standard",
+					Strategy:       config.StrategyWeightedMean,
+					PrecheckWeight: 0.3,
+					JudgeWeight:    0.7,
+					Verdicts:       config.VerdictBands{Pass: 0.8, Review: 0.5},
+				},
+				{
+					Name:           "strict",
+					Strategy:       config.StrategyMin,
+					PrecheckWeight: 0.3,
+					JudgeWeight:    0.7,
+					Verdicts:       config.VerdictBands{Pass: 0.8, Review: 0.5},
+				},
+			},
+			Agents: map[string]string{"billing-agent": "strict"},
+		},
+	}
+	agg := NewAggregatorFromConfig(cfg, newTestLogger())
+
+	stage1 := []models.StageResult{{Name: "length-checker", Score: 0.6}}
+	stage2 := []models.StageResult{{Name: "relevance-judge", Score: 1.0}}
+
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test", Agent: models.Agent{Name: "billing-agent"}}, stage1, stage2)
+	if result.Policy != "strict" || result.Verdict != models.VerdictReview {
+		t.Errorf("expected strict policy with Review, got %s with %s", result.Policy, result.Verdict)
+	}
+
+	result = agg.Aggregate(models.EvaluationContext{RequestID: "test", Agent: models.Agent{Name: "support-agent"}}, stage1, stage2)
+	if result.Policy != "standard" || result.Verdict != models.VerdictPass {
+		t.Errorf("expected standard policy with Pass, got %s with %s", result.Policy, result.Verdict)
+	}
+}
+
+func TestAggregate_FailedStages(t *testing.T) {
+	stage1 := []models.StageResult{{Name: "length-checker", Score: 0.9, Status: models.StageStatusOK}}
+
+	tests := []struct {
+		name           string
+		failedStages   string
+		stage2         []models.StageResult
+		wantConfidence float64
+		wantVerdict    models.Verdict
+	}{
+		{
+			name:         "errored judge excluded",
+			failedStages: config.FailedStagesExclude,
+			stage2: []models.StageResult{
+				{Name: "relevance-judge", Score: 1.0, Status: models.StageStatusOK},
+				{Name: "coherence-judge", Score: 0.8, Status: models.StageStatusOK},
+				{Name: "faithfulness-judge", Score: 0.0, Status: models.StageStatusError},
+			},
+			// (0.9 * 0.3) + (0.9 * 0.7) = 0.9
+			wantConfidence: 0.9,
+			wantVerdict:    models.VerdictPass,
+		},
+		{
+			name:         "errored judge counted as zero",
+			failedStages: config.FailedStagesZero,
+			stage2: []models.StageResult{
+				{Name: "relevance-judge", Score: 1.0, Status: models.StageStatusOK},
+				{Name: "coherence-judge", Score: 0.8, Status: models.StageStatusOK},
+				{Name: "faithfulness-judge", Score: 0.0, Status: models.StageStatusError},
+			},
+			// (0.9 * 0.3) + (0.6 * 0.7) = 0.69
+			wantConfidence: 0.69,
+			wantVerdict:    models.VerdictReview,
+		},
+		{
+			name:         "too many failed judges",
+			failedStages: config.FailedStagesExclude,
+			stage2: []models.StageResult{
+				{Name: "relevance-judge", Score: 1.0, Status: models.StageStatusOK},
+				{Name: "coherence-judge", Score: 0.0, Status: models.StageStatusTimeout},
+				{Name: "faithfulness-judge", Score: 0.0, Status: models.StageStatusError},
+			},
+			// (0.9 * 0.3) + (1.0 * 0.7) = 0.97, but 2/3 judges failed
+			wantConfidence: 0.97,
+			wantVerdict:    models.VerdictReview,
+		},
+		{
+			name:         "skipped judges are not failures",
+			failedStages: config.FailedStagesExclude,
+			stage2: []models.StageResult{
+				{Name: "relevance-judge", Score: 1.0, Status: models.StageStatusOK},
+				{Name: "faithfulness-judge", Score: 0.0, Status: models.StageStatusSkipped},
+				{Name: "groundedness-judge", Score: 0.0, Status: models.StageStatusSkipped},
+			},
+			wantConfidence: 0.97,
+			wantVerdict:    models.VerdictPass,
+		},
+		{
+			name:         "all judges failed uses prechecks only",
+			failedStages: config.FailedStagesExclude,
+			stage2: []models.StageResult{
+				{Name: "relevance-judge", Score: 0.0, Status: models.StageStatusError},
+			},
+			wantConfidence: 0.9,
+			wantVerdict:    models.VerdictReview,
+		},
+		{
+			name:         "results without status are scored",
+			failedStages: config.FailedStagesExclude,
+			stage2: []models.StageResult{
+				{Name: "relevance-judge", Score: 0.4},
+			},
+			// (0.9 * 0.3) + (0.4 * 0.7) = 0.55
+			wantConfidence: 0.55,
+			wantVerdict:    models.VerdictReview,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultPolicy(Weights{PreChecks: 0.3, LLMJudge: 0.7})
+			policy.FailedStages = tt.failedStages
+			agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+			result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, tt.stage2)
+
+			if math.Abs(result.Confidence-tt.wantConfidence) > 1e-9 {
+				t.Errorf("expected confidence %f, got %f", tt.wantConfidence, result.Confidence)
+			}
+			if result.Verdict != tt.wantVerdict {
+				t.Errorf("expected %s, got %s", tt.wantVerdict, result.Verdict)
+			}
+			if len(result.Stages) != len(stage1)+len(tt.stage2) {
+				t.Errorf("expected all %d stages in the result, got %d", len(stage1)+len(tt.stage2), len(result.Stages))
+			}
+		})
+	}
+}
+
+func TestAggregate_FailedStageDoesNotVeto(t *testing.T) {
+	policy := DefaultPolicy(Weights{PreChecks: 0.3, LLMJudge: 0.7})
+	policy.Vetoes = []Veto{{Stage: "faithfulness", Below: 0.3, Verdict: models.VerdictFail}}
+	policy.MaxFailedJudges = 1.0
+	agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+	stage1 := []models.StageResult{{Name: "length-checker", Score: 1.0}}
+	stage2 := []models.StageResult{
+		{Name: "relevance-judge", Score: 1.0, Status: models.StageStatusOK},
+		{Name: "faithfulness-judge", Score: 0.0, Status: models.StageStatusTimeout},
+	}
+
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+	if result.VetoedBy != "" {
+		t.Errorf("expected no veto from a timed out judge, got vetoed_by %q", result.VetoedBy)
+	}
+	if result.Verdict != models.VerdictPass {
+		t.Errorf("expected Pass, got %s", result.Verdict)
+	}
+}
+
+func TestAggregate_JudgeVariance(t *testing.T) {
+	stage1 := []models.StageResult{{Name: "length-checker", Score: 1.0}}
+
+	tests := []struct {
+		name        string
+		maxVariance float64
+		samples     *models.SampleStats
+		score       float64
+		wantVerdict models.Verdict
+	}{
+		{
+			name:        "agreeing samples pass",
+			maxVariance: 0.04,
+			samples:     &models.SampleStats{Count: 3, Mean: 0.9, Variance: 0.01},
+			score:       0.9,
+			wantVerdict: models.VerdictPass,
+		},
+		{
+			name:        "disagreeing samples are reviewed",
+			maxVariance: 0.04,
+			samples:     &models.SampleStats{Count: 3, Mean: 0.9, Variance: 0.05},
+			score:       0.9,
+			wantVerdict: models.VerdictReview,
+		},
+		{
+			name:        "a single scored sample has no variance to judge",
+			maxVariance: 0.04,
+			samples:     &models.SampleStats{Count: 1, Failed: 2, Mean: 0.9, Variance: 0.05},
+			score:       0.9,
+			wantVerdict: models.VerdictPass,
+		},
+		{
+			name:        "zero disables the check",
+			samples:     &models.SampleStats{Count: 3, Mean: 0.9, Variance: 0.2},
+			score:       0.9,
+			wantVerdict: models.VerdictPass,
+		},
+		{
+			name:        "a fail is not lifted to review",
+			maxVariance: 0.04,
+			samples:     &models.SampleStats{Count: 3, Mean: 0.2, Variance: 0.05},
+			score:       0.2,
+			wantVerdict: models.VerdictFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := DefaultPolicy(Weights{PreChecks: 0.0, LLMJudge: 1.0})
+			policy.MaxJudgeVariance = tt.maxVariance
+			agg := NewPolicyAggregator(policy, nil, newTestLogger())
+
+			stage2 := []models.StageResult{
+				{Name: "faithfulness-judge", Score: tt.score, Status: models.StageStatusOK, Samples: tt.samples},
+			}
+			result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+			if result.Verdict != tt.wantVerdict {
+				t.Errorf("expected %s, got %s", tt.wantVerdict, result.Verdict)
+			}
+		})
+	}
+}
+
+func TestAggregate_SumsUsage(t *testing.T) {
+	agg := NewAggregator(Weights{PreChecks: 0.3, LLMJudge: 0.7}, newTestLogger())
+
+	stage1 := []models.StageResult{{Name: "length", Score: 0.9}}
+	stage2 := []models.StageResult{
+		{Name: "relevance-judge", Score: 0.9, Usage: &models.TokenUsage{InputTokens: 100, OutputTokens: 20, Cost: 0.01}},
+		{Name: "coherence-judge", Score: 0.8, Usage: &models.TokenUsage{InputTokens: 50, OutputTokens: 10, Cost: 0.02}},
+		{Name: "faithfulness-judge", Score: 0.9, Cached: true},
+	}
+
+	result := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, stage2)
+
+	if result.Usage == nil {
+		t.Fatal("Expected usage on the result")
+	}
+	if result.Usage.InputTokens != 150 || result.Usage.OutputTokens != 30 || math.Abs(result.Usage.Cost-0.03) > 1e-12 {
+		t.Errorf("Expected usage 150/30/0.03, got %+v", result.Usage)
+	}
+
+	// Prechecks alone consume no tokens
+	noJudges := agg.Aggregate(models.EvaluationContext{RequestID: "test"}, stage1, []models.StageResult{{Name: "relevance-judge", Score: 0.9}})
+	if noJudges.Usage != nil {
+		t.Errorf("Expected no usage without LLM calls, got %+v", noJudges.Usage)
+	}
+}
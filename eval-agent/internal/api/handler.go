@@ -14,16 +14,23 @@ import (
 )
 
 type Handler struct {
-	executor      *executor.Executor
-	judgeExecutor *executor.JudgeExecutor
-	logger        *zerolog.Logger
+	executor           executor.Evaluator
+	judgeExecutor      *executor.JudgeExecutor
+	comparisonExecutor *executor.ComparisonExecutor
+	logger             *zerolog.Logger
 }
 
-func NewHandler(executor *executor.Executor, judgeExecutor *executor.JudgeExecutor, logger *zerolog.Logger) *Handler {
+func NewHandler(
+	executor executor.Evaluator,
+	judgeExecutor *executor.JudgeExecutor,
+	comparisonExecutor *executor.ComparisonExecutor,
+	logger *zerolog.Logger,
+) *Handler {
 	return &Handler{
-		executor:      executor,
-		judgeExecutor: judgeExecutor,
-		logger:        logger,
+		executor:           executor,
+		judgeExecutor:      judgeExecutor,
+		comparisonExecutor: comparisonExecutor,
+		logger:             logger,
 	}
 }
 
@@ -142,6 +149,62 @@ func (h *Handler) EvaluateSingleJudge(req *restful.Request, resp *restful.Respon
 
 }
 
+// POST /api/v1/compare
+// Body: ComparisonRequest
+// Returns: ComparisonResult
+func (h *Handler) Compare(req *restful.Request, resp *restful.Response) {
+	var cmpRequest models.ComparisonRequest
+	if err := req.ReadEntity(&cmpRequest); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		middleware.HandleError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := validateComparisonRequest(cmpRequest); err != nil {
+		h.logger.Warn().Err(err).Msg("Request validation failed")
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	h.logger.Info().
+		Str("event_id", cmpRequest.EventID).
+		Str("agent_a", cmpRequest.A.Agent.Name+" "+cmpRequest.A.Agent.Version).
+		Str("agent_b", cmpRequest.B.Agent.Name+" "+cmpRequest.B.Agent.Version).
+		Strs("judges", cmpRequest.Judges).
+		Msg("Start comparison")
+
+	ctx := req.Request.Context()
+	cmpResult, err := h.comparisonExecutor.Execute(ctx, cmpRequest.Judges, normalizeComparison(cmpRequest))
+
+	if err != nil {
+		if errors.Is(err, executor.ErrJudgeNotFound) {
+			h.logger.Warn().Err(err).Msg("Judge not found")
+			resp.WriteHeaderAndEntity(http.StatusNotFound, map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("Comparison failed")
+		resp.WriteHeaderAndEntity(http.StatusInternalServerError, map[string]string{
+			"error": "internal server error",
+		})
+		return
+	}
+
+	h.logger.Info().
+		Str("event_id", cmpResult.ID).
+		Str("winner", string(cmpResult.Winner)).
+		Int("wins_a", cmpResult.WinsA).
+		Int("wins_b", cmpResult.WinsB).
+		Int("ties", cmpResult.Ties).
+		Msg("Comparison complete")
+
+	resp.WriteHeaderAndEntity(http.StatusOK, cmpResult)
+}
+
 // Health handler GET API /api/v1/health
 func (h *Handler) Health(req *restful.Request, resp *restful.Response) {
 	healthResponse := HealthResponse{
@@ -158,10 +221,39 @@ func normalize(req models.EvaluationRequest) models.EvaluationContext {
 		Query:     req.Interaction.UserQuery,
 		Context:   req.Interaction.Context,
 		Answer:    req.Interaction.Answer,
+		Agent:     req.Agent,
 		CreatedAt: time.Now(),
+
+		ReferenceAnswer: req.Interaction.ReferenceAnswer,
+		OutputSchema:    req.Interaction.OutputSchema,
 	}
 }
 
+func normalizeComparison(req models.ComparisonRequest) models.ComparisonContext {
+	return models.ComparisonContext{
+		RequestID:       req.EventID,
+		Query:           req.UserQuery,
+		Context:         req.Context,
+		ReferenceAnswer: req.ReferenceAnswer,
+		A:               req.A,
+		B:               req.B,
+		CreatedAt:       time.Now(),
+	}
+}
+
+func validateComparisonRequest(cmpRequest models.ComparisonRequest) error {
+	if cmpRequest.EventID == "" {
+		return errors.New("event_id is required")
+	}
+	if cmpRequest.UserQuery == "" {
+		return errors.New("user_query is required")
+	}
+	if cmpRequest.A.Answer == "" || cmpRequest.B.Answer == "" {
+		return errors.New("a.answer and b.answer are required")
+	}
+	return nil
+}
+
 func validateEvaluationRequest(evalRequest models.EvaluationRequest) error {
 	if evalRequest.EventID == "" {
 		return errors.New("event_id is required")
@@ -49,5 +49,17 @@ func RegisterRoutes(container *restful.Container, handler *Handler) {
 			Returns(404, "Judge Not Found", middleware.ErrorResponse{}).
 			Returns(500, "Internal Server Error", middleware.ErrorResponse{}))
 
+	ws.
+		Route(ws.POST("/compare").
+			To(handler.Compare).
+			Doc("Compare two answers to the same query").
+			Metadata(restfulspec.KeyOpenAPITags, []string{"evaluate"}).
+			Reads(models.ComparisonRequest{}).
+			Writes(models.ComparisonResult{}).
+			Returns(200, "OK", models.ComparisonResult{}).
+			Returns(400, "Bad Request", middleware.ErrorResponse{}).
+			Returns(404, "Judge Not Found", middleware.ErrorResponse{}).
+			Returns(500, "Internal Server Error", middleware.ErrorResponse{}))
+
 	container.Add(ws)
 }
@@ -0,0 +1,301 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// checkpointLine is one line of a checkpoint file: either the config fingerprint the run
+// was started (or forcibly resumed) with, or a completed event
+type checkpointLine struct {
+	Config  map[string]string `json:"config,omitempty"`
+	EventID string            `json:"event_id,omitempty"`
+	Line    int               `json:"line,omitempty"` // Input line of the event
+}
+
+// Checkpoint is the manifest of a batch run: the fingerprint of the config it evaluates
+// with and the event IDs whose results were written. The file is append-only JSONL, so a
+// run killed mid-write loses at most the entry being written.
+type Checkpoint struct {
+	Config map[string]string
+
+	mu        sync.Mutex
+	file      *os.File
+	completed map[string]bool
+}
+
+// CreateCheckpoint starts the checkpoint of a new run, replacing the file of an earlier one
+func CreateCheckpoint(path string, config map[string]string) (*Checkpoint, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint %s: %w", path, err)
+	}
+
+	checkpoint := &Checkpoint{file: file, completed: make(map[string]bool)}
+	if err := checkpoint.SetConfig(config); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// OpenCheckpoint reads the checkpoint of an earlier run and reopens it for appending.
+// A partial last entry, left by a killed run, is dropped.
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint %s: %w", path, err)
+	}
+
+	if err := TrimPartialLine(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to repair checkpoint %s: %w", path, err)
+	}
+
+	checkpoint := &Checkpoint{file: file, completed: make(map[string]bool)}
+	if err := checkpoint.load(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", path, err)
+	}
+	if checkpoint.Config == nil {
+		file.Close()
+		return nil, fmt.Errorf("checkpoint %s has no config fingerprint", path)
+	}
+
+	return checkpoint, nil
+}
+
+func (c *Checkpoint) load() error {
+	if _, err := c.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(c.file)
+	for lineNum := 1; ; lineNum++ {
+		data, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			var line checkpointLine
+			if parseErr := json.Unmarshal(data, &line); parseErr != nil {
+				return fmt.Errorf("line %d: %w", lineNum, parseErr)
+			}
+			if line.Config != nil {
+				c.Config = line.Config
+			}
+			if line.EventID != "" {
+				c.completed[line.EventID] = true
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ConfigChanges returns the names of the configs whose fingerprint differs from the one
+// the checkpoint was written with, sorted
+func (c *Checkpoint) ConfigChanges(config map[string]string) []string {
+	var changed []string
+	for name := range c.Config {
+		if config[name] != c.Config[name] {
+			changed = append(changed, name)
+		}
+	}
+	for name := range config {
+		if _, ok := c.Config[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+
+	slices.Sort(changed)
+	return changed
+}
+
+// SetConfig records the fingerprint the run continues with, e.g. when resuming is forced
+// despite a config change
+func (c *Checkpoint) SetConfig(config map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Config = config
+	return c.append(checkpointLine{Config: config})
+}
+
+// Completed reports whether the result of the event was written by an earlier run
+func (c *Checkpoint) Completed(eventID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.completed[eventID]
+}
+
+// Len returns the number of completed events
+func (c *Checkpoint) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.completed)
+}
+
+// Mark records the record as completed once its result is written. Records that failed
+// to parse, have no event ID or have a stage that errored or timed out (e.g. a throttled
+// judge) are not recorded, so a resumed run evaluates them again (see PruneOutput).
+func (c *Checkpoint) Mark(record RecordResult) error {
+	if record.Error != nil || record.Result.ID == "" || hasFailedStage(record.Result) {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.completed[record.Result.ID] = true
+	return c.append(checkpointLine{EventID: record.Result.ID, Line: record.LineNumber})
+}
+
+func (c *Checkpoint) append(line checkpointLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.file.Write(append(data, '\n'))
+	return err
+}
+
+func hasFailedStage(result models.EvaluationResult) bool {
+	for _, stage := range result.Stages {
+		if stage.Status == models.StageStatusError || stage.Status == models.StageStatusTimeout {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending returns the records whose events are not completed yet. skipped, when not nil,
+// is called for every record dropped.
+func (c *Checkpoint) Pending(ctx context.Context, records <-chan InputRecord, skipped func(InputRecord)) <-chan InputRecord {
+	pending := make(chan InputRecord)
+
+	go func() {
+		defer close(pending)
+
+		for record := range records {
+			if record.Error == nil && c.Completed(record.Request.EventID) {
+				if skipped != nil {
+					skipped(record)
+				}
+				continue
+			}
+
+			select {
+			case pending <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return pending
+}
+
+// PruneOutput rewrites the JSONL output of an earlier run without the results of the
+// events that are not completed, i.e. failed stages or results written just before the
+// run was killed, so that the resumed run doesn't append a second line for them. A
+// partial last line is dropped too. Returns the number of lines dropped.
+func (c *Checkpoint) PruneOutput(path string) (int, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+
+	dropped := 0
+	reader := bufio.NewReader(in)
+	writer := bufio.NewWriter(out)
+	for {
+		data, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			var result struct {
+				ID string `json:"id"`
+			}
+			if bytes.HasSuffix(data, []byte("\n")) && json.Unmarshal(data, &result) == nil && c.Completed(result.ID) {
+				if _, err := writer.Write(data); err != nil {
+					return 0, err
+				}
+			} else {
+				dropped++
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return 0, err
+	}
+	if err := out.Close(); err != nil {
+		return 0, err
+	}
+	return dropped, os.Rename(out.Name(), path)
+}
+
+func (c *Checkpoint) Close() error {
+	return c.file.Close()
+}
+
+// TrimPartialLine truncates the file after its last newline, dropping the partial line a
+// killed run may have left, so that appended lines start on a line of their own
+func TrimPartialLine(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	size := info.Size()
+	buf := make([]byte, 4096)
+	for end := size; end > 0; {
+		start := max(end-int64(len(buf)), 0)
+		n, err := file.ReadAt(buf[:end-start], start)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			if keep := start + int64(i) + 1; keep < size {
+				return file.Truncate(keep)
+			}
+			return nil
+		}
+		end = start
+	}
+
+	// No newline at all, the whole content is a partial line
+	if size > 0 {
+		return file.Truncate(0)
+	}
+	return nil
+}
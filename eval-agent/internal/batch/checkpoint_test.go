@@ -0,0 +1,193 @@
+package batch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+func TestCheckpoint_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl.checkpoint")
+	config := map[string]string{"judges": "aaa", "prechecks": "bbb"}
+
+	checkpoint, err := CreateCheckpoint(path, config)
+	if err != nil {
+		t.Fatalf("CreateCheckpoint failed: %v", err)
+	}
+
+	marks := []RecordResult{
+		{LineNumber: 1, Result: models.EvaluationResult{ID: "1", Verdict: models.VerdictPass}},
+		{LineNumber: 2, Result: models.EvaluationResult{ID: "2", Stages: []models.StageResult{{Name: "relevance-judge", Status: models.StageStatusTimeout}}}},
+		{LineNumber: 3, Error: os.ErrInvalid},
+		{LineNumber: 4, Result: models.EvaluationResult{ID: "4", Stages: []models.StageResult{{Name: "length-checker", Status: models.StageStatusOK}}}},
+	}
+	for _, record := range marks {
+		if err := checkpoint.Mark(record); err != nil {
+			t.Fatalf("Mark failed: %v", err)
+		}
+	}
+	checkpoint.Close()
+
+	// A run killed mid-write leaves a partial entry
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	f.WriteString(`{"event_id":"5","li`)
+	f.Close()
+
+	resumed, err := OpenCheckpoint(path)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint failed: %v", err)
+	}
+	defer resumed.Close()
+
+	if resumed.Len() != 2 || !resumed.Completed("1") || !resumed.Completed("4") {
+		t.Errorf("Expected events 1 and 4 completed, got %d completed", resumed.Len())
+	}
+	if resumed.Completed("2") {
+		t.Error("Expected the timed out event to be evaluated again")
+	}
+	if changed := resumed.ConfigChanges(config); len(changed) != 0 {
+		t.Errorf("Expected no config changes, got %v", changed)
+	}
+
+	changed := resumed.ConfigChanges(map[string]string{"judges": "ccc", "prechecks": "bbb", "profiles": "ddd"})
+	if !slices.Equal(changed, []string{"judges", "profiles"}) {
+		t.Errorf("Expected judges and profiles changed, got %v", changed)
+	}
+
+	// Entries appended after the repair are readable
+	if err := resumed.Mark(RecordResult{LineNumber: 6, Result: models.EvaluationResult{ID: "6"}}); err != nil {
+		t.Fatalf("Mark failed: %v", err)
+	}
+	if err := resumed.SetConfig(map[string]string{"judges": "ccc"}); err != nil {
+		t.Fatalf("SetConfig failed: %v", err)
+	}
+	resumed.Close()
+
+	reopened, err := OpenCheckpoint(path)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint failed: %v", err)
+	}
+	defer reopened.Close()
+
+	if reopened.Len() != 3 || !reopened.Completed("6") {
+		t.Errorf("Expected event 6 completed after resume, got %d completed", reopened.Len())
+	}
+	if reopened.Config["judges"] != "ccc" {
+		t.Errorf("Expected the latest config fingerprint, got %v", reopened.Config)
+	}
+}
+
+func TestCheckpoint_Pending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	checkpoint, err := CreateCheckpoint(path, map[string]string{})
+	if err != nil {
+		t.Fatalf("CreateCheckpoint failed: %v", err)
+	}
+	defer checkpoint.Close()
+
+	checkpoint.Mark(RecordResult{LineNumber: 1, Result: models.EvaluationResult{ID: "1"}})
+
+	records := make(chan InputRecord, 3)
+	records <- InputRecord{LineNumber: 1, Offset: 10, Request: models.EvaluationRequest{EventID: "1"}}
+	records <- InputRecord{LineNumber: 2, Offset: 20, Error: os.ErrInvalid}
+	records <- InputRecord{LineNumber: 3, Offset: 30, Request: models.EvaluationRequest{EventID: "3"}}
+	close(records)
+
+	var skipped []int
+	var pending []int
+	for record := range checkpoint.Pending(context.Background(), records, func(record InputRecord) {
+		skipped = append(skipped, record.LineNumber)
+	}) {
+		pending = append(pending, record.LineNumber)
+	}
+
+	if !slices.Equal(skipped, []int{1}) || !slices.Equal(pending, []int{2, 3}) {
+		t.Errorf("Expected line 1 skipped and lines 2, 3 pending, got %v and %v", skipped, pending)
+	}
+}
+
+func TestCheckpoint_PruneOutput(t *testing.T) {
+	dir := t.TempDir()
+	checkpoint, err := CreateCheckpoint(filepath.Join(dir, "checkpoint"), map[string]string{})
+	if err != nil {
+		t.Fatalf("CreateCheckpoint failed: %v", err)
+	}
+	defer checkpoint.Close()
+
+	checkpoint.Mark(RecordResult{LineNumber: 1, Result: models.EvaluationResult{ID: "1"}})
+	checkpoint.Mark(RecordResult{LineNumber: 3, Result: models.EvaluationResult{ID: "3"}})
+
+	// Event 2 had a timed out judge, event 4 was written but not checkpointed before the
+	// run was killed mid-write of event 5
+	output := filepath.Join(dir, "results.jsonl")
+	os.WriteFile(output, []byte(
+		`{"id":"1","verdict":"pass"}`+"\n"+
+			`{"id":"2","verdict":"fail"}`+"\n"+
+			`{"id":"3","verdict":"pass"}`+"\n"+
+			`{"id":"4","verdict":"pass"}`+"\n"+
+			`{"id":"5","ver`), 0644)
+
+	dropped, err := checkpoint.PruneOutput(output)
+	if err != nil {
+		t.Fatalf("PruneOutput failed: %v", err)
+	}
+	if dropped != 3 {
+		t.Errorf("Expected 3 lines dropped, got %d", dropped)
+	}
+
+	data, _ := os.ReadFile(output)
+	want := `{"id":"1","verdict":"pass"}` + "\n" + `{"id":"3","verdict":"pass"}` + "\n"
+	if string(data) != want {
+		t.Errorf("Expected only the completed results kept, got %q", data)
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected the temporary file removed, got %d files", len(entries))
+	}
+}
+
+func TestOpenCheckpoint_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := OpenCheckpoint(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected error for a missing checkpoint")
+	}
+
+	path := filepath.Join(dir, "no-config")
+	os.WriteFile(path, []byte(`{"event_id":"1","line":1}`+"\n"), 0644)
+	if _, err := OpenCheckpoint(path); err == nil || !strings.Contains(err.Error(), "no config fingerprint") {
+		t.Errorf("Expected missing fingerprint error, got: %v", err)
+	}
+}
+
+func TestTrimPartialLine(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"", ""},
+		{"a\nb\n", "a\nb\n"},
+		{"a\nb\npartial", "a\nb\n"},
+		{"partial", ""},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "output.jsonl")
+		os.WriteFile(path, []byte(tt.content), 0644)
+
+		f, _ := os.OpenFile(path, os.O_RDWR, 0)
+		if err := TrimPartialLine(f); err != nil {
+			t.Fatalf("TrimPartialLine(%q) failed: %v", tt.content, err)
+		}
+		f.Close()
+
+		if got, _ := os.ReadFile(path); string(got) != tt.want {
+			t.Errorf("TrimPartialLine(%q) left %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package batch
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type ComparisonExecutor interface {
+	Execute(ctx context.Context, judgeNames []string, cmpCtx models.ComparisonContext) (models.ComparisonResult, error)
+}
+
+// ComparisonPair is one query answered by the baseline (A) and the candidate (B)
+type ComparisonPair struct {
+	Baseline  models.EvaluationRequest
+	Candidate models.EvaluationRequest
+}
+
+// PairRecords matches candidate records to baseline records by event_id, in candidate
+// order. Records with parse errors are skipped, event IDs found in only one of the
+// inputs are returned as unmatched: the candidate's first, then the baseline's, each in
+// input order.
+func PairRecords(baseline []InputRecord, candidate []InputRecord) ([]ComparisonPair, []string) {
+	baselineByID := make(map[string]models.EvaluationRequest, len(baseline))
+	for _, record := range baseline {
+		if record.Error == nil {
+			baselineByID[record.Request.EventID] = record.Request
+		}
+	}
+
+	var pairs []ComparisonPair
+	var unmatched []string
+	paired := make(map[string]bool, len(candidate))
+
+	for _, record := range candidate {
+		if record.Error != nil {
+			continue
+		}
+
+		id := record.Request.EventID
+		request, ok := baselineByID[id]
+		if !ok {
+			unmatched = append(unmatched, id)
+			continue
+		}
+
+		pairs = append(pairs, ComparisonPair{Baseline: request, Candidate: record.Request})
+		paired[id] = true
+	}
+
+	// Baseline-only records in input order, each reported once
+	for _, record := range baseline {
+		id := record.Request.EventID
+		if record.Error != nil || paired[id] {
+			continue
+		}
+		unmatched = append(unmatched, id)
+		paired[id] = true
+	}
+
+	return pairs, unmatched
+}
+
+type ComparisonProcessor struct {
+	executor ComparisonExecutor
+	judges   []string
+	workers  int
+	logger   *zerolog.Logger
+}
+
+// NewComparisonProcessor creates a processor comparing pairs with the given judges, all when empty
+func NewComparisonProcessor(exec ComparisonExecutor, judges []string, workers int, logger *zerolog.Logger) *ComparisonProcessor {
+	return &ComparisonProcessor{
+		executor: exec,
+		judges:   judges,
+		workers:  workers,
+		logger:   logger,
+	}
+}
+
+// Process compares the pairs and returns the comparison results via channel. A pair
+// that could not be compared is returned without a winner.
+func (p *ComparisonProcessor) Process(ctx context.Context, pairs []ComparisonPair) <-chan models.ComparisonResult {
+	results := make(chan models.ComparisonResult, len(pairs))
+	jobs := make(chan ComparisonPair, len(pairs))
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go p.worker(ctx, i, jobs, results, &wg)
+	}
+
+	p.logger.Info().
+		Int("workers", p.workers).
+		Int("total_pairs", len(pairs)).
+		Msg("Starting comparison worker pool")
+
+	for _, pair := range pairs {
+		jobs <- pair
+	}
+	close(jobs)
+
+	go func() {
+		wg.Wait()
+		close(results)
+		p.logger.Info().Msg("Comparison worker pool finished")
+	}()
+
+	return results
+}
+
+func (p *ComparisonProcessor) worker(ctx context.Context, workerID int, jobs <-chan ComparisonPair, results chan<- models.ComparisonResult, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for pair := range jobs {
+		cmpCtx := models.ComparisonContext{
+			RequestID:       pair.Candidate.EventID,
+			Query:           pair.Candidate.Interaction.UserQuery,
+			Context:         pair.Candidate.Interaction.Context,
+			ReferenceAnswer: pair.Candidate.Interaction.ReferenceAnswer,
+			A:               models.Candidate{Agent: pair.Baseline.Agent, Answer: pair.Baseline.Interaction.Answer},
+			B:               models.Candidate{Agent: pair.Candidate.Agent, Answer: pair.Candidate.Interaction.Answer},
+			CreatedAt:       time.Now(),
+		}
+
+		result, err := p.executor.Execute(ctx, p.judges, cmpCtx)
+		if err != nil {
+			p.logger.Error().
+				Int("worker", workerID).
+				Str("event_id", cmpCtx.RequestID).
+				Err(err).
+				Msg("Comparison failed")
+		}
+		results <- result
+	}
+
+	p.logger.Debug().Int("worker", workerID).Msg("Worker finished")
+}
+
+// ComparisonReport counts the candidate's wins, ties and losses against the baseline
+type ComparisonReport struct {
+	Baseline  string                  `json:"baseline"`  // Agent version of the baseline answers
+	Candidate string                  `json:"candidate"` // Agent version of the candidate answers
+	Total     int                     `json:"total"`
+	Wins      int                     `json:"wins"`
+	Ties      int                     `json:"ties"`
+	Losses    int                     `json:"losses"`
+	Errors    int                     `json:"errors"`   // Pairs no judge stated a preference for
+	WinRate   float64                 `json:"win_rate"` // Wins over compared pairs, ties count as half a win
+	Judges    map[string]*JudgeRecord `json:"judges"`
+	Usage     models.TokenUsage       `json:"usage"`
+}
+
+// JudgeRecord counts one judge's preferences for the candidate
+type JudgeRecord struct {
+	Wins         int `json:"wins"`
+	Ties         int `json:"ties"`
+	Losses       int `json:"losses"`
+	Errors       int `json:"errors"`
+	Inconsistent int `json:"inconsistent"` // Ties caused by the preference changing with the answer order
+}
+
+// NewComparisonReport tallies the comparison results of baseline (A) against candidate (B)
+func NewComparisonReport(baseline string, candidate string, results []models.ComparisonResult) ComparisonReport {
+	report := ComparisonReport{
+		Baseline:  baseline,
+		Candidate: candidate,
+		Total:     len(results),
+		Judges:    make(map[string]*JudgeRecord),
+	}
+
+	for _, result := range results {
+		if result.Usage != nil {
+			report.Usage.Add(*result.Usage)
+		}
+
+		switch result.Winner {
+		case models.PreferenceB:
+			report.Wins++
+		case models.PreferenceA:
+			report.Losses++
+		case models.PreferenceTie:
+			report.Ties++
+		default:
+			report.Errors++
+		}
+
+		for _, preference := range result.Judges {
+			record, ok := report.Judges[preference.Name]
+			if !ok {
+				record = &JudgeRecord{}
+				report.Judges[preference.Name] = record
+			}
+
+			switch {
+			case preference.Status == models.StageStatusSkipped:
+			case !preference.OK():
+				record.Errors++
+			case preference.Preference == models.PreferenceB:
+				record.Wins++
+			case preference.Preference == models.PreferenceA:
+				record.Losses++
+			default:
+				record.Ties++
+				if !preference.Consistent {
+					record.Inconsistent++
+				}
+			}
+		}
+	}
+
+	if compared := report.Wins + report.Ties + report.Losses; compared > 0 {
+		report.WinRate = (float64(report.Wins) + 0.5*float64(report.Ties)) / float64(compared)
+	}
+
+	return report
+}
+
+// JudgeNames returns the judges of the report in name order
+func (r ComparisonReport) JudgeNames() []string {
+	names := make([]string, 0, len(r.Judges))
+	for name := range r.Judges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,146 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Mock comparison executor preferring the longer answer
+type mockComparisonExecutor struct{}
+
+func (m *mockComparisonExecutor) Execute(ctx context.Context, judgeNames []string, cmpCtx models.ComparisonContext) (models.ComparisonResult, error) {
+	if cmpCtx.RequestID == "broken" {
+		return models.ComparisonResult{ID: cmpCtx.RequestID}, fmt.Errorf("judge not found")
+	}
+
+	winner := models.PreferenceTie
+	switch {
+	case len(cmpCtx.A.Answer) > len(cmpCtx.B.Answer):
+		winner = models.PreferenceA
+	case len(cmpCtx.B.Answer) > len(cmpCtx.A.Answer):
+		winner = models.PreferenceB
+	}
+	return models.ComparisonResult{ID: cmpCtx.RequestID, Winner: winner}, nil
+}
+
+func request(id string, version string, answer string) models.EvaluationRequest {
+	return models.EvaluationRequest{
+		EventID:     id,
+		Agent:       models.Agent{Name: "kg-agent", Version: version},
+		Interaction: models.Interaction{UserQuery: "q", Answer: answer},
+	}
+}
+
+func TestPairRecords(t *testing.T) {
+	baseline := []InputRecord{
+		{LineNumber: 1, Request: request("1", "v1", "a")},
+		{LineNumber: 2, Request: request("2", "v1", "b")},
+		{LineNumber: 3, Request: request("only-baseline-b", "v1", "c")},
+		{LineNumber: 4, Request: request("only-baseline-a", "v1", "e")},
+	}
+	candidate := []InputRecord{
+		{LineNumber: 1, Request: request("2", "v2", "bb")},
+		{LineNumber: 2, Error: fmt.Errorf("parse error")},
+		{LineNumber: 3, Request: request("1", "v2", "aa")},
+		{LineNumber: 4, Request: request("only-candidate", "v2", "d")},
+	}
+
+	pairs, unmatched := PairRecords(baseline, candidate)
+
+	if len(pairs) != 2 {
+		t.Fatalf("expected 2 pairs, got %d", len(pairs))
+	}
+	if pairs[0].Candidate.EventID != "2" || pairs[0].Baseline.Interaction.Answer != "b" || pairs[0].Candidate.Interaction.Answer != "bb" {
+		t.Errorf("expected pairs in candidate order matched by event_id, got %+v", pairs[0])
+	}
+
+	// Candidate-only records first, then baseline-only ones, both in input order
+	if !slices.Equal(unmatched, []string{"only-candidate", "only-baseline-b", "only-baseline-a"}) {
+		t.Errorf("expected unmatched records from both files in input order, got %v", unmatched)
+	}
+}
+
+func TestComparisonProcessor_Process(t *testing.T) {
+	logger := zerolog.Nop()
+	processor := NewComparisonProcessor(&mockComparisonExecutor{}, nil, 2, &logger)
+
+	pairs := []ComparisonPair{
+		{Baseline: request("1", "v1", "short"), Candidate: request("1", "v2", "much longer")},
+		{Baseline: request("2", "v1", "longer one"), Candidate: request("2", "v2", "short")},
+		{Baseline: request("broken", "v1", "a"), Candidate: request("broken", "v2", "b")},
+	}
+
+	winners := make(map[string]models.Preference)
+	for result := range processor.Process(context.Background(), pairs) {
+		winners[result.ID] = result.Winner
+	}
+
+	// The baseline is A and the candidate is B
+	if winners["1"] != models.PreferenceB || winners["2"] != models.PreferenceA {
+		t.Errorf("expected candidate to win 1 and lose 2, got %v", winners)
+	}
+	if winner, ok := winners["broken"]; !ok || winner != "" {
+		t.Errorf("expected failed comparison without a winner, got %q (returned: %v)", winner, ok)
+	}
+}
+
+func TestNewComparisonReport(t *testing.T) {
+	ok := func(name string, preference models.Preference, consistent bool) models.PreferenceResult {
+		return models.PreferenceResult{Name: name, Preference: preference, Status: models.StageStatusOK, Consistent: consistent}
+	}
+
+	results := []models.ComparisonResult{
+		{
+			ID:     "1",
+			Winner: models.PreferenceB,
+			Judges: []models.PreferenceResult{ok("relevance-judge", models.PreferenceB, true), ok("coherence-judge", models.PreferenceB, true)},
+			Usage:  &models.TokenUsage{InputTokens: 100, Cost: 0.1},
+		},
+		{
+			ID:     "2",
+			Winner: models.PreferenceTie,
+			Judges: []models.PreferenceResult{ok("relevance-judge", models.PreferenceTie, false), ok("coherence-judge", models.PreferenceTie, true)},
+		},
+		{
+			ID:     "3",
+			Winner: models.PreferenceA,
+			Judges: []models.PreferenceResult{ok("relevance-judge", models.PreferenceA, true), {Name: "coherence-judge", Status: models.StageStatusError}},
+		},
+		{ID: "4"},
+	}
+
+	report := NewComparisonReport("v1", "v2", results)
+
+	if report.Total != 4 || report.Wins != 1 || report.Ties != 1 || report.Losses != 1 || report.Errors != 1 {
+		t.Errorf("expected 1 win, tie, loss and error out of 4, got %+v", report)
+	}
+	// (1 + 0.5) / 3
+	if math.Abs(report.WinRate-0.5) > 1e-9 {
+		t.Errorf("expected win rate 0.5, got %f", report.WinRate)
+	}
+	if report.Baseline != "v1" || report.Candidate != "v2" {
+		t.Errorf("expected baseline v1 and candidate v2, got %s and %s", report.Baseline, report.Candidate)
+	}
+	if report.Usage.InputTokens != 100 {
+		t.Errorf("expected usage summed across results, got %+v", report.Usage)
+	}
+
+	relevance := report.Judges["relevance-judge"]
+	if relevance == nil || relevance.Wins != 1 || relevance.Ties != 1 || relevance.Losses != 1 || relevance.Inconsistent != 1 {
+		t.Errorf("unexpected relevance record: %+v", relevance)
+	}
+	coherence := report.Judges["coherence-judge"]
+	if coherence == nil || coherence.Errors != 1 || coherence.Inconsistent != 0 {
+		t.Errorf("unexpected coherence record: %+v", coherence)
+	}
+
+	if names := report.JudgeNames(); !slices.Equal(names, []string{"coherence-judge", "relevance-judge"}) {
+		t.Errorf("expected judges in name order, got %v", names)
+	}
+}
@@ -0,0 +1,72 @@
+package batch
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// CSVWriter writes one row per result with one column per stage score. The stage columns
+// are only known once every result is seen, so rows are held until Close; a row is a few
+// values, not the whole result.
+type CSVWriter struct {
+	output io.Writer
+	logger *zerolog.Logger
+	rows   []flatRow
+	stages []string // Stage columns in order of first appearance
+	seen   map[string]bool
+}
+
+func NewCSVWriter(output io.Writer, logger *zerolog.Logger) *CSVWriter {
+	return &CSVWriter{
+		output: output,
+		logger: logger,
+		seen:   make(map[string]bool),
+	}
+}
+
+func (w *CSVWriter) Write(result models.EvaluationResult) error {
+	row := flatten(result)
+	for _, column := range row.scores {
+		if !w.seen[column.name] {
+			w.seen[column.name] = true
+			w.stages = append(w.stages, column.name)
+		}
+	}
+
+	w.rows = append(w.rows, row)
+	return nil
+}
+
+func (w *CSVWriter) Close() error {
+	writer := csv.NewWriter(w.output)
+
+	header := append(append([]string{}, resultColumns...), w.stages...)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range w.rows {
+		scores := make(map[string]*float64, len(row.scores))
+		for _, column := range row.scores {
+			scores[column.name] = column.score
+		}
+
+		record := make([]string, 0, len(header))
+		for _, value := range row.values {
+			record = append(record, formatCell(value))
+		}
+		for _, stage := range w.stages {
+			record = append(record, formatCell(scores[stage]))
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
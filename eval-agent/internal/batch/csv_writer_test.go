@@ -0,0 +1,135 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func flatResults() []models.EvaluationResult {
+	return []models.EvaluationResult{
+		{
+			ID:         "1",
+			Agent:      &models.Agent{Name: "support-bot", Version: "1.0"},
+			EventType:  models.EventTypeAgentResponse,
+			Duration:   1500 * time.Millisecond,
+			Verdict:    models.VerdictPass,
+			Confidence: 0.9,
+			Usage:      &models.TokenUsage{InputTokens: 100, OutputTokens: 20, Cost: 0.5},
+			Stages: []models.StageResult{
+				{Name: "length-checker", Score: 1.0, Status: models.StageStatusOK},
+				{Name: "relevance-judge", Score: 0.8, Status: models.StageStatusOK},
+			},
+		},
+		{
+			ID:         "2",
+			Verdict:    models.VerdictFail,
+			Confidence: 0.25,
+			Stages: []models.StageResult{
+				{Name: "length-checker", Score: 0.5, Status: models.StageStatusOK},
+				{Name: "relevance-judge", Status: models.StageStatusError, Reason: "throttled"},
+				{Name: "faithfulness-judge", Score: 0.1, Status: models.StageStatusOK},
+			},
+		},
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.Nop()
+	writer := NewCSVWriter(&buf, &logger)
+
+	for _, result := range flatResults() {
+		if err := writer.Write(result); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV output: %v", err)
+	}
+
+	want := [][]string{
+		{"id", "agent", "agent_version", "event_type", "verdict", "confidence", "profile", "policy", "vetoed_by", "input_tokens", "output_tokens", "cost", "duration_ms", "score.length-checker", "score.relevance-judge", "score.faithfulness-judge"},
+		{"1", "support-bot", "1.0", "agent_response", "pass", "0.9", "", "", "", "100", "20", "0.5", "1500", "1", "0.8", ""},
+		{"2", "", "", "", "fail", "0.25", "", "", "", "0", "0", "0", "0", "0.5", "", "0.1"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if strings.Join(records[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d: got %v, want %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestFlatJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.Nop()
+	writer := NewFlatJSONWriter(&buf, &logger)
+
+	for _, result := range flatResults() {
+		if err := writer.Write(result); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var rows []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rows); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, buf.String())
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+
+	if rows[0]["id"] != "1" || rows[0]["score.relevance-judge"] != 0.8 || rows[0]["input_tokens"] != 100.0 {
+		t.Errorf("unexpected first row: %v", rows[0])
+	}
+	if score, ok := rows[1]["score.relevance-judge"]; !ok || score != nil {
+		t.Errorf("expected null score of the errored stage, got %v", score)
+	}
+
+	// Columns keep their order
+	if !strings.HasPrefix(buf.String(), `[`+"\n"+`{"id":"1","agent":"support-bot","agent_version":"1.0","event_type":"agent_response","verdict":"pass",`) {
+		t.Errorf("unexpected column order: %s", buf.String())
+	}
+}
+
+func TestFlatJSONWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.Nop()
+	writer := NewFlatJSONWriter(&buf, &logger)
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("got %q, want an empty array", buf.String())
+	}
+}
+
+func TestNewWriter_Formats(t *testing.T) {
+	logger := zerolog.Nop()
+	for _, format := range Formats {
+		if _, err := NewWriter(&bytes.Buffer{}, format, &logger); err != nil {
+			t.Errorf("NewWriter(%q) failed: %v", format, err)
+		}
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, "xml", &logger); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
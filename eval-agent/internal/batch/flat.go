@@ -0,0 +1,128 @@
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// scoreColumnPrefix starts the name of the score column of a stage, so that a stage can't
+// collide with a result column
+const scoreColumnPrefix = "score."
+
+// resultColumns are the result fields of a flat row, followed by one column per stage score
+var resultColumns = []string{"id", "agent", "agent_version", "event_type", "verdict", "confidence", "profile", "policy", "vetoed_by", "input_tokens", "output_tokens", "cost", "duration_ms"}
+
+// flatRow is an evaluation result flattened into columns for tabular tools
+type flatRow struct {
+	values []any        // In the order of resultColumns
+	scores []stageScore // In stage order
+}
+
+// stageScore is the score column of a stage, score.<stage>, nil when the stage produced
+// no score so that an errored judge is not mistaken for a bad answer
+type stageScore struct {
+	name  string
+	score *float64
+}
+
+func flatten(result models.EvaluationResult) flatRow {
+	var usage models.TokenUsage
+	if result.Usage != nil {
+		usage = *result.Usage
+	}
+	var agent models.Agent
+	if result.Agent != nil {
+		agent = *result.Agent
+	}
+
+	row := flatRow{
+		values: []any{
+			result.ID,
+			agent.Name,
+			agent.Version,
+			string(result.EventType),
+			string(result.Verdict),
+			result.Confidence,
+			result.Profile,
+			result.Policy,
+			result.VetoedBy,
+			usage.InputTokens,
+			usage.OutputTokens,
+			usage.Cost,
+			result.Duration.Milliseconds(),
+		},
+	}
+
+	for _, stage := range result.Stages {
+		column := stageScore{name: scoreColumnPrefix + stage.Name}
+		if stage.OK() {
+			score := stage.Score
+			column.score = &score
+		}
+		row.scores = append(row.scores, column)
+	}
+
+	return row
+}
+
+// MarshalJSON writes the row as one flat object, with the columns in order
+func (r flatRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	write := func(name string, value any) error {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(data)
+		return nil
+	}
+
+	for i, name := range resultColumns {
+		if err := write(name, r.values[i]); err != nil {
+			return nil, err
+		}
+	}
+	for _, column := range r.scores {
+		if err := write(column.name, column.score); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// formatCell formats a value for a CSV cell, a missing score is an empty cell
+func formatCell(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *float64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
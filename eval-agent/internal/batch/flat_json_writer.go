@@ -0,0 +1,52 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// FlatJSONWriter writes a JSON array of flat rows, one object per result with one key per
+// stage score, as read by pandas.read_json and DuckDB's read_json. Rows are written as
+// they arrive.
+type FlatJSONWriter struct {
+	output io.Writer
+	logger *zerolog.Logger
+	rows   int
+}
+
+func NewFlatJSONWriter(output io.Writer, logger *zerolog.Logger) *FlatJSONWriter {
+	return &FlatJSONWriter{
+		output: output,
+		logger: logger,
+	}
+}
+
+func (w *FlatJSONWriter) Write(result models.EvaluationResult) error {
+	data, err := json.Marshal(flatten(result))
+	if err != nil {
+		return fmt.Errorf("Failed to marshal the result. Error: %w", err)
+	}
+
+	separator := ",\n"
+	if w.rows == 0 {
+		separator = "[\n"
+	}
+	w.rows++
+
+	_, err = w.output.Write(append([]byte(separator), data...))
+	return err
+}
+
+func (w *FlatJSONWriter) Close() error {
+	closing := "\n]\n"
+	if w.rows == 0 {
+		closing = "[]\n"
+	}
+
+	_, err := io.WriteString(w.output, closing)
+	return err
+}
@@ -0,0 +1,288 @@
+package batch
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+const (
+	histogramBins = 10 // Score buckets of 0.1
+	worstExamples = 10 // Results listed in the worst-scoring section
+	worstStages   = 3  // Lowest stages shown per worst result
+)
+
+// HTMLWriter writes a self-contained HTML report of the run: the summary statistics, the
+// verdict distribution, a score histogram per stage, the mean stage scores per agent and
+// the worst-scoring results with the reasons of their lowest stages. It has no external
+// assets, so it can be attached to a CI run or mailed as is. Only the histogram counts and
+// the worst results are kept, not every result.
+type HTMLWriter struct {
+	output  io.Writer
+	logger  *zerolog.Logger
+	summary *SummaryWriter
+	now     func() time.Time
+
+	stageNames []string         // In order of first appearance
+	histograms map[string][]int // Keyed by stage name
+	worst      []htmlExample    // Sorted by confidence, at most worstExamples
+}
+
+func NewHTMLWriter(output io.Writer, logger *zerolog.Logger) *HTMLWriter {
+	return &HTMLWriter{
+		output:     output,
+		logger:     logger,
+		summary:    NewSummaryWriter(io.Discard, logger),
+		now:        time.Now,
+		histograms: make(map[string][]int),
+	}
+}
+
+func (w *HTMLWriter) Write(result models.EvaluationResult) error {
+	for _, stage := range result.Stages {
+		counts, ok := w.histograms[stage.Name]
+		if !ok {
+			counts = make([]int, histogramBins)
+			w.histograms[stage.Name] = counts
+			w.stageNames = append(w.stageNames, stage.Name)
+		}
+		if stage.OK() {
+			counts[min(max(int(stage.Score*histogramBins), 0), histogramBins-1)]++
+		}
+	}
+
+	// Results of equal confidence keep the order they were written in
+	i, _ := slices.BinarySearchFunc(w.worst, result.Confidence, func(e htmlExample, confidence float64) int {
+		if e.Confidence <= confidence {
+			return -1
+		}
+		return 1
+	})
+	if i < worstExamples {
+		w.worst = slices.Insert(w.worst, i, htmlExample{
+			ID:         result.ID,
+			Verdict:    result.Verdict,
+			Confidence: result.Confidence,
+			VetoedBy:   result.VetoedBy,
+			Stages:     lowestStages(result.Stages, worstStages),
+		})
+		w.worst = w.worst[:min(len(w.worst), worstExamples)]
+	}
+
+	return w.summary.Write(result)
+}
+
+func (w *HTMLWriter) Close() error {
+	return reportTemplate.Execute(w.output, w.report())
+}
+
+type htmlReport struct {
+	Generated string
+	Stats     SummaryStats
+	Verdicts  []htmlBar
+	Stages    []htmlHistogram
+	Agents    []htmlAgent
+	Worst     []htmlExample
+}
+
+// htmlBar is one bar of a chart, Width is relative to the longest bar in percent
+type htmlBar struct {
+	Label string
+	Count int
+	Width float64
+}
+
+type htmlHistogram struct {
+	Name  string
+	Stats StageStats
+	Bins  []htmlBar
+}
+
+// htmlAgent is a row of the agent table, with the mean score of every stage column
+type htmlAgent struct {
+	Name   string
+	Stats  GroupStats
+	Scores []string // Formatted, "-" when the agent's results have no score of the stage
+}
+
+type htmlExample struct {
+	ID         string
+	Verdict    models.Verdict
+	Confidence float64
+	VetoedBy   string
+	Stages     []models.StageResult
+}
+
+func (w *HTMLWriter) report() htmlReport {
+	stats := w.summary.computeStats()
+
+	report := htmlReport{
+		Generated: w.now().UTC().Format(time.RFC3339),
+		Stats:     stats,
+		Verdicts:  bars([]string{"pass", "review", "fail"}, []int{stats.PassCount, stats.ReviewCount, stats.FailCount}),
+		Worst:     w.worst,
+	}
+
+	// Score histogram per stage
+	labels := make([]string, histogramBins)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("%.1f", float64(i)/histogramBins)
+	}
+	for _, name := range w.stageNames {
+		report.Stages = append(report.Stages, htmlHistogram{
+			Name:  name,
+			Stats: stats.Stages[name],
+			Bins:  bars(labels, w.histograms[name]),
+		})
+	}
+
+	// Mean stage scores per agent, to show which dimension an agent is weak in
+	for _, key := range slices.Sorted(maps.Keys(stats.Agents)) {
+		group := stats.Agents[key]
+		agent := htmlAgent{Name: key, Stats: group}
+		for _, name := range w.stageNames {
+			score := "-"
+			if stage, ok := group.Stages[name]; ok && stage.Scored > 0 {
+				score = fmt.Sprintf("%.3f", stage.Mean)
+			}
+			agent.Scores = append(agent.Scores, score)
+		}
+		report.Agents = append(report.Agents, agent)
+	}
+
+	return report
+}
+
+// bars turns counts into bars scaled to the largest count
+func bars(labels []string, counts []int) []htmlBar {
+	longest := slices.Max(counts)
+
+	result := make([]htmlBar, len(counts))
+	for i, count := range counts {
+		result[i] = htmlBar{Label: labels[i], Count: count}
+		if longest > 0 {
+			result[i].Width = float64(count) / float64(longest) * 100
+		}
+	}
+	return result
+}
+
+// lowestStages returns up to n stages that failed or scored lowest, failed ones first.
+// Skipped stages are left out.
+func lowestStages(stages []models.StageResult, n int) []models.StageResult {
+	var relevant []models.StageResult
+	for _, stage := range stages {
+		if stage.Status != models.StageStatusSkipped {
+			relevant = append(relevant, stage)
+		}
+	}
+
+	slices.SortStableFunc(relevant, func(a, b models.StageResult) int {
+		if a.Failed() != b.Failed() {
+			if a.Failed() {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.Score, b.Score)
+	})
+	return relevant[:min(len(relevant), n)]
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(count, total int) string {
+		if total == 0 {
+			return "0.0"
+		}
+		return fmt.Sprintf("%.1f", float64(count)/float64(total)*100)
+	},
+	"seconds": func(d time.Duration) string {
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Evaluation report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 1100px; color: #222; }
+h1 { margin-bottom: 0; }
+.muted { color: #777; }
+.cards { display: flex; gap: 1rem; flex-wrap: wrap; margin: 1.5rem 0; }
+.card { border: 1px solid #ddd; border-radius: 6px; padding: 0.75rem 1rem; min-width: 8rem; }
+.card strong { display: block; font-size: 1.5rem; }
+.chart { margin-bottom: 1.5rem; }
+.row { display: flex; align-items: center; gap: 0.5rem; margin: 2px 0; }
+.row .label { width: 4rem; text-align: right; font-family: monospace; }
+.row .track { flex: 1; background: #f3f3f3; }
+.row .bar { height: 1rem; background: #4a78c2; }
+.row .count { width: 4rem; font-family: monospace; }
+.pass .bar { background: #3c9a5f; }
+.review .bar { background: #d9a32b; }
+.fail .bar { background: #c94a4a; }
+.histograms { display: grid; grid-template-columns: repeat(auto-fill, minmax(320px, 1fr)); gap: 1.5rem; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #eee; padding: 0.4rem; text-align: left; vertical-align: top; }
+td.num { font-family: monospace; white-space: nowrap; }
+.stage { margin-bottom: 0.4rem; }
+</style>
+</head>
+<body>
+<h1>Evaluation report</h1>
+<p class="muted">Generated {{.Generated}}</p>
+
+<div class="cards">
+<div class="card"><strong>{{.Stats.Total}}</strong>results</div>
+<div class="card"><strong>{{percent .Stats.PassCount .Stats.Total}}%</strong>pass</div>
+<div class="card"><strong>{{printf "%.3f" .Stats.AvgConfidence}}</strong>mean confidence</div>
+<div class="card"><strong>{{.Stats.Usage.InputTokens}} / {{.Stats.Usage.OutputTokens}}</strong>input / output tokens</div>
+<div class="card"><strong>${{printf "%.4f" .Stats.Usage.Cost}}</strong>estimated cost</div>
+{{with .Stats.Latency}}<div class="card"><strong>{{seconds .P50}} / {{seconds .P90}} / {{seconds .P99}}</strong>latency p50 / p90 / p99</div>{{end}}
+</div>
+
+<h2>Verdicts</h2>
+<div class="chart">
+{{range .Verdicts}}<div class="row {{.Label}}"><span class="label">{{.Label}}</span><span class="track"><div class="bar" style="width: {{printf "%.1f" .Width}}%"></div></span><span class="count">{{.Count}}</span></div>
+{{end}}</div>
+
+<h2>Scores by stage</h2>
+<div class="histograms">
+{{range .Stages}}<div class="chart">
+<h3>{{.Name}}</h3>
+{{with .Stats}}<p class="muted">{{.Scored}} scored, mean {{printf "%.3f" .Mean}}, p50 {{printf "%.2f" .P50}}, p90 {{printf "%.2f" .P90}}, min {{printf "%.2f" .Min}}{{if .Errors}}, {{.Errors}} errored{{end}}{{if .Timeouts}}, {{.Timeouts}} timed out{{end}}, {{seconds .MeanDuration}} mean</p>{{end}}
+{{range .Bins}}<div class="row"><span class="label">{{.Label}}</span><span class="track"><div class="bar" style="width: {{printf "%.1f" .Width}}%"></div></span><span class="count">{{.Count}}</span></div>
+{{end}}</div>
+{{end}}</div>
+
+{{if .Agents}}<h2>Agents</h2>
+<table>
+<tr><th>Agent</th><th>Results</th><th>Pass</th><th>Confidence</th>{{range .Stages}}<th>{{.Name}}</th>{{end}}</tr>
+{{range .Agents}}<tr>
+<td>{{.Name}}</td>
+<td class="num">{{.Stats.Total}}</td>
+<td class="num">{{percent .Stats.PassCount .Stats.Total}}%</td>
+<td class="num">{{printf "%.3f" .Stats.AvgConfidence}}</td>
+{{range .Scores}}<td class="num">{{.}}</td>{{end}}
+</tr>
+{{end}}</table>
+{{end}}
+<h2>Worst-scoring results</h2>
+<table>
+<tr><th>ID</th><th>Verdict</th><th>Confidence</th><th>Lowest stages</th></tr>
+{{range .Worst}}<tr>
+<td>{{.ID}}</td>
+<td>{{.Verdict}}{{if .VetoedBy}} <span class="muted">(vetoed by {{.VetoedBy}})</span>{{end}}</td>
+<td class="num">{{printf "%.3f" .Confidence}}</td>
+<td>{{range .Stages}}<div class="stage"><strong>{{.Name}}</strong> <span class="num">{{if .Failed}}{{.Status}}{{else}}{{printf "%.2f" .Score}}{{end}}</span> {{.Reason}}</div>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,100 @@
+package batch
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestHTMLWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.Nop()
+	writer := NewHTMLWriter(&buf, &logger)
+
+	results := append(flatResults(), models.EvaluationResult{
+		ID:         "<script>alert(1)</script>",
+		Verdict:    models.VerdictReview,
+		Confidence: 0.5,
+		Stages:     []models.StageResult{{Name: "relevance-judge", Score: 0.55, Status: models.StageStatusOK, Reason: "partly relevant"}},
+	})
+	for _, result := range results {
+		if err := writer.Write(result); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	html := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<h3>relevance-judge</h3>",
+		"2 scored, mean 0.675, p50 0.55, p90 0.80, min 0.55, 1 errored",
+		"<td>support-bot@1.0</td>",
+		"<h3>faithfulness-judge</h3>",
+		"partly relevant",
+		"throttled",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+
+	if strings.Contains(html, "<script>alert") {
+		t.Error("expected result IDs to be escaped")
+	}
+	if strings.Contains(html, "<link") || strings.Contains(html, "src=") {
+		t.Error("expected a self-contained report")
+	}
+
+	// Worst results come first
+	if strings.Index(html, "<td>2</td>") > strings.Index(html, "<td>1</td>") {
+		t.Error("expected results ordered by confidence")
+	}
+}
+
+func TestHTMLWriter_KeepsWorst(t *testing.T) {
+	logger := zerolog.Nop()
+	writer := NewHTMLWriter(&bytes.Buffer{}, &logger)
+
+	// Confidences 0, 0.05, ..., 0.95 written out of order, then a tie at 0.05
+	for i := range 20 {
+		confidence := float64((i*7)%20) / 20
+		writer.Write(models.EvaluationResult{ID: fmt.Sprint(i), Confidence: confidence})
+	}
+	writer.Write(models.EvaluationResult{ID: "tie", Confidence: 0.05})
+
+	if len(writer.worst) != worstExamples {
+		t.Fatalf("expected %d worst results kept, got %d", worstExamples, len(writer.worst))
+	}
+	var ids []string
+	for _, example := range writer.worst {
+		ids = append(ids, example.ID)
+	}
+	if got := strings.Join(ids, ","); got != "0,3,tie,6,9,12,15,18,1,4" {
+		t.Errorf("got worst %s, want lowest confidence first and ties in write order", got)
+	}
+}
+
+func TestLowestStages(t *testing.T) {
+	stages := []models.StageResult{
+		{Name: "a", Score: 0.9, Status: models.StageStatusOK},
+		{Name: "b", Score: 0.2, Status: models.StageStatusOK},
+		{Name: "c", Status: models.StageStatusSkipped},
+		{Name: "d", Status: models.StageStatusTimeout},
+		{Name: "e", Score: 0.5, Status: models.StageStatusOK},
+	}
+
+	var names []string
+	for _, stage := range lowestStages(stages, 3) {
+		names = append(names, stage.Name)
+	}
+	if strings.Join(names, ",") != "d,b,e" {
+		t.Errorf("got %v, want [d b e]", names)
+	}
+}
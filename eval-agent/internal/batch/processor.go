@@ -27,29 +27,52 @@ func NewProcessor(exec Executor, workers int, logger *zerolog.Logger) *Processor
 	}
 }
 
-// Process takes input records and returns evaluation results via channel
+// Process takes input records and returns evaluation results via channel. Records with
+// a parse error are skipped.
 func (p *Processor) Process(ctx context.Context, records []InputRecord) <-chan models.EvaluationResult {
-	results := make(chan models.EvaluationResult, len(records))
-	jobs := make(chan InputRecord, len(records))
+	input := make(chan InputRecord)
+	go func() {
+		defer close(input)
+		for _, record := range records {
+			select {
+			case input <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make(chan models.EvaluationResult, p.workers)
+	go func() {
+		defer close(results)
+		for record := range p.ProcessStream(ctx, input) {
+			if record.Error == nil {
+				results <- record.Result
+			}
+		}
+	}()
+
+	return results
+}
+
+// ProcessStream evaluates records as they arrive and returns the results as they complete,
+// in completion order. Buffers are bounded by the number of workers, so only the records
+// in flight are held in memory whatever the size of the input. Records with a parse error
+// are passed through with their error. Once ctx is done no new record is started.
+func (p *Processor) ProcessStream(ctx context.Context, records <-chan InputRecord) <-chan RecordResult {
+	results := make(chan RecordResult, p.workers)
 
 	// Start worker pool
 	var wg sync.WaitGroup
 	for i := 0; i < p.workers; i++ {
 		wg.Add(1)
-		go p.worker(ctx, i, jobs, results, &wg)
+		go p.worker(ctx, i, records, results, &wg)
 	}
 
-	// Send jobs
 	p.logger.Info().
 		Int("workers", p.workers).
-		Int("total_records", len(records)).
 		Msg("Starting worker pool")
 
-	for _, record := range records {
-		jobs <- record
-	}
-	close(jobs)
-
 	// Wait and close results channel
 	go func() {
 		wg.Wait()
@@ -60,16 +83,29 @@ func (p *Processor) Process(ctx context.Context, records []InputRecord) <-chan m
 	return results
 }
 
-func (p *Processor) worker(ctx context.Context, workerID int, jobs <-chan InputRecord, results chan<- models.EvaluationResult, wg *sync.WaitGroup) {
+func (p *Processor) worker(ctx context.Context, workerID int, records <-chan InputRecord, results chan<- RecordResult, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	for record := range jobs {
+	for {
+		var record InputRecord
+		select {
+		case <-ctx.Done():
+			return
+		case next, ok := <-records:
+			if !ok {
+				p.logger.Debug().Int("worker", workerID).Msg("Worker finished")
+				return
+			}
+			record = next
+		}
+
 		if record.Error != nil {
 			p.logger.Warn().
 				Int("worker", workerID).
 				Int("line", record.LineNumber).
 				Err(record.Error).
 				Msg("Skipping record with parse error")
+			results <- RecordResult{LineNumber: record.LineNumber, Offset: record.Offset, Error: record.Error}
 			continue
 		}
 
@@ -78,12 +114,19 @@ func (p *Processor) worker(ctx context.Context, workerID int, jobs <-chan InputR
 			Query:     record.Request.Interaction.UserQuery,
 			Context:   record.Request.Interaction.Context,
 			Answer:    record.Request.Interaction.Answer,
+			Agent:     record.Request.Agent,
 			CreatedAt: time.Now(),
+
+			ReferenceAnswer: record.Request.Interaction.ReferenceAnswer,
+			OutputSchema:    record.Request.Interaction.OutputSchema,
 		}
 
+		start := time.Now()
 		result := p.executor.Execute(ctx, evalCtx)
-		results <- result
-	}
+		result.Duration = time.Since(start)
+		result.Agent = &record.Request.Agent
+		result.EventType = record.Request.EventType
 
-	p.logger.Debug().Int("worker", workerID).Msg("Worker finished")
+		results <- RecordResult{LineNumber: record.LineNumber, Offset: record.Offset, Result: result}
+	}
 }
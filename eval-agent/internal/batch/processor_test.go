@@ -78,3 +78,83 @@ func TestProcessor_SkipsErrorRecords(t *testing.T) {
 		t.Errorf("expected executor called 2 times, got %d", executor.called)
 	}
 }
+
+func TestProcessor_ProcessStream(t *testing.T) {
+	logger := zerolog.Nop()
+	executor := &mockExecutor{}
+	processor := NewProcessor(executor, 1, &logger)
+
+	records := make(chan InputRecord)
+	go func() {
+		defer close(records)
+		records <- InputRecord{LineNumber: 1, Offset: 10, Request: models.EvaluationRequest{EventID: "1"}}
+		records <- InputRecord{LineNumber: 3, Offset: 25, Error: fmt.Errorf("parse error")}
+		records <- InputRecord{LineNumber: 4, Offset: 40, Request: models.EvaluationRequest{EventID: "4"}}
+	}()
+
+	var results []RecordResult
+	for result := range processor.ProcessStream(context.Background(), records) {
+		results = append(results, result)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if results[0].LineNumber != 1 || results[0].Result.ID != "1" {
+		t.Errorf("expected line 1 evaluated, got %+v", results[0])
+	}
+	if results[1].LineNumber != 3 || results[1].Error == nil {
+		t.Errorf("expected line 3 passed through with its parse error, got %+v", results[1])
+	}
+	if results[2].LineNumber != 4 || results[2].Offset != 40 || results[2].Result.ID != "4" {
+		t.Errorf("expected line 4 evaluated, got %+v", results[2])
+	}
+	if executor.called != 2 {
+		t.Errorf("expected executor called 2 times, got %d", executor.called)
+	}
+}
+
+func TestProcessor_ProcessStream_Cancelled(t *testing.T) {
+	logger := zerolog.Nop()
+	executor := &mockExecutor{}
+	processor := NewProcessor(executor, 2, &logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The input is never closed, the workers must stop on the cancelled context
+	records := make(chan InputRecord)
+	for range processor.ProcessStream(ctx, records) {
+		t.Error("expected no results after cancellation")
+	}
+
+	if executor.called != 0 {
+		t.Errorf("expected executor not called, got %d", executor.called)
+	}
+}
+
+func TestProcessor_ProcessStream_GroupingFields(t *testing.T) {
+	logger := zerolog.Nop()
+	processor := NewProcessor(&mockExecutor{}, 1, &logger)
+
+	records := make(chan InputRecord, 1)
+	records <- InputRecord{LineNumber: 1, Request: models.EvaluationRequest{
+		EventID:   "1",
+		EventType: models.EventTypeAgentResponse,
+		Agent:     models.Agent{Name: "support-bot", Version: "2.0"},
+	}}
+	close(records)
+
+	record := <-processor.ProcessStream(context.Background(), records)
+	result := record.Result
+
+	if result.Agent == nil || result.Agent.Name != "support-bot" || result.Agent.Version != "2.0" {
+		t.Errorf("expected the agent of the request, got %+v", result.Agent)
+	}
+	if result.EventType != models.EventTypeAgentResponse {
+		t.Errorf("expected event type agent_response, got %q", result.EventType)
+	}
+	if result.Duration <= 0 {
+		t.Errorf("expected the evaluation duration, got %v", result.Duration)
+	}
+}
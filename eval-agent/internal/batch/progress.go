@@ -0,0 +1,87 @@
+package batch
+
+import (
+	"sync"
+	"time"
+)
+
+// Progress tracks the throughput of a batch run. With the input size known the time left
+// is estimated from the share of the input consumed by completed records, which works
+// without counting the records of a huge file up front.
+type Progress struct {
+	mu      sync.Mutex
+	start   time.Time
+	size    int64 // Input size in bytes, 0 when unknown (stdin)
+	done    int
+	errors  int
+	skipped int
+	offset  int64 // Furthest input offset of a completed record
+	base    int64 // Furthest input offset of a skipped record, where this run's work starts
+	now     func() time.Time
+}
+
+// ProgressSnapshot is the state of a run at one point in time. Percent and ETA are only
+// set when the input size is known.
+type ProgressSnapshot struct {
+	Done          int
+	Errors        int
+	Skipped       int
+	Elapsed       time.Duration
+	RecordsPerSec float64
+	Percent       float64
+	ETA           time.Duration
+}
+
+func NewProgress(size int64) *Progress {
+	return &Progress{
+		start: time.Now(),
+		size:  size,
+		now:   time.Now,
+	}
+}
+
+// Record counts a completed record, records with an error count as errors
+func (p *Progress) Record(result RecordResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	if result.Error != nil {
+		p.errors++
+	}
+	p.offset = max(p.offset, result.Offset)
+}
+
+// Skip counts a record completed by an earlier run. Skipped input doesn't count towards the
+// consumed share, so the ETA of a resumed run is not skewed by records it didn't evaluate.
+func (p *Progress) Skip(record InputRecord) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.skipped++
+	p.base = max(p.base, record.Offset)
+}
+
+func (p *Progress) Snapshot() ProgressSnapshot {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := ProgressSnapshot{
+		Done:    p.done,
+		Errors:  p.errors,
+		Skipped: p.skipped,
+		Elapsed: p.now().Sub(p.start),
+	}
+
+	if snapshot.Elapsed > 0 {
+		snapshot.RecordsPerSec = float64(p.done) / snapshot.Elapsed.Seconds()
+	}
+
+	if p.size > p.base && p.offset > p.base {
+		consumed := min(float64(p.offset-p.base)/float64(p.size-p.base), 1.0)
+		snapshot.Percent = min(float64(p.offset)/float64(p.size), 1.0) * 100
+		snapshot.ETA = time.Duration(float64(snapshot.Elapsed) * (1 - consumed) / consumed)
+	}
+
+	return snapshot
+}
@@ -0,0 +1,61 @@
+package batch
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProgress_Snapshot(t *testing.T) {
+	now := time.Now()
+	progress := NewProgress(1000)
+	progress.start = now
+	progress.now = func() time.Time { return now.Add(10 * time.Second) }
+
+	progress.Record(RecordResult{LineNumber: 2, Offset: 250})
+	progress.Record(RecordResult{LineNumber: 1, Offset: 100})
+	progress.Record(RecordResult{LineNumber: 3, Offset: 200, Error: errors.New("parse error")})
+
+	snapshot := progress.Snapshot()
+
+	if snapshot.Done != 3 || snapshot.Errors != 1 {
+		t.Errorf("expected 3 done and 1 error, got %d and %d", snapshot.Done, snapshot.Errors)
+	}
+	if snapshot.RecordsPerSec != 0.3 {
+		t.Errorf("expected 0.3 records/sec, got %f", snapshot.RecordsPerSec)
+	}
+	// A quarter of the input took 10s, the rest takes 30s
+	if snapshot.Percent != 25 || snapshot.ETA != 30*time.Second {
+		t.Errorf("expected 25%% with 30s left, got %f%% with %s", snapshot.Percent, snapshot.ETA)
+	}
+}
+
+func TestProgress_UnknownSize(t *testing.T) {
+	progress := NewProgress(0)
+	progress.Record(RecordResult{LineNumber: 1, Offset: 100})
+
+	snapshot := progress.Snapshot()
+	if snapshot.Percent != 0 || snapshot.ETA != 0 {
+		t.Errorf("expected no estimate without input size, got %f%% and %s", snapshot.Percent, snapshot.ETA)
+	}
+}
+
+func TestProgress_Resumed(t *testing.T) {
+	now := time.Now()
+	progress := NewProgress(1000)
+	progress.start = now
+	progress.now = func() time.Time { return now.Add(10 * time.Second) }
+
+	// The first half was evaluated by an earlier run
+	progress.Skip(InputRecord{LineNumber: 50, Offset: 500})
+	progress.Record(RecordResult{LineNumber: 60, Offset: 600})
+
+	snapshot := progress.Snapshot()
+	if snapshot.Skipped != 1 || snapshot.Done != 1 {
+		t.Errorf("expected 1 skipped and 1 done, got %d and %d", snapshot.Skipped, snapshot.Done)
+	}
+	// A fifth of the remaining input took 10s, the rest takes 40s
+	if snapshot.Percent != 60 || snapshot.ETA != 40*time.Second {
+		t.Errorf("expected 60%% with 40s left, got %f%% with %s", snapshot.Percent, snapshot.ETA)
+	}
+}
@@ -2,11 +2,12 @@ package batch
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"strings"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
@@ -24,42 +25,51 @@ func NewReader(file io.Reader, logger *zerolog.Logger) *Reader {
 	}
 }
 
+// ReadAll streams the records of the input one line at a time, the channel is unbuffered
+// so the reader stays at most one record ahead of its consumer. Lines have no length limit.
 func (r *Reader) ReadAll(ctx context.Context) <-chan InputRecord {
 	ch := make(chan InputRecord)
 
 	go func() {
 		defer close(ch)
 
-		scanner := bufio.NewScanner(r.file)
+		reader := bufio.NewReader(r.file)
 		lineNum := 0
+		var offset int64
 
-		for scanner.Scan() {
-			lineNum++
-
-			select {
-			case <-ctx.Done():
+		for {
+			data, err := reader.ReadBytes('\n')
+			if err != nil && !errors.Is(err, io.EOF) {
+				r.logger.Error().Err(err).Int("line", lineNum+1).Msg("Read Error")
 				return
-			default:
 			}
-
-			line := strings.TrimSpace(scanner.Text())
-			if line == "" {
-				continue
+			if len(data) == 0 && err != nil {
+				return
 			}
 
-			var req models.EvaluationRequest
-			if err := json.Unmarshal([]byte(line), &req); err != nil {
-				// Send error to the channel
-				ch <- InputRecord{LineNumber: lineNum, Error: fmt.Errorf("parse error: %w", err)}
-				continue
-			}
+			lineNum++
+			offset += int64(len(data))
 
-			// Send success record
-			ch <- InputRecord{LineNumber: lineNum, Request: req}
-		}
+			line := bytes.TrimSpace(data)
+			if len(line) > 0 {
+				record := InputRecord{LineNumber: lineNum, Offset: offset}
+				var req models.EvaluationRequest
+				if parseErr := json.Unmarshal(line, &req); parseErr != nil {
+					record.Error = fmt.Errorf("parse error: %w", parseErr)
+				} else {
+					record.Request = req
+				}
+
+				select {
+				case ch <- record:
+				case <-ctx.Done():
+					return
+				}
+			}
 
-		if err := scanner.Err(); err != nil {
-			r.logger.Error().Err(err).Msg("Scanner Error")
+			if err != nil {
+				return
+			}
 		}
 	}()
 
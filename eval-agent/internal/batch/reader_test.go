@@ -104,3 +104,28 @@ func TestReader_LineNumbers(t *testing.T) {
 		t.Errorf("third record should be line 4, got %d", records[2].LineNumber)
 	}
 }
+
+func TestReader_LongLinesAndOffsets(t *testing.T) {
+	// Longer than the 64KB line limit of bufio.Scanner
+	answer := strings.Repeat("a", 100_000)
+	first := `{"event_id":"1","interaction":{"user_query":"q","answer":"` + answer + `"}}`
+	second := `{"event_id":"2","interaction":{"user_query":"q","answer":"short"}}`
+	input := first + "\n" + second
+
+	reader := NewReader(strings.NewReader(input), newTestLogger())
+
+	var records []InputRecord
+	for record := range reader.ReadAll(context.Background()) {
+		records = append(records, record)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].Error != nil || len(records[0].Request.Interaction.Answer) != len(answer) {
+		t.Errorf("expected long line parsed, got error %v", records[0].Error)
+	}
+	if records[0].Offset != int64(len(first)+1) || records[1].Offset != int64(len(input)) {
+		t.Errorf("unexpected offsets %d and %d", records[0].Offset, records[1].Offset)
+	}
+}
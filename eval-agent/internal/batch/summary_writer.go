@@ -3,36 +3,90 @@ package batch
 import (
 	"encoding/json"
 	"io"
+	"math"
+	"slices"
+	"time"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
 
+// unknownGroup keys the results of a batch input without an agent or event type
+const unknownGroup = "unknown"
+
 type SummaryStats struct {
-	Total         int     `json:"total"`
-	PassCount     int     `json:"pass_count"`
-	FailCount     int     `json:"fail_count"`
-	ReviewCount   int     `json:"review_count"`
-	AvgConfidence float64 `json:"avg_confidence"`
+	GroupStats
+	Agents     map[string]GroupStats `json:"agents,omitempty"`      // Keyed by agent name@version
+	EventTypes map[string]GroupStats `json:"event_types,omitempty"` // Keyed by event type
+}
+
+// GroupStats are the statistics of the results of a run, or of a group of them
+type GroupStats struct {
+	Total         int                   `json:"total"`
+	PassCount     int                   `json:"pass_count"`
+	FailCount     int                   `json:"fail_count"`
+	ReviewCount   int                   `json:"review_count"`
+	AvgConfidence float64               `json:"avg_confidence"`
+	Usage         models.TokenUsage     `json:"usage"`             // Tokens and estimated cost of the results
+	Latency       *LatencyStats         `json:"latency,omitempty"` // Wall time of the evaluations, nil when not measured
+	Stages        map[string]StageStats `json:"stages,omitempty"`  // Keyed by stage name
+}
+
+// StageStats summarize one stage over the results. The score statistics only cover the
+// results the stage scored, errors and timeouts are counted apart so a flaky judge shows
+// up as failures instead of low scores.
+type StageStats struct {
+	Count        int           `json:"count"` // Results that ran the stage
+	Scored       int           `json:"scored"`
+	Errors       int           `json:"errors"`
+	Timeouts     int           `json:"timeouts"`
+	Skipped      int           `json:"skipped"`
+	Mean         float64       `json:"mean"`
+	P50          float64       `json:"p50"`
+	P90          float64       `json:"p90"`
+	Min          float64       `json:"min"`
+	MeanDuration time.Duration `json:"mean_duration_ns"`
+}
+
+// LatencyStats are percentiles of the wall time of evaluations
+type LatencyStats struct {
+	Mean time.Duration `json:"mean_ns"`
+	P50  time.Duration `json:"p50_ns"`
+	P90  time.Duration `json:"p90_ns"`
+	P99  time.Duration `json:"p99_ns"`
+	Max  time.Duration `json:"max_ns"`
 }
 
+// SummaryWriter accumulates the statistics as results are written and keeps no result,
+// so its memory does not grow with the run beyond the scores and durations percentiles
+// are computed from
 type SummaryWriter struct {
-	output  io.Writer
-	logger  *zerolog.Logger
-	results []models.EvaluationResult
+	output     io.Writer
+	logger     *zerolog.Logger
+	all        *groupAccumulator
+	agents     map[string]*groupAccumulator
+	eventTypes map[string]*groupAccumulator
 }
 
 func NewSummaryWriter(output io.Writer, logger *zerolog.Logger) *SummaryWriter {
 	return &SummaryWriter{
-		output:  output,
-		logger:  logger,
-		results: []models.EvaluationResult{},
+		output:     output,
+		logger:     logger,
+		all:        newGroupAccumulator(),
+		agents:     make(map[string]*groupAccumulator),
+		eventTypes: make(map[string]*groupAccumulator),
 	}
 }
 
 func (w *SummaryWriter) Write(result models.EvaluationResult) error {
-	// Collect results
-	w.results = append(w.results, result)
+	w.all.add(result)
+
+	eventType := string(result.EventType)
+	if eventType == "" {
+		eventType = unknownGroup
+	}
+	group(w.agents, agentKey(result.Agent)).add(result)
+	group(w.eventTypes, eventType).add(result)
 	return nil
 }
 
@@ -50,27 +104,184 @@ func (w *SummaryWriter) Close() error {
 
 func (w *SummaryWriter) computeStats() SummaryStats {
 	stats := SummaryStats{
-		Total: len(w.results),
+		GroupStats: w.all.stats(),
+	}
+
+	if w.all.total > 0 {
+		stats.Agents = make(map[string]GroupStats, len(w.agents))
+		for key, acc := range w.agents {
+			stats.Agents[key] = acc.stats()
+		}
+		stats.EventTypes = make(map[string]GroupStats, len(w.eventTypes))
+		for key, acc := range w.eventTypes {
+			stats.EventTypes[key] = acc.stats()
+		}
+	}
+
+	return stats
+}
+
+// group returns the accumulator of key, creating it on first use
+func group(groups map[string]*groupAccumulator, key string) *groupAccumulator {
+	acc, ok := groups[key]
+	if !ok {
+		acc = newGroupAccumulator()
+		groups[key] = acc
+	}
+	return acc
+}
+
+// agentKey names the group of an agent, name@version
+func agentKey(agent *models.Agent) string {
+	if agent == nil || agent.Name == "" {
+		return unknownGroup
+	}
+	if agent.Version == "" {
+		return agent.Name
+	}
+	return agent.Name + "@" + agent.Version
+}
+
+type groupAccumulator struct {
+	total           int
+	pass            int
+	fail            int
+	review          int
+	totalConfidence float64
+	usage           models.TokenUsage
+	durations       []time.Duration
+	stages          map[string]*stageAccumulator
+}
+
+func newGroupAccumulator() *groupAccumulator {
+	return &groupAccumulator{stages: make(map[string]*stageAccumulator)}
+}
+
+func (a *groupAccumulator) add(result models.EvaluationResult) {
+	a.total++
+	a.totalConfidence += result.Confidence
+	if result.Usage != nil {
+		a.usage.Add(*result.Usage)
+	}
+	if result.Duration > 0 {
+		a.durations = append(a.durations, result.Duration)
+	}
+
+	switch result.Verdict {
+	case models.VerdictPass:
+		a.pass++
+	case models.VerdictFail:
+		a.fail++
+	case models.VerdictReview:
+		a.review++
 	}
 
-	var totalConfidence float64
+	for _, stage := range result.Stages {
+		acc, ok := a.stages[stage.Name]
+		if !ok {
+			acc = &stageAccumulator{}
+			a.stages[stage.Name] = acc
+		}
+		acc.add(stage)
+	}
+}
 
-	for _, result := range w.results {
-		totalConfidence += result.Confidence
+func (a *groupAccumulator) stats() GroupStats {
+	stats := GroupStats{
+		Total:       a.total,
+		PassCount:   a.pass,
+		FailCount:   a.fail,
+		ReviewCount: a.review,
+		Usage:       a.usage,
+		Latency:     latencyStats(a.durations),
+	}
+	if a.total > 0 {
+		stats.AvgConfidence = a.totalConfidence / float64(a.total)
+	}
 
-		switch result.Verdict {
-		case models.VerdictPass:
-			stats.PassCount++
-		case models.VerdictFail:
-			stats.FailCount++
-		case models.VerdictReview:
-			stats.ReviewCount++
+	if len(a.stages) > 0 {
+		stats.Stages = make(map[string]StageStats, len(a.stages))
+		for name, acc := range a.stages {
+			stats.Stages[name] = acc.stats()
 		}
 	}
 
-	if stats.Total > 0 {
-		stats.AvgConfidence = totalConfidence / float64(stats.Total)
+	return stats
+}
+
+type stageAccumulator struct {
+	count    int
+	errors   int
+	timeouts int
+	skipped  int
+	scores   []float64
+	duration time.Duration
+}
+
+func (a *stageAccumulator) add(stage models.StageResult) {
+	a.count++
+	a.duration += stage.Duration
+
+	switch stage.Status {
+	case models.StageStatusError:
+		a.errors++
+	case models.StageStatusTimeout:
+		a.timeouts++
+	case models.StageStatusSkipped:
+		a.skipped++
+	default:
+		a.scores = append(a.scores, stage.Score)
 	}
+}
 
+func (a *stageAccumulator) stats() StageStats {
+	stats := StageStats{
+		Count:        a.count,
+		Scored:       len(a.scores),
+		Errors:       a.errors,
+		Timeouts:     a.timeouts,
+		Skipped:      a.skipped,
+		MeanDuration: a.duration / time.Duration(a.count),
+	}
+	if len(a.scores) == 0 {
+		return stats
+	}
+
+	slices.Sort(a.scores)
+	var sum float64
+	for _, score := range a.scores {
+		sum += score
+	}
+
+	stats.Mean = sum / float64(len(a.scores))
+	stats.P50 = percentile(a.scores, 0.5)
+	stats.P90 = percentile(a.scores, 0.9)
+	stats.Min = a.scores[0]
 	return stats
 }
+
+func latencyStats(durations []time.Duration) *LatencyStats {
+	if len(durations) == 0 {
+		return nil
+	}
+
+	slices.Sort(durations)
+	var sum time.Duration
+	for _, duration := range durations {
+		sum += duration
+	}
+
+	return &LatencyStats{
+		Mean: sum / time.Duration(len(durations)),
+		P50:  percentile(durations, 0.5),
+		P90:  percentile(durations, 0.9),
+		P99:  percentile(durations, 0.99),
+		Max:  durations[len(durations)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p (0-1) of sorted, non-empty values
+func percentile[T float64 | time.Duration](sorted []T, p float64) T {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
@@ -15,8 +16,8 @@ func TestSummaryWriter(t *testing.T) {
 	writer := NewSummaryWriter(&buf, &logger)
 
 	// Write mix of pass, fail, review
-	writer.Write(models.EvaluationResult{ID: "1", Verdict: models.VerdictPass, Confidence: 0.9})
-	writer.Write(models.EvaluationResult{ID: "2", Verdict: models.VerdictFail, Confidence: 0.3})
+	writer.Write(models.EvaluationResult{ID: "1", Verdict: models.VerdictPass, Confidence: 0.9, Usage: &models.TokenUsage{InputTokens: 100, OutputTokens: 20, Cost: 0.5}})
+	writer.Write(models.EvaluationResult{ID: "2", Verdict: models.VerdictFail, Confidence: 0.3, Usage: &models.TokenUsage{InputTokens: 50, OutputTokens: 10, Cost: 0.25}})
 	writer.Write(models.EvaluationResult{ID: "3", Verdict: models.VerdictReview, Confidence: 0.6})
 
 	err := writer.Close()
@@ -45,4 +46,136 @@ func TestSummaryWriter(t *testing.T) {
 	if stats.AvgConfidence != wantAvg {
 		t.Errorf("AvgConfidence: got %v, want %v", stats.AvgConfidence, wantAvg)
 	}
+	if stats.Usage.InputTokens != 150 || stats.Usage.OutputTokens != 30 || stats.Usage.Cost != 0.75 {
+		t.Errorf("Usage: got %+v, want 150/30/0.75", stats.Usage)
+	}
+}
+
+func TestSummaryWriter_StageStats(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.Nop()
+	writer := NewSummaryWriter(&buf, &logger)
+
+	scores := []float64{0.9, 0.2, 0.6, 0.8, 0.4}
+	for i, score := range scores {
+		writer.Write(models.EvaluationResult{
+			ID:       string(rune('a' + i)),
+			Verdict:  models.VerdictPass,
+			Duration: time.Duration(i+1) * time.Second,
+			Stages: []models.StageResult{
+				{Name: "relevance-judge", Score: score, Status: models.StageStatusOK, Duration: 100 * time.Millisecond},
+			},
+		})
+	}
+	writer.Write(models.EvaluationResult{ID: "f", Stages: []models.StageResult{
+		{Name: "relevance-judge", Status: models.StageStatusError, Duration: 400 * time.Millisecond},
+	}})
+	writer.Write(models.EvaluationResult{ID: "g", Stages: []models.StageResult{
+		{Name: "relevance-judge", Status: models.StageStatusTimeout, Duration: 1200 * time.Millisecond},
+	}})
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var stats SummaryStats
+	if err := json.Unmarshal(buf.Bytes(), &stats); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+
+	stage := stats.Stages["relevance-judge"]
+	want := StageStats{
+		Count:        7,
+		Scored:       5,
+		Errors:       1,
+		Timeouts:     1,
+		Mean:         0.58,
+		P50:          0.6,
+		P90:          0.9,
+		Min:          0.2,
+		MeanDuration: 300 * time.Millisecond,
+	}
+	if stage.Mean-want.Mean > 1e-9 || want.Mean-stage.Mean > 1e-9 {
+		t.Errorf("Mean: got %v, want %v", stage.Mean, want.Mean)
+	}
+	stage.Mean = want.Mean
+	if stage != want {
+		t.Errorf("Stage stats: got %+v, want %+v", stage, want)
+	}
+
+	// Results without a measured duration are left out of the latency
+	latency := stats.Latency
+	if latency == nil {
+		t.Fatal("expected latency stats")
+	}
+	if latency.Mean != 3*time.Second || latency.P50 != 3*time.Second || latency.P90 != 5*time.Second || latency.Max != 5*time.Second {
+		t.Errorf("Latency: got %+v", latency)
+	}
+}
+
+func TestSummaryWriter_Groups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.Nop()
+	writer := NewSummaryWriter(&buf, &logger)
+
+	v1 := &models.Agent{Name: "support-bot", Version: "1.0"}
+	v2 := &models.Agent{Name: "support-bot", Version: "2.0"}
+	judge := func(score float64) []models.StageResult {
+		return []models.StageResult{{Name: "faithfulness-judge", Score: score, Status: models.StageStatusOK}}
+	}
+
+	writer.Write(models.EvaluationResult{ID: "1", Agent: v1, EventType: models.EventTypeAgentResponse, Verdict: models.VerdictPass, Stages: judge(0.9)})
+	writer.Write(models.EvaluationResult{ID: "2", Agent: v1, EventType: models.EventTypeAgentResponse, Verdict: models.VerdictPass, Stages: judge(0.7)})
+	writer.Write(models.EvaluationResult{ID: "3", Agent: v2, EventType: models.EventTypeAgentError, Verdict: models.VerdictFail, Stages: judge(0.1)})
+	writer.Write(models.EvaluationResult{ID: "4", Verdict: models.VerdictReview})
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var stats SummaryStats
+	if err := json.Unmarshal(buf.Bytes(), &stats); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+
+	if len(stats.Agents) != 3 {
+		t.Fatalf("Agents: got %d groups, want 3: %v", len(stats.Agents), stats.Agents)
+	}
+	if group := stats.Agents["support-bot@1.0"]; group.Total != 2 || group.PassCount != 2 || group.Stages["faithfulness-judge"].Mean != 0.8 {
+		t.Errorf("support-bot@1.0: got %+v", group)
+	}
+	if group := stats.Agents["support-bot@2.0"]; group.Total != 1 || group.FailCount != 1 || group.Stages["faithfulness-judge"].Min != 0.1 {
+		t.Errorf("support-bot@2.0: got %+v", group)
+	}
+	if group := stats.Agents["unknown"]; group.Total != 1 || group.ReviewCount != 1 {
+		t.Errorf("unknown agent: got %+v", group)
+	}
+
+	if stats.EventTypes["agent_response"].Total != 2 || stats.EventTypes["agent_error"].Total != 1 || stats.EventTypes["unknown"].Total != 1 {
+		t.Errorf("EventTypes: got %+v", stats.EventTypes)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0.0, 1},
+		{0.5, 5},
+		{0.9, 9},
+		{0.99, 10},
+		{1.0, 10},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile([]float64{0.4}, 0.9); got != 0.4 {
+		t.Errorf("percentile of one value = %v, want 0.4", got)
+	}
 }
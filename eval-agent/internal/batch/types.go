@@ -4,6 +4,16 @@ import "github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 
 type InputRecord struct {
 	LineNumber int
+	Offset     int64 // Byte offset of the end of the line in the input
 	Request    models.EvaluationRequest
 	Error      error
 }
+
+// RecordResult is the evaluation of one input record. A record that failed to parse
+// carries its parse error instead of a result.
+type RecordResult struct {
+	LineNumber int
+	Offset     int64
+	Result     models.EvaluationResult
+	Error      error
+}
@@ -13,10 +13,19 @@ type Writer interface {
 	Close() error
 }
 
+// Formats lists the supported output formats
+var Formats = []string{"jsonl", "json", "csv", "html", "summary"}
+
 func NewWriter(output io.Writer, format string, logger *zerolog.Logger) (Writer, error) {
 	switch format {
 	case "jsonl":
 		return NewJSONLWriter(output, logger), nil
+	case "json":
+		return NewFlatJSONWriter(output, logger), nil
+	case "csv":
+		return NewCSVWriter(output, logger), nil
+	case "html":
+		return NewHTMLWriter(output, logger), nil
 	case "summary":
 		return NewSummaryWriter(output, logger), nil
 	default:
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -20,8 +19,10 @@ type ClaudeRequest struct {
 }
 
 type ClaudeResponse struct {
-	Content    string
-	StopReason string
+	Content      string
+	StopReason   string
+	InputTokens  int
+	OutputTokens int
 }
 
 type claudeMessageRequest struct {
@@ -42,11 +43,37 @@ type claudeMessageResponse struct {
 		Text string `json:"text"`
 	} `json:"content"`
 	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
 }
 
 var anthropicVersion = "bedrock-2023-05-31"
 
+// InvokeModel sends one request, after the pacer admits it. Every attempt of
+// InvokeModelWithRetry goes through here, so each one is paced.
 func (c *Client) InvokeModel(ctx context.Context, request ClaudeRequest) (*ClaudeResponse, error) {
+	if c.Pacer == nil {
+		return c.invokeModel(ctx, request)
+	}
+
+	release, err := c.Pacer.Acquire(ctx, request.Prompt, request.MaxTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.invokeModel(ctx, request)
+	var used int
+	if response != nil {
+		used = response.InputTokens + response.OutputTokens
+	}
+	release(used, err)
+
+	return response, err
+}
+
+func (c *Client) invokeModel(ctx context.Context, request ClaudeRequest) (*ClaudeResponse, error) {
 	payload := claudeMessageRequest{
 		AnthropicVersion: anthropicVersion,
 		MaxTokens:        request.MaxTokens,
@@ -87,8 +114,10 @@ func (c *Client) InvokeModel(ctx context.Context, request ClaudeRequest) (*Claud
 	}
 
 	return &ClaudeResponse{
-		Content:    content,
-		StopReason: response.StopReason,
+		Content:      content,
+		StopReason:   response.StopReason,
+		InputTokens:  response.Usage.InputTokens,
+		OutputTokens: response.Usage.OutputTokens,
 	}, nil
 }
 
@@ -104,7 +133,7 @@ func (c *Client) InvokeModelWithRetry(ctx context.Context, request ClaudeRequest
 		lastErr = err
 
 		// Check if error is retryable
-		if !isRetryableError(err) {
+		if !IsRetryable(err) {
 			return nil, fmt.Errorf("non-retryable error: %w", err)
 		}
 
@@ -120,41 +149,9 @@ func (c *Client) InvokeModelWithRetry(ctx context.Context, request ClaudeRequest
 	return nil, fmt.Errorf("max retries %d exceeded: %w", c.MaxRetries, lastErr)
 }
 
-func isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := err.Error()
-
-	// 1. Throttling errors
-	if strings.Contains(errStr, "ThrottlingException") ||
-		strings.Contains(errStr, "TooManyRequestsException") ||
-		strings.Contains(errStr, "Rate exceeded") {
-		return true
-	}
-
-	// 2. Service errors (5xx)
-	if strings.Contains(errStr, "InternalServerException") ||
-		strings.Contains(errStr, "ServiceUnavailableException") ||
-		strings.Contains(errStr, "500") ||
-		strings.Contains(errStr, "503") {
-		return true
-	}
-
-	// 3. Network errors
-	if strings.Contains(errStr, "connection reset") ||
-		strings.Contains(errStr, "EOF") ||
-		strings.Contains(errStr, "timeout") {
-		return true
-	}
-
-	// Non-retryable errors (4xx client errors, validation errors, etc.)
-	return false
-}
-
+// calculateBackoff doubles the delay with every attempt, up to maxDelay, with ±20% jitter
 func calculateBackoff(attempt int, initialDelay, maxDelay time.Duration) time.Duration {
-	backoff := float64(initialDelay) + math.Pow(2, float64(attempt))
+	backoff := float64(initialDelay) * math.Pow(2, float64(attempt))
 
 	if backoff > float64(maxDelay) {
 		backoff = float64(maxDelay)
@@ -0,0 +1,90 @@
+package bedrock
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingPacer admits every call and counts them
+type countingPacer struct {
+	acquired atomic.Int32
+	released atomic.Int32
+}
+
+func (p *countingPacer) Acquire(context.Context, string, int) (func(int, error), error) {
+	p.acquired.Add(1)
+	return func(int, error) { p.released.Add(1) }, nil
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	t.Setenv("AWS_ENDPOINT_URL", server.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	client, err := NewClient(context.Background(), "us-east-1", "test-model")
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client.InitialDelay = time.Millisecond
+	client.MaxDelay = time.Millisecond
+	return client
+}
+
+func TestInvokeModelWithRetry_OneRetryLayer(t *testing.T) {
+	tests := []struct {
+		name      string
+		throttled int32 // Requests answered with a ThrottlingException before a success
+		wantErr   bool
+		wantCalls int32
+	}{
+		{name: "success", throttled: 0, wantCalls: 1},
+		{name: "throttled once", throttled: 1, wantCalls: 2},
+		{name: "always throttled", throttled: 100, wantErr: true, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) <= tt.throttled {
+					w.Header().Set("X-Amzn-ErrorType", "ThrottlingException")
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(`{"message":"Too many requests"}`))
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"content":[{"type":"text","text":"ok"}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":2}}`))
+			})
+			pacer := &countingPacer{}
+			client.Pacer = pacer
+
+			response, err := client.InvokeModelWithRetry(context.Background(), ClaudeRequest{Prompt: "test", MaxTokens: 16})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if err == nil && response.Content != "ok" {
+				t.Errorf("got content %q, want ok", response.Content)
+			}
+
+			// Every HTTP attempt is one paced attempt, the SDK doesn't retry on its own
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("got %d HTTP attempts, want %d", got, tt.wantCalls)
+			}
+			if pacer.acquired.Load() != tt.wantCalls || pacer.released.Load() != tt.wantCalls {
+				t.Errorf("got %d acquired and %d released, want %d paced attempts", pacer.acquired.Load(), pacer.released.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
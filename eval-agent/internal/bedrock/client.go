@@ -15,10 +15,20 @@ type Client struct {
 	MaxRetries   int
 	InitialDelay time.Duration
 	MaxDelay     time.Duration
+	Pacer        Pacer // Admits every call, nil when calls are not paced
+}
+
+// Pacer admits calls to the model, e.g. a rate limiter shared by all of its clients.
+// Acquire blocks until the call may be sent; release reports the call's outcome and the
+// tokens it used.
+type Pacer interface {
+	Acquire(ctx context.Context, prompt string, maxTokens int) (release func(usedTokens int, err error), err error)
 }
 
 func NewClient(ctx context.Context, region string, modelID string) (*Client, error) {
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	// The SDK retryer is turned off, retries are left to InvokeModelWithRetry so that
+	// every attempt goes through the Pacer
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region), config.WithRetryMaxAttempts(1))
 	if err != nil {
 		return nil, fmt.Errorf("Unable to load AWS config: %w", err)
 	}
@@ -0,0 +1,107 @@
+package bedrock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// ErrorClass tells how a failed call should be handled
+type ErrorClass int
+
+const (
+	ErrorNone      ErrorClass = iota // The call succeeded
+	ErrorPermanent                   // Invalid or forbidden request, sending it again won't help
+	ErrorTransient                   // Server or network failure, retry with backoff
+	ErrorThrottled                   // Rate or quota exceeded, retry with backoff and send less
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorNone:
+		return "none"
+	case ErrorTransient:
+		return "transient"
+	case ErrorThrottled:
+		return "throttled"
+	default:
+		return "permanent"
+	}
+}
+
+// throttlingCodes are the error codes AWS services use for rate limits
+var throttlingCodes = map[string]bool{
+	"ThrottlingException":      true,
+	"TooManyRequestsException": true,
+	"ThrottledException":       true,
+	"RequestLimitExceeded":     true,
+}
+
+// ClassifyError classifies an error of the Bedrock runtime API by its type: the modeled
+// Bedrock exceptions first, then the code and fault of other API errors, then the HTTP
+// status, then network errors. A cancelled or expired context is permanent, the caller
+// gave up.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorNone
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorPermanent
+	}
+
+	var (
+		throttling  *types.ThrottlingException
+		quota       *types.ServiceQuotaExceededException
+		internal    *types.InternalServerException
+		unavailable *types.ServiceUnavailableException
+		timeout     *types.ModelTimeoutException
+		notReady    *types.ModelNotReadyException
+	)
+	switch {
+	case errors.As(err, &throttling), errors.As(err, &quota):
+		return ErrorThrottled
+	case errors.As(err, &internal), errors.As(err, &unavailable), errors.As(err, &timeout), errors.As(err, &notReady):
+		return ErrorTransient
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if throttlingCodes[apiErr.ErrorCode()] {
+			return ErrorThrottled
+		}
+		if apiErr.ErrorFault() == smithy.FaultServer {
+			return ErrorTransient
+		}
+	}
+
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch status := respErr.HTTPStatusCode(); {
+		case status == http.StatusTooManyRequests:
+			return ErrorThrottled
+		case status >= 500:
+			return ErrorTransient
+		default:
+			return ErrorPermanent
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return ErrorTransient
+	}
+
+	return ErrorPermanent
+}
+
+// IsRetryable reports whether the call may succeed if sent again
+func IsRetryable(err error) bool {
+	class := ClassifyError(err)
+	return class == ErrorTransient || class == ErrorThrottled
+}
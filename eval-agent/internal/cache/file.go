@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// FileCache stores judge results as JSON files, one per key, under a directory
+type FileCache struct {
+	dir string
+}
+
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %s: %w", dir, err)
+	}
+
+	return &FileCache{dir: dir}, nil
+}
+
+func (c *FileCache) Get(ctx context.Context, key string) (models.StageResult, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return models.StageResult{}, false, nil
+	}
+	if err != nil {
+		return models.StageResult{}, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var result models.StageResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return models.StageResult{}, false, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+
+	return result, true, nil
+}
+
+// Set writes the entry to a temporary file first so concurrent readers never see a partial entry
+func (c *FileCache) Set(ctx context.Context, key string, result models.StageResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return nil
+}
+
+// path shards entries by the first two characters of the key to keep directories small
+func (c *FileCache) path(key string) string {
+	if len(key) < 2 {
+		return filepath.Join(c.dir, key+".json")
+	}
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
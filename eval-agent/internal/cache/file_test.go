@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+func TestFileCache_SetAndGet(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCache failed: %v", err)
+	}
+
+	ctx := context.Background()
+	key := "ab12cd"
+
+	if _, found, err := cache.Get(ctx, key); found || err != nil {
+		t.Fatalf("Expected a miss on an empty cache, got found=%v err=%v", found, err)
+	}
+
+	want := models.StageResult{Name: "relevance-judge", Score: 0.8, Reason: "relevant"}
+	if err := cache.Set(ctx, key, want); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	got, found, err := cache.Get(ctx, key)
+	if err != nil || !found {
+		t.Fatalf("Expected a hit, got found=%v err=%v", found, err)
+	}
+	if got.Score != want.Score || got.Reason != want.Reason {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
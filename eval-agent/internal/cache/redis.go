@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache stores judge results as JSON strings in Redis
+type RedisCache struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisCache creates a cache storing keys under the given prefix. A ttl of 0 keeps entries forever.
+func NewRedisCache(client *redis.Client, prefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+	}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (models.StageResult, bool, error) {
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return models.StageResult{}, false, nil
+	}
+	if err != nil {
+		return models.StageResult{}, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var result models.StageResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return models.StageResult{}, false, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+
+	return result, true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, result models.StageResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := c.client.Set(ctx, c.prefix+key, data, c.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return nil
+}
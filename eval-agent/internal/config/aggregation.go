@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Aggregation strategies supported by the aggregator
+const (
+	StrategyWeightedMean  = "weighted_mean"
+	StrategyGeometricMean = "geometric_mean"
+	StrategyMin           = "min"
+)
+
+// How stages that errored, timed out or were skipped are aggregated
+const (
+	FailedStagesExclude = "exclude" // Left out, the remaining stages are re-weighted
+	FailedStagesZero    = "zero"    // Counted with a score of 0.0
+)
+
+// DefaultMaxFailedJudges is the share of judges that may error or time out before
+// the verdict is downgraded to review
+const DefaultMaxFailedJudges = 0.5
+
+// PoliciesConfig is the root configuration structure for aggregation policies
+type PoliciesConfig struct {
+	Aggregation Aggregation `yaml:"aggregation"`
+}
+
+// Aggregation contains the named policies and which agent uses which policy
+type Aggregation struct {
+	DefaultPolicy string              `yaml:"default_policy"`
+	Policies      []AggregationPolicy `yaml:"policies"`
+	Agents        map[string]string   `yaml:"agents,omitempty"` // agent name -> policy name
+}
+
+// AggregationPolicy defines how stage scores are combined into a verdict
+type AggregationPolicy struct {
+	Name             string             `yaml:"name"`
+	Description      string             `yaml:"description"`
+	Strategy         string             `yaml:"strategy"`
+	PrecheckWeight   float64            `yaml:"precheck_weight"`
+	JudgeWeight      float64            `yaml:"judge_weight"`
+	StageWeights     map[string]float64 `yaml:"stage_weights,omitempty"` // Per judge/checker weight overrides
+	Vetoes           []VetoRule         `yaml:"vetoes,omitempty"`
+	Verdicts         VerdictBands       `yaml:"verdict_bands"`
+	FailedStages     string             `yaml:"failed_stages,omitempty"`      // exclude (default) or zero
+	MaxFailedJudges  *float64           `yaml:"max_failed_judges,omitempty"`  // Share of judges that may fail before the verdict is review
+	MaxJudgeVariance float64            `yaml:"max_judge_variance,omitempty"` // Score variance of a sampled judge above which a pass is review, 0 disables
+}
+
+// VetoRule forces a verdict when a single stage scores below a threshold
+type VetoRule struct {
+	Stage   string  `yaml:"stage"`
+	Below   float64 `yaml:"below"`
+	Verdict string  `yaml:"verdict,omitempty"` // fail (default) or review
+}
+
+// VerdictBands defines the confidence cutoffs for each verdict
+type VerdictBands struct {
+	Pass   float64 `yaml:"pass"`   // confidence above this is a pass
+	Review float64 `yaml:"review"` // confidence above this is a review, otherwise fail
+}
+
+// LoadAggregationConfig loads and validates the aggregation policies from YAML
+func LoadAggregationConfig() (*PoliciesConfig, error) {
+	path := configPath("AGGREGATION_CONFIG_PATH", "configs/aggregation.yaml")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var cfg PoliciesConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	applyAggregationDefaults(&cfg)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+func applyAggregationDefaults(cfg *PoliciesConfig) {
+	if cfg.Aggregation.DefaultPolicy == "" && len(cfg.Aggregation.Policies) == 1 {
+		cfg.Aggregation.DefaultPolicy = cfg.Aggregation.Policies[0].Name
+	}
+
+	for i := range cfg.Aggregation.Policies {
+		policy := &cfg.Aggregation.Policies[i]
+
+		if policy.Strategy == "" {
+			policy.Strategy = StrategyWeightedMean
+		}
+		if policy.PrecheckWeight == 0.0 && policy.JudgeWeight == 0.0 {
+			policy.PrecheckWeight = 0.3
+			policy.JudgeWeight = 0.7
+		}
+		if policy.FailedStages == "" {
+			policy.FailedStages = FailedStagesExclude
+		}
+		if policy.MaxFailedJudges == nil {
+			maxFailedJudges := DefaultMaxFailedJudges
+			policy.MaxFailedJudges = &maxFailedJudges
+		}
+		if policy.Verdicts.Pass == 0.0 && policy.Verdicts.Review == 0.0 {
+			policy.Verdicts.Pass = 0.8
+			policy.Verdicts.Review = 0.5
+		}
+		for j := range policy.Vetoes {
+			if policy.Vetoes[j].Verdict == "" {
+				policy.Vetoes[j].Verdict = "fail"
+			}
+		}
+	}
+}
+
+func (cfg *PoliciesConfig) Validate() error {
+	if len(cfg.Aggregation.Policies) == 0 {
+		return fmt.Errorf("no aggregation policies configured")
+	}
+
+	seen := make(map[string]bool)
+
+	for i, policy := range cfg.Aggregation.Policies {
+		if policy.Name == "" {
+			return fmt.Errorf("policy at index %d is missing name", i)
+		}
+
+		if seen[policy.Name] {
+			return fmt.Errorf("duplicate policy name: %s", policy.Name)
+		}
+		seen[policy.Name] = true
+
+		switch policy.Strategy {
+		case StrategyWeightedMean, StrategyGeometricMean, StrategyMin:
+		default:
+			return fmt.Errorf("policy %s has unknown strategy: %s", policy.Name, policy.Strategy)
+		}
+
+		if policy.PrecheckWeight < 0.0 || policy.JudgeWeight < 0.0 {
+			return fmt.Errorf("policy %s has negative stage weight", policy.Name)
+		}
+
+		for stage, weight := range policy.StageWeights {
+			if weight < 0.0 {
+				return fmt.Errorf("policy %s has negative weight for %s: %f", policy.Name, stage, weight)
+			}
+		}
+
+		for j, veto := range policy.Vetoes {
+			if veto.Stage == "" {
+				return fmt.Errorf("policy %s veto at index %d is missing stage", policy.Name, j)
+			}
+			if veto.Below < 0.0 || veto.Below > 1.0 {
+				return fmt.Errorf("policy %s veto on %s has invalid threshold: %f (must be 0.0-1.0)", policy.Name, veto.Stage, veto.Below)
+			}
+			if veto.Verdict != "fail" && veto.Verdict != "review" {
+				return fmt.Errorf("policy %s veto on %s has invalid verdict: %s (must be fail or review)", policy.Name, veto.Stage, veto.Verdict)
+			}
+		}
+
+		switch policy.FailedStages {
+		case "", FailedStagesExclude, FailedStagesZero:
+		default:
+			return fmt.Errorf("policy %s has unknown failed_stages mode: %s (must be exclude or zero)", policy.Name, policy.FailedStages)
+		}
+
+		if policy.MaxFailedJudges != nil && (*policy.MaxFailedJudges < 0.0 || *policy.MaxFailedJudges > 1.0) {
+			return fmt.Errorf("policy %s has invalid max_failed_judges: %f (must be 0.0-1.0)", policy.Name, *policy.MaxFailedJudges)
+		}
+
+		if policy.MaxJudgeVariance < 0.0 || policy.MaxJudgeVariance > 0.25 {
+			return fmt.Errorf("policy %s has invalid max_judge_variance: %f (must be 0.0-0.25)", policy.Name, policy.MaxJudgeVariance)
+		}
+
+		bands := policy.Verdicts
+		if bands.Review < 0.0 || bands.Pass > 1.0 || bands.Review > bands.Pass {
+			return fmt.Errorf("policy %s has invalid verdict bands: pass=%f review=%f (need 0.0 <= review <= pass <= 1.0)", policy.Name, bands.Pass, bands.Review)
+		}
+	}
+
+	if !seen[cfg.Aggregation.DefaultPolicy] {
+		return fmt.Errorf("default policy %q is not defined", cfg.Aggregation.DefaultPolicy)
+	}
+
+	for agent, policy := range cfg.Aggregation.Agents {
+		if !seen[policy] {
+			return fmt.Errorf("agent %s references unknown policy: %s", agent, policy)
+		}
+	}
+
+	return nil
+}
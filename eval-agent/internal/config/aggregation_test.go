@@ -0,0 +1,247 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAggregationConfig_Success(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "aggregation.yaml")
+
+	configContent := `aggregation:
+  default_policy: standard
+  policies:
+    - name: standard
+
+    - name: strict
+      strategy: min
+      precheck_weight: 0.2
+      judge_weight: 0.8
+      stage_weights:
+        faithfulness: 2.0
+      vetoes:
+        - stage: faithfulness
+          below: 0.3
+      verdict_bands:
+        pass: 0.9
+        review: 0.6
+      failed_stages: zero
+      max_failed_judges: 0.0
+  agents:
+    billing-agent: strict
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	os.Setenv("AGGREGATION_CONFIG_PATH", configPath)
+	defer os.Unsetenv("AGGREGATION_CONFIG_PATH")
+
+	cfg, err := LoadAggregationConfig()
+	if err != nil {
+		t.Fatalf("LoadAggregationConfig() failed: %v", err)
+	}
+
+	if len(cfg.Aggregation.Policies) != 2 {
+		t.Fatalf("Expected 2 policies, got %d", len(cfg.Aggregation.Policies))
+	}
+
+	// Omitted fields should fall back to the defaults
+	standard := cfg.Aggregation.Policies[0]
+	if standard.Strategy != StrategyWeightedMean {
+		t.Errorf("Expected strategy=%s (default), got %s", StrategyWeightedMean, standard.Strategy)
+	}
+	if standard.PrecheckWeight != 0.3 || standard.JudgeWeight != 0.7 {
+		t.Errorf("Expected weights 0.3/0.7 (default), got %f/%f", standard.PrecheckWeight, standard.JudgeWeight)
+	}
+	if standard.Verdicts.Pass != 0.8 || standard.Verdicts.Review != 0.5 {
+		t.Errorf("Expected bands 0.8/0.5 (default), got %f/%f", standard.Verdicts.Pass, standard.Verdicts.Review)
+	}
+	if standard.FailedStages != FailedStagesExclude {
+		t.Errorf("Expected failed_stages=%s (default), got %s", FailedStagesExclude, standard.FailedStages)
+	}
+	if standard.MaxFailedJudges == nil || *standard.MaxFailedJudges != DefaultMaxFailedJudges {
+		t.Errorf("Expected max_failed_judges=%f (default), got %v", DefaultMaxFailedJudges, standard.MaxFailedJudges)
+	}
+
+	strict := cfg.Aggregation.Policies[1]
+	if strict.Strategy != StrategyMin {
+		t.Errorf("Expected strategy=min, got %s", strict.Strategy)
+	}
+	if strict.StageWeights["faithfulness"] != 2.0 {
+		t.Errorf("Expected faithfulness weight=2.0, got %f", strict.StageWeights["faithfulness"])
+	}
+	if len(strict.Vetoes) != 1 || strict.Vetoes[0].Verdict != "fail" {
+		t.Errorf("Expected one veto with verdict=fail (default), got %+v", strict.Vetoes)
+	}
+	if strict.FailedStages != FailedStagesZero {
+		t.Errorf("Expected failed_stages=zero, got %s", strict.FailedStages)
+	}
+	if strict.MaxFailedJudges == nil || *strict.MaxFailedJudges != 0.0 {
+		t.Errorf("Expected explicit max_failed_judges=0.0 to be kept, got %v", strict.MaxFailedJudges)
+	}
+
+	if cfg.Aggregation.Agents["billing-agent"] != "strict" {
+		t.Errorf("Expected billing-agent to use strict, got %s", cfg.Aggregation.Agents["billing-agent"])
+	}
+}
+
+func TestLoadAggregationConfig_SinglePolicyIsDefault(t *testing.T) {
+	cfg := &PoliciesConfig{
+		Aggregation: Aggregation{
+			Policies: []AggregationPolicy{{Name: "only"}},
+		},
+	}
+
+	applyAggregationDefaults(cfg)
+
+	if cfg.Aggregation.DefaultPolicy != "only" {
+		t.Errorf("Expected default_policy=only, got %q", cfg.Aggregation.DefaultPolicy)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got: %v", err)
+	}
+}
+
+func TestLoadAggregationConfig_FileNotFound(t *testing.T) {
+	os.Setenv("AGGREGATION_CONFIG_PATH", "/nonexistent/path/aggregation.yaml")
+	defer os.Unsetenv("AGGREGATION_CONFIG_PATH")
+
+	_, err := LoadAggregationConfig()
+	if err == nil {
+		t.Fatal("Expected error for nonexistent config file")
+	}
+
+	if !contains(err.Error(), "failed to read config file") {
+		t.Errorf("Expected 'failed to read config file' error, got: %v", err)
+	}
+}
+
+func TestValidateAggregation(t *testing.T) {
+	valid := func() AggregationPolicy {
+		return AggregationPolicy{
+			Name:           "standard",
+			Strategy:       StrategyWeightedMean,
+			PrecheckWeight: 0.3,
+			JudgeWeight:    0.7,
+			Verdicts:       VerdictBands{Pass: 0.8, Review: 0.5},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(cfg *PoliciesConfig)
+		wantErr string
+	}{
+		{
+			name:    "no policies",
+			mutate:  func(cfg *PoliciesConfig) { cfg.Aggregation.Policies = nil },
+			wantErr: "no aggregation policies configured",
+		},
+		{
+			name:    "missing name",
+			mutate:  func(cfg *PoliciesConfig) { cfg.Aggregation.Policies[0].Name = "" },
+			wantErr: "missing name",
+		},
+		{
+			name: "duplicate name",
+			mutate: func(cfg *PoliciesConfig) {
+				cfg.Aggregation.Policies = append(cfg.Aggregation.Policies, valid())
+			},
+			wantErr: "duplicate policy name",
+		},
+		{
+			name:    "unknown strategy",
+			mutate:  func(cfg *PoliciesConfig) { cfg.Aggregation.Policies[0].Strategy = "median" },
+			wantErr: "unknown strategy",
+		},
+		{
+			name:    "negative stage weight",
+			mutate:  func(cfg *PoliciesConfig) { cfg.Aggregation.Policies[0].JudgeWeight = -0.7 },
+			wantErr: "negative stage weight",
+		},
+		{
+			name: "negative judge weight",
+			mutate: func(cfg *PoliciesConfig) {
+				cfg.Aggregation.Policies[0].StageWeights = map[string]float64{"relevance": -1}
+			},
+			wantErr: "negative weight for relevance",
+		},
+		{
+			name: "veto without stage",
+			mutate: func(cfg *PoliciesConfig) {
+				cfg.Aggregation.Policies[0].Vetoes = []VetoRule{{Below: 0.3, Verdict: "fail"}}
+			},
+			wantErr: "missing stage",
+		},
+		{
+			name: "veto with invalid verdict",
+			mutate: func(cfg *PoliciesConfig) {
+				cfg.Aggregation.Policies[0].Vetoes = []VetoRule{{Stage: "faithfulness", Below: 0.3, Verdict: "pass"}}
+			},
+			wantErr: "invalid verdict",
+		},
+		{
+			name: "inverted verdict bands",
+			mutate: func(cfg *PoliciesConfig) {
+				cfg.Aggregation.Policies[0].Verdicts = VerdictBands{Pass: 0.5, Review: 0.8}
+			},
+			wantErr: "invalid verdict bands",
+		},
+		{
+			name: "unknown failed stages mode",
+			mutate: func(cfg *PoliciesConfig) {
+				cfg.Aggregation.Policies[0].FailedStages = "ignore"
+			},
+			wantErr: "unknown failed_stages mode",
+		},
+		{
+			name: "max failed judges above one",
+			mutate: func(cfg *PoliciesConfig) {
+				maxFailed := 1.5
+				cfg.Aggregation.Policies[0].MaxFailedJudges = &maxFailed
+			},
+			wantErr: "invalid max_failed_judges",
+		},
+		{
+			name:    "max judge variance above a quarter",
+			mutate:  func(cfg *PoliciesConfig) { cfg.Aggregation.Policies[0].MaxJudgeVariance = 0.3 },
+			wantErr: "invalid max_judge_variance",
+		},
+		{
+			name:    "unknown default policy",
+			mutate:  func(cfg *PoliciesConfig) { cfg.Aggregation.DefaultPolicy = "missing" },
+			wantErr: "default policy \"missing\" is not defined",
+		},
+		{
+			name: "agent references unknown policy",
+			mutate: func(cfg *PoliciesConfig) {
+				cfg.Aggregation.Agents = map[string]string{"billing-agent": "strict"}
+			},
+			wantErr: "references unknown policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &PoliciesConfig{
+				Aggregation: Aggregation{
+					DefaultPolicy: "standard",
+					Policies:      []AggregationPolicy{valid()},
+				},
+			}
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatalf("Expected validation error containing %q", tt.wantErr)
+			}
+			if !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// configPath returns the config file path set in the environment variable, else the default
+func configPath(env string, defaultPath string) string {
+	if path := os.Getenv(env); path != "" {
+		return path
+	}
+	return defaultPath
+}
+
+// evaluationConfigs are the config files that change evaluation results, pricing only
+// changes the reported cost
+var evaluationConfigs = []struct {
+	name        string
+	env         string
+	defaultPath string
+}{
+	{"judges", "JUDGES_CONFIG_PATH", "configs/judges.yaml"},
+	{"prechecks", "PRECHECKS_CONFIG_PATH", "configs/prechecks.yaml"},
+	{"aggregation", "AGGREGATION_CONFIG_PATH", "configs/aggregation.yaml"},
+	{"profiles", "PROFILES_CONFIG_PATH", "configs/profiles.yaml"},
+}
+
+// Fingerprint returns the SHA-256 of every config file that changes evaluation results,
+// keyed by config name (judges, prechecks, aggregation, profiles). Two runs with equal
+// fingerprints evaluate with the same judges, prompts, prechecks and policies.
+func Fingerprint() (map[string]string, error) {
+	fingerprint := make(map[string]string, len(evaluationConfigs))
+
+	for _, cfg := range evaluationConfigs {
+		path := configPath(cfg.env, cfg.defaultPath)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+
+		sum := sha256.Sum256(data)
+		fingerprint[cfg.name] = hex.EncodeToString(sum[:])
+	}
+
+	return fingerprint, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	tmpDir := t.TempDir()
+	envs := map[string]string{
+		"judges":      "JUDGES_CONFIG_PATH",
+		"prechecks":   "PRECHECKS_CONFIG_PATH",
+		"aggregation": "AGGREGATION_CONFIG_PATH",
+		"profiles":    "PROFILES_CONFIG_PATH",
+	}
+	for name, env := range envs {
+		path := filepath.Join(tmpDir, name+".yaml")
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write test config: %v", err)
+		}
+		t.Setenv(env, path)
+	}
+
+	before, err := Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint() failed: %v", err)
+	}
+	if len(before) != 4 {
+		t.Errorf("Expected 4 config hashes, got %v", before)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "judges.yaml"), []byte("judges: changed"), 0644)
+	after, err := Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint() failed: %v", err)
+	}
+
+	if after["judges"] == before["judges"] {
+		t.Error("Expected the judges hash to change with the file")
+	}
+	if after["prechecks"] != before["prechecks"] {
+		t.Error("Expected the prechecks hash to be unchanged")
+	}
+
+	t.Setenv("PROFILES_CONFIG_PATH", filepath.Join(tmpDir, "missing.yaml"))
+	if _, err := Fingerprint(); err == nil {
+		t.Error("Expected error for a missing config file")
+	}
+}
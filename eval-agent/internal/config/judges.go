@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"text/template"
 
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,12 +23,37 @@ type Judges struct {
 
 // JudgeConfiguration defines a single judge configuration
 type JudgeConfiguration struct {
-	Name            string       `yaml:"name"`
-	Enabled         bool         `yaml:"enabled"`
-	Description     string       `yaml:"description"`
-	RequiresContext bool         `yaml:"requires_context"`
-	Prompt          string       `yaml:"prompt"`
-	Model           *ModelConfig `yaml:"model,omitempty"` // Optional override
+	Name              string        `yaml:"name"`
+	Enabled           bool          `yaml:"enabled"`
+	Description       string        `yaml:"description"`
+	RequiresContext   bool          `yaml:"requires_context"`
+	RequiresReference bool          `yaml:"requires_reference,omitempty"` // Skipped when the request has no reference_answer
+	Prompt            string        `yaml:"prompt"`
+	Model             *ModelConfig  `yaml:"model,omitempty"`           // Optional override
+	Rubric            *Rubric       `yaml:"rubric,omitempty"`          // Optional multi-criterion scoring
+	Samples           int           `yaml:"samples,omitempty"`         // Self-consistency: number of LLM calls, the score is their mean
+	Ensemble          []ModelConfig `yaml:"ensemble,omitempty"`        // Models the samples rotate through, defaults to the judge's model
+	PairwisePrompt    string        `yaml:"pairwise_prompt,omitempty"` // Prompt comparing two answers, defaults to a generic one built from the description
+}
+
+// Rubric declares named criteria the LLM scores individually. The judge score is the
+// weighted mean of the criterion scores, normalized from the scale to 0.0-1.0.
+type Rubric struct {
+	Scale    Scale       `yaml:"scale"`
+	Criteria []Criterion `yaml:"criteria"`
+}
+
+// Scale is the range the LLM scores each criterion on
+type Scale struct {
+	Min float64 `yaml:"min"`
+	Max float64 `yaml:"max"`
+}
+
+// Criterion is a single rubric item
+type Criterion struct {
+	Name        string  `yaml:"name"`
+	Description string  `yaml:"description"`
+	Weight      float64 `yaml:"weight"`
 }
 
 // ModelConfig defines LLM model parameters
@@ -34,14 +61,14 @@ type ModelConfig struct {
 	MaxTokens   int     `yaml:"max_tokens,omitempty"`
 	Temperature float64 `yaml:"temperature,omitempty"`
 	Retry       bool    `yaml:"retry,omitempty"`
+	Repair      bool    `yaml:"repair,omitempty"`   // Re-prompt once for valid JSON when the response can't be parsed
+	Provider    string  `yaml:"provider,omitempty"` // bedrock, openai or scripted, empty uses LLM_PROVIDER
+	ModelID     string  `yaml:"model_id,omitempty"` // Empty uses the provider's model (CLAUDE_MODEL_ID, OPENAI_MODEL_ID)
 }
 
 // LoadJudgesConfig loads and validates the judges configuration from YAML
 func LoadJudgesConfig() (*JudgesConfig, error) {
-	path := os.Getenv("JUDGES_CONFIG_PATH")
-	if path == "" {
-		path = "configs/judges.yaml"
-	}
+	path := configPath("JUDGES_CONFIG_PATH", "configs/judges.yaml")
 
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -74,11 +101,25 @@ func applyDefaults(cfg *JudgesConfig) {
 	for i := range cfg.Judges.Evaluators {
 		judge := &cfg.Judges.Evaluators[i]
 
+		if judge.Rubric != nil {
+			if judge.Rubric.Scale.Min == 0.0 && judge.Rubric.Scale.Max == 0.0 {
+				judge.Rubric.Scale.Max = 1.0
+			}
+			for j := range judge.Rubric.Criteria {
+				if judge.Rubric.Criteria[j].Weight == 0.0 {
+					judge.Rubric.Criteria[j].Weight = 1.0
+				}
+			}
+		}
+
 		if judge.Model == nil {
 			judge.Model = &ModelConfig{
 				MaxTokens:   cfg.Judges.DefaultModel.MaxTokens,
 				Temperature: cfg.Judges.DefaultModel.Temperature,
 				Retry:       cfg.Judges.DefaultModel.Retry,
+				Repair:      cfg.Judges.DefaultModel.Repair,
+				Provider:    cfg.Judges.DefaultModel.Provider,
+				ModelID:     cfg.Judges.DefaultModel.ModelID,
 			}
 		} else {
 			if judge.Model.MaxTokens == 0 {
@@ -87,6 +128,37 @@ func applyDefaults(cfg *JudgesConfig) {
 			if judge.Model.Temperature == 0.0 {
 				judge.Model.Temperature = cfg.Judges.DefaultModel.Temperature
 			}
+			// The default model ID only applies to judges that keep the default provider
+			if judge.Model.Provider == "" {
+				judge.Model.Provider = cfg.Judges.DefaultModel.Provider
+				if judge.Model.ModelID == "" {
+					judge.Model.ModelID = cfg.Judges.DefaultModel.ModelID
+				}
+			}
+		}
+
+		// Ensemble models inherit unset fields from the judge's model, retry and repair
+		// always follow it
+		for j := range judge.Ensemble {
+			member := &judge.Ensemble[j]
+			if member.MaxTokens == 0 {
+				member.MaxTokens = judge.Model.MaxTokens
+			}
+			if member.Temperature == 0.0 {
+				member.Temperature = judge.Model.Temperature
+			}
+			if member.Provider == "" {
+				member.Provider = judge.Model.Provider
+				if member.ModelID == "" {
+					member.ModelID = judge.Model.ModelID
+				}
+			}
+			member.Retry = judge.Model.Retry
+			member.Repair = judge.Model.Repair
+		}
+
+		if judge.Samples == 0 {
+			judge.Samples = max(1, len(judge.Ensemble))
 		}
 	}
 }
@@ -115,6 +187,15 @@ func (cfg *JudgesConfig) Validate() error {
 		if _, err := template.New(judge.Name).Parse(judge.Prompt); err != nil {
 			return fmt.Errorf("judge %s has invalid prompt template: %w", judge.Name, err)
 		}
+		if _, err := template.New(judge.Name).Parse(judge.PairwisePrompt); err != nil {
+			return fmt.Errorf("judge %s has invalid pairwise prompt template: %w", judge.Name, err)
+		}
+
+		if judge.Rubric != nil {
+			if err := judge.Rubric.validate(); err != nil {
+				return fmt.Errorf("judge %s has invalid rubric: %w", judge.Name, err)
+			}
+		}
 
 		if judge.Model != nil {
 			if judge.Model.MaxTokens < 0 {
@@ -123,6 +204,24 @@ func (cfg *JudgesConfig) Validate() error {
 			if judge.Model.Temperature < 0.0 || judge.Model.Temperature > 1.0 {
 				return fmt.Errorf("judge %s has invalid temperature: %f (must be 0.0-1.0)", judge.Name, judge.Model.Temperature)
 			}
+			if judge.Model.Provider != "" && !slices.Contains(llm.Providers, judge.Model.Provider) {
+				return fmt.Errorf("judge %s has unknown provider: %s", judge.Name, judge.Model.Provider)
+			}
+		}
+
+		if judge.Samples < 0 {
+			return fmt.Errorf("judge %s has negative samples: %d", judge.Name, judge.Samples)
+		}
+		if len(judge.Ensemble) > max(judge.Samples, 1) {
+			return fmt.Errorf("judge %s has %d ensemble models but only %d samples", judge.Name, len(judge.Ensemble), judge.Samples)
+		}
+		for j, member := range judge.Ensemble {
+			if member.Temperature < 0.0 || member.Temperature > 1.0 {
+				return fmt.Errorf("judge %s ensemble model at index %d has invalid temperature: %f (must be 0.0-1.0)", judge.Name, j, member.Temperature)
+			}
+			if member.Provider != "" && !slices.Contains(llm.Providers, member.Provider) {
+				return fmt.Errorf("judge %s ensemble model at index %d has unknown provider: %s", judge.Name, j, member.Provider)
+			}
 		}
 	}
 
@@ -132,6 +231,69 @@ func (cfg *JudgesConfig) Validate() error {
 	if cfg.Judges.DefaultModel.Temperature < 0.0 || cfg.Judges.DefaultModel.Temperature > 1.0 {
 		return fmt.Errorf("default model has invalid temperature: %f (must be 0.0-1.0)", cfg.Judges.DefaultModel.Temperature)
 	}
+	if cfg.Judges.DefaultModel.Provider != "" && !slices.Contains(llm.Providers, cfg.Judges.DefaultModel.Provider) {
+		return fmt.Errorf("default model has unknown provider: %s", cfg.Judges.DefaultModel.Provider)
+	}
+
+	return nil
+}
+
+func (r *Rubric) validate() error {
+	if len(r.Criteria) == 0 {
+		return fmt.Errorf("no criteria defined")
+	}
+
+	if r.Scale.Max <= r.Scale.Min {
+		return fmt.Errorf("scale max %f must be greater than min %f", r.Scale.Max, r.Scale.Min)
+	}
+
+	seen := make(map[string]bool)
+	totalWeight := 0.0
+
+	for i, criterion := range r.Criteria {
+		if criterion.Name == "" {
+			return fmt.Errorf("criterion at index %d is missing name", i)
+		}
+		if seen[criterion.Name] {
+			return fmt.Errorf("duplicate criterion name: %s", criterion.Name)
+		}
+		seen[criterion.Name] = true
+
+		if criterion.Weight < 0.0 {
+			return fmt.Errorf("criterion %s has negative weight: %f", criterion.Name, criterion.Weight)
+		}
+		totalWeight += criterion.Weight
+	}
+
+	if totalWeight == 0.0 {
+		return fmt.Errorf("criteria weights sum to zero")
+	}
 
 	return nil
 }
+
+// Only returns a copy of the config restricted to the named judges, which are enabled
+// even if they are disabled globally. An empty list returns the config unchanged.
+func (cfg *JudgesConfig) Only(names []string) (*JudgesConfig, error) {
+	if len(names) == 0 {
+		return cfg, nil
+	}
+
+	byName := make(map[string]JudgeConfiguration, len(cfg.Judges.Evaluators))
+	for _, judge := range cfg.Judges.Evaluators {
+		byName[judge.Name] = judge
+	}
+
+	selected := *cfg
+	selected.Judges.Evaluators = make([]JudgeConfiguration, 0, len(names))
+	for _, name := range names {
+		judge, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown judge: %s", name)
+		}
+		judge.Enabled = true
+		selected.Judges.Evaluators = append(selected.Judges.Evaluators, judge)
+	}
+
+	return &selected, nil
+}
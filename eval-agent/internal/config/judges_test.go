@@ -265,6 +265,25 @@ func TestValidate_InvalidPromptTemplate(t *testing.T) {
 	}
 }
 
+func TestValidate_InvalidPairwisePromptTemplate(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			Evaluators: []JudgeConfiguration{
+				{
+					Name:           "test",
+					Prompt:         "{{.Answer}}",
+					PairwisePrompt: "{{.A.Answer",
+				},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil || !contains(err.Error(), "invalid pairwise prompt template") {
+		t.Errorf("Expected 'invalid pairwise prompt template' error, got: %v", err)
+	}
+}
+
 func TestValidate_DuplicateNames(t *testing.T) {
 	cfg := &JudgesConfig{
 		Judges: Judges{
@@ -353,6 +372,64 @@ func TestValidate_InvalidTemperature(t *testing.T) {
 	}
 }
 
+func TestValidate_UnknownProvider(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			Evaluators: []JudgeConfiguration{
+				{
+					Name:   "test",
+					Prompt: "test",
+					Model:  &ModelConfig{Provider: "azure"},
+				},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Expected validation error for unknown provider")
+	}
+
+	if !contains(err.Error(), "unknown provider: azure") {
+		t.Errorf("Expected 'unknown provider' error, got: %v", err)
+	}
+}
+
+func TestApplyDefaults_ModelID(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			DefaultModel: ModelConfig{Provider: "bedrock", ModelID: "sonnet"},
+			Evaluators: []JudgeConfiguration{
+				{Name: "faithfulness", Prompt: "test"},
+				{Name: "coherence", Prompt: "test", Model: &ModelConfig{ModelID: "haiku"}},
+				{Name: "relevance", Prompt: "test", Model: &ModelConfig{MaxTokens: 128}},
+				{Name: "instruction", Prompt: "test", Model: &ModelConfig{Provider: "openai"}},
+			},
+		},
+	}
+
+	applyDefaults(cfg)
+
+	tests := []struct {
+		judge    string
+		provider string
+		modelID  string
+	}{
+		{"faithfulness", "bedrock", "sonnet"},
+		{"coherence", "bedrock", "haiku"},
+		{"relevance", "bedrock", "sonnet"},
+		// A judge switching provider does not inherit the default model ID
+		{"instruction", "openai", ""},
+	}
+
+	for i, tt := range tests {
+		model := cfg.Judges.Evaluators[i].Model
+		if model.Provider != tt.provider || model.ModelID != tt.modelID {
+			t.Errorf("%s: expected %s/%q, got %s/%q", tt.judge, tt.provider, tt.modelID, model.Provider, model.ModelID)
+		}
+	}
+}
+
 func TestApplyDefaults_PopulatesDefaultModel(t *testing.T) {
 	cfg := &JudgesConfig{
 		Judges: Judges{
@@ -382,6 +459,7 @@ func TestApplyDefaults_CreatesModelForJudges(t *testing.T) {
 				MaxTokens:   300,
 				Temperature: 0.7,
 				Retry:       true,
+				Repair:      true,
 			},
 			Evaluators: []JudgeConfiguration{
 				{Name: "test", Prompt: "test", Model: nil},
@@ -404,6 +482,9 @@ func TestApplyDefaults_CreatesModelForJudges(t *testing.T) {
 	if !judge.Model.Retry {
 		t.Error("Expected retry=true")
 	}
+	if !judge.Model.Repair {
+		t.Error("Expected repair=true")
+	}
 }
 
 func TestApplyDefaults_MergesPartialOverrides(t *testing.T) {
@@ -451,3 +532,183 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestApplyDefaults_Rubric(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			Evaluators: []JudgeConfiguration{
+				{
+					Name:   "completeness",
+					Prompt: "test",
+					Rubric: &Rubric{Criteria: []Criterion{{Name: "coverage"}, {Name: "depth", Weight: 2.0}}},
+				},
+			},
+		},
+	}
+
+	applyDefaults(cfg)
+
+	rubric := cfg.Judges.Evaluators[0].Rubric
+	if rubric.Scale.Min != 0.0 || rubric.Scale.Max != 1.0 {
+		t.Errorf("Expected default scale 0-1, got %f-%f", rubric.Scale.Min, rubric.Scale.Max)
+	}
+	if rubric.Criteria[0].Weight != 1.0 {
+		t.Errorf("Expected default criterion weight=1.0, got %f", rubric.Criteria[0].Weight)
+	}
+	if rubric.Criteria[1].Weight != 2.0 {
+		t.Errorf("Expected criterion weight=2.0 to be kept, got %f", rubric.Criteria[1].Weight)
+	}
+}
+
+func TestValidate_InvalidRubric(t *testing.T) {
+	tests := []struct {
+		name    string
+		rubric  Rubric
+		wantErr string
+	}{
+		{
+			name:    "no criteria",
+			rubric:  Rubric{Scale: Scale{Max: 1}},
+			wantErr: "no criteria defined",
+		},
+		{
+			name:    "inverted scale",
+			rubric:  Rubric{Scale: Scale{Min: 5, Max: 1}, Criteria: []Criterion{{Name: "coverage", Weight: 1}}},
+			wantErr: "must be greater than min",
+		},
+		{
+			name:    "duplicate criterion",
+			rubric:  Rubric{Scale: Scale{Max: 1}, Criteria: []Criterion{{Name: "coverage", Weight: 1}, {Name: "coverage", Weight: 1}}},
+			wantErr: "duplicate criterion name",
+		},
+		{
+			name:    "zero total weight",
+			rubric:  Rubric{Scale: Scale{Max: 1}, Criteria: []Criterion{{Name: "coverage"}}},
+			wantErr: "weights sum to zero",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rubric := tt.rubric
+			cfg := &JudgesConfig{
+				Judges: Judges{
+					Evaluators: []JudgeConfiguration{{Name: "completeness", Prompt: "test", Rubric: &rubric}},
+				},
+			}
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatalf("Expected validation error containing %q", tt.wantErr)
+			}
+			if !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestApplyDefaults_Samples(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			DefaultModel: ModelConfig{Provider: "bedrock", ModelID: "sonnet", MaxTokens: 256, Temperature: 0.2, Retry: true},
+			Evaluators: []JudgeConfiguration{
+				{Name: "faithfulness", Prompt: "test"},
+				{Name: "coherence", Prompt: "test", Samples: 5},
+				{Name: "relevance", Prompt: "test", Ensemble: []ModelConfig{
+					{Temperature: 0.7},
+					{ModelID: "haiku", MaxTokens: 128},
+					{Provider: "openai"},
+				}},
+			},
+		},
+	}
+
+	applyDefaults(cfg)
+
+	evaluators := cfg.Judges.Evaluators
+	if evaluators[0].Samples != 1 || evaluators[1].Samples != 5 {
+		t.Errorf("Expected samples 1 and 5, got %d and %d", evaluators[0].Samples, evaluators[1].Samples)
+	}
+
+	// Samples default to the size of the ensemble
+	relevance := evaluators[2]
+	if relevance.Samples != 3 {
+		t.Errorf("Expected samples=3 for a 3 model ensemble, got %d", relevance.Samples)
+	}
+
+	tests := []struct {
+		provider    string
+		modelID     string
+		maxTokens   int
+		temperature float64
+	}{
+		{"bedrock", "sonnet", 256, 0.7},
+		{"bedrock", "haiku", 128, 0.2},
+		// A member switching provider does not inherit the judge's model ID
+		{"openai", "", 256, 0.2},
+	}
+
+	for i, tt := range tests {
+		member := relevance.Ensemble[i]
+		if member.Provider != tt.provider || member.ModelID != tt.modelID || member.MaxTokens != tt.maxTokens || member.Temperature != tt.temperature {
+			t.Errorf("ensemble model %d: expected %s/%q max_tokens=%d temperature=%f, got %s/%q max_tokens=%d temperature=%f",
+				i, tt.provider, tt.modelID, tt.maxTokens, tt.temperature, member.Provider, member.ModelID, member.MaxTokens, member.Temperature)
+		}
+		if !member.Retry {
+			t.Errorf("ensemble model %d: expected retry to follow the judge's model", i)
+		}
+	}
+}
+
+func TestValidate_InvalidSamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  int
+		ensemble []ModelConfig
+		wantErr  string
+	}{
+		{
+			name:    "negative samples",
+			samples: -1,
+			wantErr: "negative samples",
+		},
+		{
+			name:     "more models than samples",
+			samples:  2,
+			ensemble: []ModelConfig{{}, {}, {}},
+			wantErr:  "3 ensemble models but only 2 samples",
+		},
+		{
+			name:     "invalid ensemble temperature",
+			samples:  2,
+			ensemble: []ModelConfig{{Temperature: 1.5}},
+			wantErr:  "ensemble model at index 0 has invalid temperature",
+		},
+		{
+			name:     "unknown ensemble provider",
+			samples:  2,
+			ensemble: []ModelConfig{{}, {Provider: "gemini"}},
+			wantErr:  "ensemble model at index 1 has unknown provider",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &JudgesConfig{
+				Judges: Judges{
+					Evaluators: []JudgeConfiguration{{Name: "faithfulness", Prompt: "test", Samples: tt.samples, Ensemble: tt.ensemble}},
+				},
+			}
+			applyDefaults(cfg)
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatalf("Expected validation error containing %q", tt.wantErr)
+			}
+			if !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
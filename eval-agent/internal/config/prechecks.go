@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PrechecksConfig is the root configuration structure for stage 1 checkers
+type PrechecksConfig struct {
+	Prechecks Prechecks `yaml:"prechecks"`
+}
+
+// Prechecks contains the list of configured checkers
+type Prechecks struct {
+	Checkers []PrecheckConfiguration `yaml:"checkers"`
+}
+
+// PrecheckConfiguration defines a single checker configuration
+type PrecheckConfiguration struct {
+	Name         string             `yaml:"name"`
+	Enabled      bool               `yaml:"enabled"`
+	Description  string             `yaml:"description"`
+	Weight       float64            `yaml:"weight,omitempty"`        // Relative weight within stage 1 (default: 1.0)
+	Thresholds   map[string]float64 `yaml:"thresholds,omitempty"`    // Checker specific tuning knobs
+	OutputFormat *OutputFormat      `yaml:"output_format,omitempty"` // Format required by the structure checker
+}
+
+// Output formats verified by the structure checker
+const (
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTable = "table"
+	FormatList  = "list"
+	FormatCode  = "code"
+)
+
+// OutputFormat is the structure an answer must have: Items is the number of list items
+// (0 means any) and Language the language tag of a code block (empty means any).
+type OutputFormat struct {
+	Type     string `yaml:"type"` // json, yaml, table, list or code
+	Items    int    `yaml:"items,omitempty"`
+	Language string `yaml:"language,omitempty"`
+}
+
+var outputFormatTypes = []string{FormatJSON, FormatYAML, FormatTable, FormatList, FormatCode}
+
+func (f *OutputFormat) Validate() error {
+	if !slices.Contains(outputFormatTypes, f.Type) {
+		return fmt.Errorf("unknown output format type %q (must be one of %v)", f.Type, outputFormatTypes)
+	}
+	if f.Items < 0 {
+		return fmt.Errorf("output format has negative items: %d", f.Items)
+	}
+	if f.Items > 0 && f.Type != FormatList {
+		return fmt.Errorf("output format items only apply to lists")
+	}
+	if f.Language != "" && f.Type != FormatCode {
+		return fmt.Errorf("output format language only applies to code")
+	}
+	return nil
+}
+
+// Threshold returns the named threshold or the given default when it is not configured
+func (c PrecheckConfiguration) Threshold(key string, defaultValue float64) float64 {
+	if value, ok := c.Thresholds[key]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// LoadPrechecksConfig loads and validates the prechecks configuration from YAML
+func LoadPrechecksConfig() (*PrechecksConfig, error) {
+	path := configPath("PRECHECKS_CONFIG_PATH", "configs/prechecks.yaml")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var cfg PrechecksConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	applyPrecheckDefaults(&cfg)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+func applyPrecheckDefaults(cfg *PrechecksConfig) {
+	for i := range cfg.Prechecks.Checkers {
+		checker := &cfg.Prechecks.Checkers[i]
+		if checker.Weight == 0.0 {
+			checker.Weight = 1.0
+		}
+	}
+}
+
+func (cfg *PrechecksConfig) Validate() error {
+	if len(cfg.Prechecks.Checkers) == 0 {
+		return fmt.Errorf("no prechecks configured in checkers list")
+	}
+
+	seen := make(map[string]bool)
+
+	for i, checker := range cfg.Prechecks.Checkers {
+		if checker.Name == "" {
+			return fmt.Errorf("precheck at index %d is missing name", i)
+		}
+
+		if seen[checker.Name] {
+			return fmt.Errorf("duplicate precheck name: %s", checker.Name)
+		}
+		seen[checker.Name] = true
+
+		if checker.Weight < 0.0 {
+			return fmt.Errorf("precheck %s has negative weight: %f", checker.Name, checker.Weight)
+		}
+
+		for key, value := range checker.Thresholds {
+			if value < 0.0 {
+				return fmt.Errorf("precheck %s has negative threshold %s: %f", checker.Name, key, value)
+			}
+		}
+
+		minRatio, hasMin := checker.Thresholds["min_ratio"]
+		maxRatio, hasMax := checker.Thresholds["max_ratio"]
+		if hasMin && hasMax && minRatio >= maxRatio {
+			return fmt.Errorf("precheck %s has min_ratio %f not below max_ratio %f", checker.Name, minRatio, maxRatio)
+		}
+
+		if checker.OutputFormat != nil {
+			if err := checker.OutputFormat.Validate(); err != nil {
+				return fmt.Errorf("precheck %s: %w", checker.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Only returns a copy of the config restricted to the named prechecks, which are enabled
+// even if they are disabled globally. An empty list returns the config unchanged.
+func (cfg *PrechecksConfig) Only(names []string) (*PrechecksConfig, error) {
+	if len(names) == 0 {
+		return cfg, nil
+	}
+
+	byName := make(map[string]PrecheckConfiguration, len(cfg.Prechecks.Checkers))
+	for _, checker := range cfg.Prechecks.Checkers {
+		byName[checker.Name] = checker
+	}
+
+	selected := *cfg
+	selected.Prechecks.Checkers = make([]PrecheckConfiguration, 0, len(names))
+	for _, name := range names {
+		checker, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown precheck: %s", name)
+		}
+		checker.Enabled = true
+		selected.Prechecks.Checkers = append(selected.Prechecks.Checkers, checker)
+	}
+
+	return &selected, nil
+}
+
+// WithThresholds returns a copy of the config with the thresholds of the named prechecks
+// replaced by the given values, keeping the ones not overridden. No overrides return the
+// config unchanged.
+func (cfg *PrechecksConfig) WithThresholds(overrides map[string]map[string]float64) (*PrechecksConfig, error) {
+	if len(overrides) == 0 {
+		return cfg, nil
+	}
+
+	updated := *cfg
+	updated.Prechecks.Checkers = make([]PrecheckConfiguration, len(cfg.Prechecks.Checkers))
+	copy(updated.Prechecks.Checkers, cfg.Prechecks.Checkers)
+
+	for name, thresholds := range overrides {
+		index := slices.IndexFunc(updated.Prechecks.Checkers, func(c PrecheckConfiguration) bool { return c.Name == name })
+		if index < 0 {
+			return nil, fmt.Errorf("thresholds for unknown precheck: %s", name)
+		}
+
+		checker := &updated.Prechecks.Checkers[index]
+		merged := maps.Clone(checker.Thresholds)
+		if merged == nil {
+			merged = make(map[string]float64, len(thresholds))
+		}
+		maps.Copy(merged, thresholds)
+		checker.Thresholds = merged
+	}
+
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// WithOutputFormat returns a copy of the config with the structure checker enabled and
+// requiring the given format. A nil format returns the config unchanged.
+func (cfg *PrechecksConfig) WithOutputFormat(format *OutputFormat) (*PrechecksConfig, error) {
+	if format == nil {
+		return cfg, nil
+	}
+
+	index := slices.IndexFunc(cfg.Prechecks.Checkers, func(c PrecheckConfiguration) bool { return c.Name == "structure" })
+	if index < 0 {
+		return nil, fmt.Errorf("output format requires the structure precheck")
+	}
+
+	updated := *cfg
+	updated.Prechecks.Checkers = slices.Clone(cfg.Prechecks.Checkers)
+	updated.Prechecks.Checkers[index].Enabled = true
+	updated.Prechecks.Checkers[index].OutputFormat = format
+
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPrechecksConfig_Success(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "prechecks.yaml")
+
+	configContent := `prechecks:
+  checkers:
+    - name: length
+      enabled: true
+      weight: 2.0
+      thresholds:
+        min_ratio: 0.2
+        max_ratio: 40.0
+
+    - name: overlap
+      enabled: false
+      thresholds:
+        min_overlap: 0.3
+
+    - name: format
+      enabled: true
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	os.Setenv("PRECHECKS_CONFIG_PATH", configPath)
+	defer os.Unsetenv("PRECHECKS_CONFIG_PATH")
+
+	cfg, err := LoadPrechecksConfig()
+	if err != nil {
+		t.Fatalf("LoadPrechecksConfig() failed: %v", err)
+	}
+
+	if len(cfg.Prechecks.Checkers) != 3 {
+		t.Fatalf("Expected 3 checkers, got %d", len(cfg.Prechecks.Checkers))
+	}
+
+	length := cfg.Prechecks.Checkers[0]
+	if length.Weight != 2.0 {
+		t.Errorf("Expected length weight=2.0, got %f", length.Weight)
+	}
+	if length.Threshold("max_ratio", 0) != 40.0 {
+		t.Errorf("Expected length max_ratio=40.0, got %f", length.Threshold("max_ratio", 0))
+	}
+
+	overlap := cfg.Prechecks.Checkers[1]
+	if overlap.Enabled {
+		t.Error("Expected overlap to be disabled")
+	}
+
+	// Weight should default to 1.0 when omitted
+	format := cfg.Prechecks.Checkers[2]
+	if format.Weight != 1.0 {
+		t.Errorf("Expected format weight=1.0 (default), got %f", format.Weight)
+	}
+	if format.Threshold("min_words", 5) != 5 {
+		t.Errorf("Expected missing threshold to fall back to default, got %f", format.Threshold("min_words", 5))
+	}
+}
+
+func TestLoadPrechecksConfig_FileNotFound(t *testing.T) {
+	os.Setenv("PRECHECKS_CONFIG_PATH", "/nonexistent/path/prechecks.yaml")
+	defer os.Unsetenv("PRECHECKS_CONFIG_PATH")
+
+	_, err := LoadPrechecksConfig()
+	if err == nil {
+		t.Fatal("Expected error for nonexistent config file")
+	}
+
+	if !contains(err.Error(), "failed to read config file") {
+		t.Errorf("Expected 'failed to read config file' error, got: %v", err)
+	}
+}
+
+func TestValidatePrechecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		checkers []PrecheckConfiguration
+		wantErr  string
+	}{
+		{
+			name:     "no checkers",
+			checkers: []PrecheckConfiguration{},
+			wantErr:  "no prechecks configured",
+		},
+		{
+			name:     "missing name",
+			checkers: []PrecheckConfiguration{{Name: ""}},
+			wantErr:  "missing name",
+		},
+		{
+			name:     "duplicate name",
+			checkers: []PrecheckConfiguration{{Name: "length"}, {Name: "length"}},
+			wantErr:  "duplicate precheck name",
+		},
+		{
+			name:     "negative weight",
+			checkers: []PrecheckConfiguration{{Name: "length", Weight: -1}},
+			wantErr:  "negative weight",
+		},
+		{
+			name:     "negative threshold",
+			checkers: []PrecheckConfiguration{{Name: "overlap", Thresholds: map[string]float64{"min_overlap": -0.1}}},
+			wantErr:  "negative threshold",
+		},
+		{
+			name:     "inverted ratios",
+			checkers: []PrecheckConfiguration{{Name: "length", Thresholds: map[string]float64{"min_ratio": 5, "max_ratio": 1}}},
+			wantErr:  "not below max_ratio",
+		},
+		{
+			name:     "unknown output format",
+			checkers: []PrecheckConfiguration{{Name: "structure", OutputFormat: &OutputFormat{Type: "xml"}}},
+			wantErr:  "unknown output format type",
+		},
+		{
+			name:     "items on a non-list format",
+			checkers: []PrecheckConfiguration{{Name: "structure", OutputFormat: &OutputFormat{Type: "json", Items: 3}}},
+			wantErr:  "items only apply to lists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &PrechecksConfig{Prechecks: Prechecks{Checkers: tt.checkers}}
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatalf("Expected validation error containing %q", tt.wantErr)
+			}
+			if !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PricingConfig is the root configuration structure for the model price table
+type PricingConfig struct {
+	Pricing Pricing `yaml:"pricing"`
+}
+
+// Pricing maps model IDs to their token prices
+type Pricing struct {
+	Models map[string]ModelPrice `yaml:"models"`
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	InputPerMillion  float64 `yaml:"input_per_million"`
+	OutputPerMillion float64 `yaml:"output_per_million"`
+}
+
+// Bedrock cross-region inference profiles prefix the model ID with a geography
+var inferenceProfilePrefixes = []string{"us.", "eu.", "apac.", "global."}
+
+// LoadPricingConfig loads and validates the price table from YAML
+func LoadPricingConfig() (*PricingConfig, error) {
+	path := configPath("PRICING_CONFIG_PATH", "configs/pricing.yaml")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var cfg PricingConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+func (cfg *PricingConfig) Validate() error {
+	for modelID, price := range cfg.Pricing.Models {
+		if price.InputPerMillion < 0.0 || price.OutputPerMillion < 0.0 {
+			return fmt.Errorf("model %s has a negative price", modelID)
+		}
+	}
+
+	return nil
+}
+
+// Price returns the price of a model. Inference profile IDs such as
+// us.anthropic.claude-3-5-haiku-20241022-v1:0 fall back to the price of the base model.
+func (cfg *PricingConfig) Price(modelID string) (ModelPrice, bool) {
+	if cfg == nil {
+		return ModelPrice{}, false
+	}
+
+	if price, ok := cfg.Pricing.Models[modelID]; ok {
+		return price, true
+	}
+
+	for _, prefix := range inferenceProfilePrefixes {
+		if baseID, found := strings.CutPrefix(modelID, prefix); found {
+			price, ok := cfg.Pricing.Models[baseID]
+			return price, ok
+		}
+	}
+
+	return ModelPrice{}, false
+}
+
+// Cost returns the estimated cost in USD of the given token counts
+func (p ModelPrice) Cost(inputTokens int, outputTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMillion + float64(outputTokens)*p.OutputPerMillion) / 1_000_000
+}
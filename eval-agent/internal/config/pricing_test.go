@@ -0,0 +1,93 @@
+package config
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPricingConfig_Success(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "pricing.yaml")
+
+	configContent := `pricing:
+  models:
+    "anthropic.claude-3-5-haiku-20241022-v1:0":
+      input_per_million: 0.80
+      output_per_million: 4.00
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	os.Setenv("PRICING_CONFIG_PATH", configPath)
+	defer os.Unsetenv("PRICING_CONFIG_PATH")
+
+	cfg, err := LoadPricingConfig()
+	if err != nil {
+		t.Fatalf("LoadPricingConfig() failed: %v", err)
+	}
+
+	price, ok := cfg.Price("anthropic.claude-3-5-haiku-20241022-v1:0")
+	if !ok || price.InputPerMillion != 0.80 || price.OutputPerMillion != 4.00 {
+		t.Errorf("Expected haiku price 0.80/4.00, got %+v (found=%v)", price, ok)
+	}
+}
+
+func TestLoadPricingConfig_NegativePrice(t *testing.T) {
+	cfg := &PricingConfig{
+		Pricing: Pricing{
+			Models: map[string]ModelPrice{"haiku": {InputPerMillion: -1.0}},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil || !contains(err.Error(), "negative price") {
+		t.Errorf("Expected 'negative price' error, got: %v", err)
+	}
+}
+
+func TestPricingConfig_Price(t *testing.T) {
+	cfg := &PricingConfig{
+		Pricing: Pricing{
+			Models: map[string]ModelPrice{
+				"anthropic.claude-3-5-haiku-20241022-v1:0": {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modelID string
+		found   bool
+	}{
+		{"exact model ID", "anthropic.claude-3-5-haiku-20241022-v1:0", true},
+		{"inference profile", "us.anthropic.claude-3-5-haiku-20241022-v1:0", true},
+		{"unknown model", "llama-3-8b", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, found := cfg.Price(tt.modelID); found != tt.found {
+				t.Errorf("Price(%s) found=%v, want %v", tt.modelID, found, tt.found)
+			}
+		})
+	}
+
+	// A nil table has no prices
+	var empty *PricingConfig
+	if _, found := empty.Price("anthropic.claude-3-5-haiku-20241022-v1:0"); found {
+		t.Error("Expected no price from a nil table")
+	}
+}
+
+func TestModelPrice_Cost(t *testing.T) {
+	price := ModelPrice{InputPerMillion: 3.00, OutputPerMillion: 15.00}
+
+	// 1000 input tokens at $3/M plus 200 output tokens at $15/M
+	if cost := price.Cost(1000, 200); math.Abs(cost-0.006) > 1e-12 {
+		t.Errorf("Expected cost 0.006, got %f", cost)
+	}
+}
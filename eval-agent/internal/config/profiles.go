@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfilesConfig is the root configuration structure for evaluation profiles
+type ProfilesConfig struct {
+	Evaluation Evaluation `yaml:"evaluation"`
+}
+
+// Evaluation contains the evaluation profiles and the fallback profile
+type Evaluation struct {
+	DefaultProfile string              `yaml:"default_profile"`
+	Profiles       []EvaluationProfile `yaml:"profiles"`
+}
+
+// EvaluationProfile selects the judges, prechecks and aggregation used for matching agents
+type EvaluationProfile struct {
+	Name               string                        `yaml:"name"`
+	Description        string                        `yaml:"description"`
+	Match              AgentMatch                    `yaml:"match"`
+	Judges             []string                      `yaml:"judges,omitempty"`               // Empty means all enabled judges
+	Prechecks          []string                      `yaml:"prechecks,omitempty"`            // Empty means all enabled prechecks
+	Policy             string                        `yaml:"policy,omitempty"`               // Aggregation policy, empty means select by agent name
+	PrecheckWeight     *float64                      `yaml:"precheck_weight,omitempty"`      // Optional override of the policy weight
+	JudgeWeight        *float64                      `yaml:"judge_weight,omitempty"`         // Optional override of the policy weight
+	EarlyExitThreshold *float64                      `yaml:"early_exit_threshold,omitempty"` // Optional override of EARLY_EXIT_THRESHOLD
+	Thresholds         map[string]map[string]float64 `yaml:"thresholds,omitempty"`           // Precheck threshold overrides keyed by precheck name
+	OutputFormat       *OutputFormat                 `yaml:"output_format,omitempty"`        // Format required by the structure precheck
+}
+
+// AgentMatch holds glob patterns (see path.Match) for the agent fields, empty fields match anything
+type AgentMatch struct {
+	Name    string `yaml:"name,omitempty"`
+	Type    string `yaml:"type,omitempty"`
+	Version string `yaml:"version,omitempty"`
+}
+
+// IsEmpty reports whether the match has no patterns
+func (m AgentMatch) IsEmpty() bool {
+	return m.Name == "" && m.Type == "" && m.Version == ""
+}
+
+// LoadProfilesConfig loads and validates the evaluation profiles from YAML
+func LoadProfilesConfig() (*ProfilesConfig, error) {
+	path := configPath("PROFILES_CONFIG_PATH", "configs/profiles.yaml")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var cfg ProfilesConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if cfg.Evaluation.DefaultProfile == "" && len(cfg.Evaluation.Profiles) == 1 {
+		cfg.Evaluation.DefaultProfile = cfg.Evaluation.Profiles[0].Name
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+func (cfg *ProfilesConfig) Validate() error {
+	if len(cfg.Evaluation.Profiles) == 0 {
+		return fmt.Errorf("no evaluation profiles configured")
+	}
+
+	seen := make(map[string]bool)
+
+	for i, profile := range cfg.Evaluation.Profiles {
+		if profile.Name == "" {
+			return fmt.Errorf("profile at index %d is missing name", i)
+		}
+
+		if seen[profile.Name] {
+			return fmt.Errorf("duplicate profile name: %s", profile.Name)
+		}
+		seen[profile.Name] = true
+
+		// Only the default profile may match every agent, otherwise it would shadow the ones below it
+		if profile.Match.IsEmpty() && profile.Name != cfg.Evaluation.DefaultProfile {
+			return fmt.Errorf("profile %s has no match patterns", profile.Name)
+		}
+
+		for _, pattern := range []string{profile.Match.Name, profile.Match.Type, profile.Match.Version} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("profile %s has invalid match pattern %q: %w", profile.Name, pattern, err)
+			}
+		}
+
+		if profile.PrecheckWeight != nil && *profile.PrecheckWeight < 0.0 {
+			return fmt.Errorf("profile %s has negative precheck_weight: %f", profile.Name, *profile.PrecheckWeight)
+		}
+		if profile.JudgeWeight != nil && *profile.JudgeWeight < 0.0 {
+			return fmt.Errorf("profile %s has negative judge_weight: %f", profile.Name, *profile.JudgeWeight)
+		}
+
+		if threshold := profile.EarlyExitThreshold; threshold != nil && (*threshold < 0.0 || *threshold > 1.0) {
+			return fmt.Errorf("profile %s has invalid early_exit_threshold: %f (must be 0.0-1.0)", profile.Name, *threshold)
+		}
+
+		for precheck, thresholds := range profile.Thresholds {
+			for key, value := range thresholds {
+				if value < 0.0 {
+					return fmt.Errorf("profile %s has negative threshold %s for precheck %s: %f", profile.Name, key, precheck, value)
+				}
+			}
+		}
+
+		if profile.OutputFormat != nil {
+			if err := profile.OutputFormat.Validate(); err != nil {
+				return fmt.Errorf("profile %s: %w", profile.Name, err)
+			}
+		}
+	}
+
+	if !seen[cfg.Evaluation.DefaultProfile] {
+		return fmt.Errorf("default profile %q is not defined", cfg.Evaluation.DefaultProfile)
+	}
+
+	return nil
+}
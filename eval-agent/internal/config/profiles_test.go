@@ -0,0 +1,292 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadProfilesConfig_Success(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "profiles.yaml")
+
+	configContent := `evaluation:
+  default_profile: default
+  profiles:
+    - name: default
+
+    - name: rag
+      match:
+        type: rag
+        version: "2.*"
+      judges: [relevance, faithfulness]
+      prechecks: [length]
+      policy: strict
+      judge_weight: 0.9
+      early_exit_threshold: 0.0
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	os.Setenv("PROFILES_CONFIG_PATH", configPath)
+	defer os.Unsetenv("PROFILES_CONFIG_PATH")
+
+	cfg, err := LoadProfilesConfig()
+	if err != nil {
+		t.Fatalf("LoadProfilesConfig() failed: %v", err)
+	}
+
+	if len(cfg.Evaluation.Profiles) != 2 {
+		t.Fatalf("Expected 2 profiles, got %d", len(cfg.Evaluation.Profiles))
+	}
+
+	rag := cfg.Evaluation.Profiles[1]
+	if rag.Match.Type != "rag" || rag.Match.Version != "2.*" {
+		t.Errorf("Expected match type=rag version=2.*, got %+v", rag.Match)
+	}
+	if len(rag.Judges) != 2 || len(rag.Prechecks) != 1 {
+		t.Errorf("Expected 2 judges and 1 precheck, got %v and %v", rag.Judges, rag.Prechecks)
+	}
+	if rag.PrecheckWeight != nil {
+		t.Errorf("Expected precheck_weight to be unset, got %f", *rag.PrecheckWeight)
+	}
+	if rag.JudgeWeight == nil || *rag.JudgeWeight != 0.9 {
+		t.Errorf("Expected judge_weight=0.9, got %v", rag.JudgeWeight)
+	}
+	// An explicit 0.0 disables early exit and must not be treated as unset
+	if rag.EarlyExitThreshold == nil || *rag.EarlyExitThreshold != 0.0 {
+		t.Errorf("Expected early_exit_threshold=0.0, got %v", rag.EarlyExitThreshold)
+	}
+}
+
+func TestLoadProfilesConfig_FileNotFound(t *testing.T) {
+	os.Setenv("PROFILES_CONFIG_PATH", "/nonexistent/path/profiles.yaml")
+	defer os.Unsetenv("PROFILES_CONFIG_PATH")
+
+	_, err := LoadProfilesConfig()
+	if err == nil {
+		t.Fatal("Expected error for nonexistent config file")
+	}
+
+	if !contains(err.Error(), "failed to read config file") {
+		t.Errorf("Expected 'failed to read config file' error, got: %v", err)
+	}
+}
+
+func TestValidateProfiles(t *testing.T) {
+	negative := -0.5
+	tooHigh := 1.5
+
+	tests := []struct {
+		name     string
+		profiles []EvaluationProfile
+		wantErr  string
+	}{
+		{
+			name:     "no profiles",
+			profiles: []EvaluationProfile{},
+			wantErr:  "no evaluation profiles configured",
+		},
+		{
+			name:     "missing name",
+			profiles: []EvaluationProfile{{Name: "default"}, {Match: AgentMatch{Type: "rag"}}},
+			wantErr:  "missing name",
+		},
+		{
+			name:     "duplicate name",
+			profiles: []EvaluationProfile{{Name: "default"}, {Name: "default"}},
+			wantErr:  "duplicate profile name",
+		},
+		{
+			name:     "catch-all profile that is not the default",
+			profiles: []EvaluationProfile{{Name: "default"}, {Name: "rag"}},
+			wantErr:  "has no match patterns",
+		},
+		{
+			name:     "invalid pattern",
+			profiles: []EvaluationProfile{{Name: "default"}, {Name: "rag", Match: AgentMatch{Name: "rag-["}}},
+			wantErr:  "invalid match pattern",
+		},
+		{
+			name:     "negative weight",
+			profiles: []EvaluationProfile{{Name: "default", JudgeWeight: &negative}},
+			wantErr:  "negative judge_weight",
+		},
+		{
+			name:     "early exit out of range",
+			profiles: []EvaluationProfile{{Name: "default", EarlyExitThreshold: &tooHigh}},
+			wantErr:  "invalid early_exit_threshold",
+		},
+		{
+			name:     "negative precheck threshold",
+			profiles: []EvaluationProfile{{Name: "default", Thresholds: map[string]map[string]float64{"semantic": {"min_query_similarity": -0.1}}}},
+			wantErr:  "negative threshold min_query_similarity for precheck semantic",
+		},
+		{
+			name:     "language on a non-code format",
+			profiles: []EvaluationProfile{{Name: "default", OutputFormat: &OutputFormat{Type: "table", Language: "go"}}},
+			wantErr:  "language only applies to code",
+		},
+		{
+			name:     "default not defined",
+			profiles: []EvaluationProfile{{Name: "rag", Match: AgentMatch{Type: "rag"}}},
+			wantErr:  "default profile \"default\" is not defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &ProfilesConfig{
+				Evaluation: Evaluation{DefaultProfile: "default", Profiles: tt.profiles},
+			}
+
+			err := cfg.Validate()
+			if err == nil {
+				t.Fatalf("Expected validation error containing %q", tt.wantErr)
+			}
+			if !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestJudgesConfig_Only(t *testing.T) {
+	cfg := &JudgesConfig{
+		Judges: Judges{
+			Evaluators: []JudgeConfiguration{
+				{Name: "relevance", Enabled: true},
+				{Name: "faithfulness", Enabled: false},
+				{Name: "coherence", Enabled: true},
+			},
+		},
+	}
+
+	selected, err := cfg.Only([]string{"faithfulness", "relevance"})
+	if err != nil {
+		t.Fatalf("Only() failed: %v", err)
+	}
+	if len(selected.Judges.Evaluators) != 2 {
+		t.Fatalf("Expected 2 judges, got %d", len(selected.Judges.Evaluators))
+	}
+	if selected.Judges.Evaluators[0].Name != "faithfulness" || !selected.Judges.Evaluators[0].Enabled {
+		t.Errorf("Expected faithfulness to be selected and enabled, got %+v", selected.Judges.Evaluators[0])
+	}
+	if cfg.Judges.Evaluators[1].Enabled {
+		t.Error("Expected the original config to be left unchanged")
+	}
+
+	if _, err := cfg.Only([]string{"toxicity"}); err == nil || !contains(err.Error(), "unknown judge: toxicity") {
+		t.Errorf("Expected 'unknown judge' error, got: %v", err)
+	}
+}
+
+func TestPrechecksConfig_Only(t *testing.T) {
+	cfg := &PrechecksConfig{
+		Prechecks: Prechecks{
+			Checkers: []PrecheckConfiguration{
+				{Name: "length", Enabled: true},
+				{Name: "overlap", Enabled: false},
+			},
+		},
+	}
+
+	selected, err := cfg.Only(nil)
+	if err != nil || selected != cfg {
+		t.Errorf("Expected an empty selection to return the config unchanged, got %v", err)
+	}
+
+	selected, err = cfg.Only([]string{"overlap"})
+	if err != nil {
+		t.Fatalf("Only() failed: %v", err)
+	}
+	if len(selected.Prechecks.Checkers) != 1 || !selected.Prechecks.Checkers[0].Enabled {
+		t.Errorf("Expected only overlap enabled, got %+v", selected.Prechecks.Checkers)
+	}
+
+	if _, err := cfg.Only([]string{"spelling"}); err == nil || !contains(err.Error(), "unknown precheck: spelling") {
+		t.Errorf("Expected 'unknown precheck' error, got: %v", err)
+	}
+}
+
+func TestPrechecksConfig_WithThresholds(t *testing.T) {
+	cfg := &PrechecksConfig{
+		Prechecks: Prechecks{
+			Checkers: []PrecheckConfiguration{
+				{Name: "length", Enabled: true, Thresholds: map[string]float64{"min_ratio": 0.5, "max_ratio": 10}},
+				{Name: "semantic", Enabled: true},
+			},
+		},
+	}
+
+	updated, err := cfg.WithThresholds(nil)
+	if err != nil || updated != cfg {
+		t.Errorf("Expected no overrides to return the config unchanged, got %v", err)
+	}
+
+	updated, err = cfg.WithThresholds(map[string]map[string]float64{
+		"length":   {"max_ratio": 20},
+		"semantic": {"min_query_similarity": 0.4},
+	})
+	if err != nil {
+		t.Fatalf("WithThresholds() failed: %v", err)
+	}
+
+	length := updated.Prechecks.Checkers[0]
+	if length.Threshold("min_ratio", 0) != 0.5 || length.Threshold("max_ratio", 0) != 20 {
+		t.Errorf("Expected max_ratio overridden and min_ratio kept, got %v", length.Thresholds)
+	}
+	if updated.Prechecks.Checkers[1].Threshold("min_query_similarity", 0) != 0.4 {
+		t.Errorf("Expected semantic threshold set, got %v", updated.Prechecks.Checkers[1].Thresholds)
+	}
+	if cfg.Prechecks.Checkers[0].Thresholds["max_ratio"] != 10 || cfg.Prechecks.Checkers[1].Thresholds != nil {
+		t.Error("Expected the original config to be left unchanged")
+	}
+
+	if _, err := cfg.WithThresholds(map[string]map[string]float64{"spelling": {"min": 1}}); err == nil || !contains(err.Error(), "unknown precheck: spelling") {
+		t.Errorf("Expected 'unknown precheck' error, got: %v", err)
+	}
+	if _, err := cfg.WithThresholds(map[string]map[string]float64{"length": {"min_ratio": 30}}); err == nil || !contains(err.Error(), "not below max_ratio") {
+		t.Errorf("Expected validation error, got: %v", err)
+	}
+}
+
+func TestPrechecksConfig_WithOutputFormat(t *testing.T) {
+	cfg := &PrechecksConfig{
+		Prechecks: Prechecks{
+			Checkers: []PrecheckConfiguration{
+				{Name: "format", Enabled: true},
+				{Name: "structure", Enabled: false},
+			},
+		},
+	}
+
+	updated, err := cfg.WithOutputFormat(nil)
+	if err != nil || updated != cfg {
+		t.Errorf("Expected no format to return the config unchanged, got %v", err)
+	}
+
+	updated, err = cfg.WithOutputFormat(&OutputFormat{Type: "list", Items: 5})
+	if err != nil {
+		t.Fatalf("WithOutputFormat() failed: %v", err)
+	}
+
+	structure := updated.Prechecks.Checkers[1]
+	if !structure.Enabled || structure.OutputFormat == nil || structure.OutputFormat.Items != 5 {
+		t.Errorf("Expected structure enabled with the format, got %+v", structure)
+	}
+	if cfg.Prechecks.Checkers[1].Enabled || cfg.Prechecks.Checkers[1].OutputFormat != nil {
+		t.Error("Expected the original config to be left unchanged")
+	}
+
+	if _, err := cfg.WithOutputFormat(&OutputFormat{Type: "xml"}); err == nil || !contains(err.Error(), "unknown output format type") {
+		t.Errorf("Expected validation error, got: %v", err)
+	}
+
+	only, _ := cfg.Only([]string{"format"})
+	if _, err := only.WithOutputFormat(&OutputFormat{Type: "json"}); err == nil || !contains(err.Error(), "requires the structure precheck") {
+		t.Errorf("Expected missing structure precheck error, got: %v", err)
+	}
+}
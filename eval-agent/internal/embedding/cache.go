@@ -0,0 +1,91 @@
+package embedding
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// CachedEmbedder keeps the embeddings of recently seen texts in memory, evicting the least
+// recently used once size is reached. Queries and contexts repeat across evaluations, so
+// only the answer usually has to be embedded.
+type CachedEmbedder struct {
+	embedder Embedder
+	size     int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Most recently used at the front
+}
+
+type cacheEntry struct {
+	text   string
+	vector []float64
+}
+
+func NewCachedEmbedder(embedder Embedder, size int) *CachedEmbedder {
+	return &CachedEmbedder{
+		embedder: embedder,
+		size:     size,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Embed returns the cached embeddings and embeds the remaining texts in a single call
+func (c *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	var missing []string
+	var missingIdx []int
+
+	c.mu.Lock()
+	for i, text := range texts {
+		if element, ok := c.entries[text]; ok {
+			c.order.MoveToFront(element)
+			vectors[i] = element.Value.(*cacheEntry).vector
+			continue
+		}
+		missing = append(missing, text)
+		missingIdx = append(missingIdx, i)
+	}
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := c.embedder.Embed(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for j, i := range missingIdx {
+		vectors[i] = embedded[j]
+		c.add(missing[j], embedded[j])
+	}
+
+	return vectors, nil
+}
+
+// Len returns the number of cached embeddings
+func (c *CachedEmbedder) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *CachedEmbedder) add(text string, vector []float64) {
+	if element, ok := c.entries[text]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[text] = c.order.PushFront(&cacheEntry{text: text, vector: vector})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).text)
+	}
+}
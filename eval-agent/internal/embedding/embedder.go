@@ -0,0 +1,68 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Supported embedding providers
+const (
+	ProviderHash   = "hash"   // Local deterministic feature hashing, no model or network needed
+	ProviderOpenAI = "openai" // Any OpenAI-compatible embeddings API (OpenAI, vLLM, Ollama, ...)
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how close their meaning is
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// Config selects the embedding provider and model
+type Config struct {
+	Provider   string
+	ModelID    string // openai
+	BaseURL    string // openai, e.g. http://localhost:8000/v1
+	APIKey     string // openai, optional for local servers
+	Dimensions int    // hash, DefaultDimensions when unset
+	CacheSize  int    // Embeddings kept in memory, 0 disables the cache
+}
+
+// NewEmbedder creates an embedder for the configured provider, cached when CacheSize is set
+func NewEmbedder(cfg Config) (Embedder, error) {
+	var embedder Embedder
+	switch cfg.Provider {
+	case ProviderHash:
+		embedder = NewHashEmbedder(cfg.Dimensions)
+	case ProviderOpenAI:
+		if cfg.ModelID == "" {
+			return nil, fmt.Errorf("openai embedding provider requires a model ID")
+		}
+		embedder = NewOpenAIEmbedder(cfg.BaseURL, cfg.APIKey, cfg.ModelID)
+	default:
+		return nil, fmt.Errorf("unknown embedding provider: %s (supported: hash, openai)", cfg.Provider)
+	}
+
+	if cfg.CacheSize > 0 {
+		embedder = NewCachedEmbedder(embedder, cfg.CacheSize)
+	}
+	return embedder, nil
+}
+
+// Cosine returns the cosine similarity of two vectors, 0.0 when either is zero or their
+// dimensions differ
+func Cosine(a []float64, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0.0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0.0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
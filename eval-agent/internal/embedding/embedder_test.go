@@ -0,0 +1,146 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHashEmbedder(t *testing.T) {
+	embedder := NewHashEmbedder(0)
+
+	vectors, err := embedder.Embed(context.Background(), []string{
+		"How does encryption protect data?",
+		"Encrypted data is protected because only key holders can read it.",
+		"The weather in Lisbon is sunny.",
+		"How does encryption protect data?",
+	})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if len(vectors[0]) != DefaultDimensions {
+		t.Fatalf("Expected %d dimensions, got %d", DefaultDimensions, len(vectors[0]))
+	}
+	if math.Abs(Cosine(vectors[0], vectors[3])-1.0) > 1e-9 {
+		t.Errorf("Expected identical texts to have identical embeddings")
+	}
+
+	related := Cosine(vectors[0], vectors[1])
+	unrelated := Cosine(vectors[0], vectors[2])
+	if related <= unrelated {
+		t.Errorf("Expected related texts to be more similar (%f) than unrelated ones (%f)", related, unrelated)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{"same direction", []float64{1, 2}, []float64{2, 4}, 1.0},
+		{"orthogonal", []float64{1, 0}, []float64{0, 1}, 0.0},
+		{"opposite", []float64{1, 0}, []float64{-1, 0}, -1.0},
+		{"zero vector", []float64{0, 0}, []float64{1, 0}, 0.0},
+		{"dimension mismatch", []float64{1}, []float64{1, 0}, 0.0},
+	}
+
+	for _, tt := range tests {
+		if got := Cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: expected %f, got %f", tt.name, tt.want, got)
+		}
+	}
+}
+
+// countingEmbedder records the texts it was asked to embed
+type countingEmbedder struct {
+	calls [][]string
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	e.calls = append(e.calls, texts)
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float64{float64(len(text))}
+	}
+	return vectors, nil
+}
+
+func TestCachedEmbedder(t *testing.T) {
+	inner := &countingEmbedder{}
+	embedder := NewCachedEmbedder(inner, 2)
+
+	if _, err := embedder.Embed(context.Background(), []string{"query", "answer"}); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	vectors, err := embedder.Embed(context.Background(), []string{"query", "other answer"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(inner.calls) != 2 || len(inner.calls[1]) != 1 || inner.calls[1][0] != "other answer" {
+		t.Errorf("Expected only the uncached text to be embedded, got calls %v", inner.calls)
+	}
+	if vectors[0][0] != 5 || vectors[1][0] != 12 {
+		t.Errorf("Expected vectors in input order, got %v", vectors)
+	}
+
+	// "answer" was the least recently used and got evicted
+	if embedder.Len() != 2 {
+		t.Errorf("Expected cache bounded to 2 entries, got %d", embedder.Len())
+	}
+	embedder.Embed(context.Background(), []string{"answer"})
+	if len(inner.calls) != 3 {
+		t.Errorf("Expected evicted text to be embedded again, got calls %v", inner.calls)
+	}
+}
+
+func TestOpenAIEmbedder_Embed(t *testing.T) {
+	var received embeddingsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("Expected path /v1/embeddings, got %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		// Out of order on purpose, the index maps the embedding to its input
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [0, 1]}, {"index": 0, "embedding": [1, 0]}]}`))
+	}))
+	defer server.Close()
+
+	embedder := NewOpenAIEmbedder(server.URL+"/v1/", "", "nomic-embed-text")
+
+	vectors, err := embedder.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if received.Model != "nomic-embed-text" || len(received.Input) != 2 {
+		t.Errorf("Unexpected request: %+v", received)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("Expected embeddings in input order, got %v", vectors)
+	}
+}
+
+func TestNewEmbedder(t *testing.T) {
+	if _, err := NewEmbedder(Config{Provider: "word2vec"}); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+	if _, err := NewEmbedder(Config{Provider: ProviderOpenAI}); err == nil {
+		t.Error("Expected error for openai provider without model")
+	}
+
+	embedder, err := NewEmbedder(Config{Provider: ProviderHash, CacheSize: 10})
+	if err != nil {
+		t.Fatalf("NewEmbedder failed: %v", err)
+	}
+	if _, ok := embedder.(*CachedEmbedder); !ok {
+		t.Errorf("Expected a cached embedder, got %T", embedder)
+	}
+}
@@ -19,7 +19,7 @@ type JudgeRunner interface {
 
 // Aggregator aggregates stage results into final evaluation
 type Aggregator interface {
-	Aggregate(id string, stage1 []models.StageResult, stage2 []models.StageResult) models.EvaluationResult
+	Aggregate(evalCtx models.EvaluationContext, stage1 []models.StageResult, stage2 []models.StageResult) models.EvaluationResult
 }
 
 type Executor struct {
@@ -64,12 +64,33 @@ func (e *Executor) Execute(ctx context.Context, evalCtx models.EvaluationContext
 		return result
 	}
 
-	stageEvalScore := 0.0
+	// A vetoing checker fails the answer whatever the judges would say, so they are not called
 	for _, stageEval := range stageEvalResults {
-		stageEvalScore += stageEval.Score
+		if stageEval.Veto {
+			result.Stages = append(result.Stages, stageEvalResults...)
+			result.Verdict = models.VerdictFail
+			result.VetoedBy = stageEval.Name
+			e.logger.Info().Str("stage", stageEval.Name).Msg("veto triggered, skipping judges")
+
+			return result
+		}
 	}
 
-	stageEvalAvgScore := stageEvalScore / float64(len(stageEvalResults))
+	// Only scored checkers count: skipped ones, e.g. reference metrics without a reference answer,
+	// have no score, and an errored or timed-out one must not fail the answer before the judges run
+	stageEvalScore, stageEvalWeight := 0.0, 0.0
+	for _, stageEval := range stageEvalResults {
+		if !stageEval.OK() {
+			continue
+		}
+		stageEvalScore += stageEval.Score * stageEval.EffectiveWeight()
+		stageEvalWeight += stageEval.EffectiveWeight()
+	}
+
+	stageEvalAvgScore := 1.0
+	if stageEvalWeight > 0 {
+		stageEvalAvgScore = stageEvalScore / stageEvalWeight
+	}
 
 	if stageEvalAvgScore < e.earlyExitThreshold {
 		result.Stages = append(result.Stages, stageEvalResults...)
@@ -81,7 +102,7 @@ func (e *Executor) Execute(ctx context.Context, evalCtx models.EvaluationContext
 
 	judgeEvaResults := e.judgeRunner.Run(ctx, evalCtx)
 
-	finalResult := e.aggregator.Aggregate(id, stageEvalResults, judgeEvaResults)
+	finalResult := e.aggregator.Aggregate(evalCtx, stageEvalResults, judgeEvaResults)
 	e.logger.
 		Info().
 		Str("verdict", string(finalResult.Verdict)).
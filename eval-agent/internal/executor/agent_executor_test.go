@@ -51,7 +51,7 @@ func TestExecutor_Execute_FullPipeline_Pass(t *testing.T) {
 		Confidence: 0.85,
 		Verdict:    models.VerdictPass,
 	}
-	mockAgg.EXPECT().Aggregate("test-001", precheckResults, judgeResults).Return(expectedResult)
+	mockAgg.EXPECT().Aggregate(evalCtx, precheckResults, judgeResults).Return(expectedResult)
 
 	executor := NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.2, newTestLogger())
 
@@ -104,6 +104,118 @@ func TestExecutor_Execute_EarlyExit_LowScore(t *testing.T) {
 	}
 }
 
+func TestExecutor_Execute_EarlyExit_IgnoresSkipped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockJudge := mocks.NewMockJudgeRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+
+	evalCtx := models.EvaluationContext{
+		RequestID: "test-005",
+		Query:     "What is Go?",
+		Answer:    "Go is a programming language",
+		CreatedAt: time.Now(),
+	}
+
+	// Reference metrics are skipped without a reference answer and must not drag the average down
+	precheckResults := []models.StageResult{
+		{Name: "length-checker", Score: 0.8, Status: models.StageStatusOK},
+		{Name: "token_f1-checker", Score: 0.0, Status: models.StageStatusSkipped},
+		{Name: "bleu-checker", Score: 0.0, Status: models.StageStatusSkipped},
+	}
+	judgeResults := []models.StageResult{{Name: "relevance-judge", Score: 0.9}}
+
+	mockPrecheck.EXPECT().Run(evalCtx).Return(precheckResults)
+	mockJudge.EXPECT().Run(gomock.Any(), evalCtx).Return(judgeResults)
+	mockAgg.EXPECT().Aggregate(evalCtx, precheckResults, judgeResults).Return(models.EvaluationResult{
+		ID:      "test-005",
+		Verdict: models.VerdictPass,
+	})
+
+	executor := NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.5, newTestLogger())
+
+	result := executor.Execute(context.Background(), evalCtx)
+
+	if result.Verdict != models.VerdictPass {
+		t.Errorf("expected skipped prechecks to be ignored, got verdict %s", result.Verdict)
+	}
+}
+
+func TestExecutor_Execute_EarlyExit_IgnoresFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockJudge := mocks.NewMockJudgeRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+
+	evalCtx := models.EvaluationContext{
+		RequestID: "test-005b",
+		Query:     "What is Go?",
+		Answer:    "Go is a programming language",
+		CreatedAt: time.Now(),
+	}
+
+	// An embedding timeout or a checker error says nothing about the answer, the judges must still run
+	precheckResults := []models.StageResult{
+		{Name: "length-checker", Score: 0.8, Status: models.StageStatusOK},
+		{Name: "embedding-checker", Score: 0.0, Status: models.StageStatusTimeout},
+		{Name: "semantic-checker", Score: 0.0, Status: models.StageStatusError},
+	}
+	judgeResults := []models.StageResult{{Name: "relevance-judge", Score: 0.9}}
+
+	mockPrecheck.EXPECT().Run(evalCtx).Return(precheckResults)
+	mockJudge.EXPECT().Run(gomock.Any(), evalCtx).Return(judgeResults)
+	mockAgg.EXPECT().Aggregate(evalCtx, precheckResults, judgeResults).Return(models.EvaluationResult{
+		ID:      "test-005b",
+		Verdict: models.VerdictPass,
+	})
+
+	executor := NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.5, newTestLogger())
+
+	result := executor.Execute(context.Background(), evalCtx)
+
+	if result.Verdict != models.VerdictPass {
+		t.Errorf("expected failed prechecks to be ignored, got verdict %s", result.Verdict)
+	}
+}
+
+func TestExecutor_Execute_Veto_SkipsJudges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPrecheck := mocks.NewMockPrecheckRunner(ctrl)
+	mockJudge := mocks.NewMockJudgeRunner(ctrl)
+	mockAgg := mocks.NewMockAggregator(ctrl)
+
+	evalCtx := models.EvaluationContext{
+		RequestID: "test-006",
+		Query:     "What is my account email?",
+		Answer:    "Your email is jane.doe@example.com",
+		CreatedAt: time.Now(),
+	}
+
+	// Good scores otherwise, the veto alone fails the answer without calling the judges
+	precheckResults := []models.StageResult{
+		{Name: "length-checker", Score: 1.0, Status: models.StageStatusOK},
+		{Name: "pii-checker", Score: 0.0, Status: models.StageStatusOK, Veto: true},
+	}
+	mockPrecheck.EXPECT().Run(evalCtx).Return(precheckResults)
+
+	executor := NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.2, newTestLogger())
+
+	result := executor.Execute(context.Background(), evalCtx)
+
+	if result.Verdict != models.VerdictFail || result.VetoedBy != "pii-checker" {
+		t.Errorf("expected fail vetoed by pii-checker, got %s vetoed by %q", result.Verdict, result.VetoedBy)
+	}
+	if len(result.Stages) != 2 {
+		t.Errorf("expected 2 precheck stages, got %d", len(result.Stages))
+	}
+}
+
 func TestExecutor_Execute_EmptyPrechecks_Fail(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -167,7 +279,7 @@ func TestExecutor_Execute_PassPrecheck_FailJudge(t *testing.T) {
 		Confidence: 0.48, // (0.9 * 0.3) + (0.3 * 0.7) = 0.48
 		Verdict:    models.VerdictFail,
 	}
-	mockAgg.EXPECT().Aggregate("test-004", precheckResults, judgeResults).Return(expectedResult)
+	mockAgg.EXPECT().Aggregate(evalCtx, precheckResults, judgeResults).Return(expectedResult)
 
 	executor := NewExecutor(mockPrecheck, mockJudge, mockAgg, 0.2, newTestLogger())
 
@@ -223,7 +335,7 @@ func TestExecutor_Execute_EarlyExitThreshold(t *testing.T) {
 					{Name: "judge", Score: 0.9, Reason: "test", Duration: 1 * time.Second},
 				}
 				mockJudge.EXPECT().Run(gomock.Any(), evalCtx).Return(judgeResults)
-				mockAgg.EXPECT().Aggregate("test", precheckResults, judgeResults).Return(models.EvaluationResult{
+				mockAgg.EXPECT().Aggregate(evalCtx, precheckResults, judgeResults).Return(models.EvaluationResult{
 					ID:         "test",
 					Confidence: 0.85,
 					Verdict:    models.VerdictPass,
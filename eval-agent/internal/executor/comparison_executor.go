@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type ComparerFactory interface {
+	Comparers(judgeNames []string) ([]judge.Comparer, error)
+}
+
+// ComparisonExecutor runs pairwise comparisons of two answers with the configured judges
+type ComparisonExecutor struct {
+	judges  ComparerFactory
+	timeout time.Duration
+	logger  *zerolog.Logger
+}
+
+func NewComparisonExecutor(judges ComparerFactory, logger *zerolog.Logger) *ComparisonExecutor {
+	return &ComparisonExecutor{
+		judges:  judges,
+		timeout: 30 * time.Second, // Two LLM calls per judge, one per answer order
+		logger:  logger,
+	}
+}
+
+// Execute asks every selected judge for its preference, all judges when none are selected.
+// The winner is the answer preferred by more judges, a tie when both are preferred equally.
+func (e *ComparisonExecutor) Execute(ctx context.Context, judgeNames []string, cmpCtx models.ComparisonContext) (models.ComparisonResult, error) {
+	id := cmpCtx.RequestID
+	e.logger.Info().Str("requestID", id).Msg("starting comparison")
+
+	result := models.ComparisonResult{
+		ID:     id,
+		Judges: []models.PreferenceResult{},
+	}
+
+	comparers, err := e.judges.Comparers(judgeNames)
+	if err != nil {
+		e.logger.Error().Err(err).Strs("judges", judgeNames).Msg("Judges not available for comparison")
+		return result, fmt.Errorf("%w: %v", ErrJudgeNotFound, err)
+	}
+
+	result.Judges = make([]models.PreferenceResult, len(comparers))
+	var wg sync.WaitGroup
+	for i, comparer := range comparers {
+		wg.Add(1)
+		go func(i int, c judge.Comparer) {
+			defer wg.Done()
+
+			judgeCtx, cancel := context.WithTimeout(ctx, e.timeout)
+			defer cancel()
+
+			preference := c.Compare(judgeCtx, cmpCtx)
+			if judgeCtx.Err() == context.DeadlineExceeded {
+				preference = models.PreferenceResult{
+					Name:     preference.Name,
+					Reason:   "comparison timed out after " + e.timeout.String(),
+					Status:   models.StageStatusTimeout,
+					Duration: e.timeout,
+					Usage:    preference.Usage,
+				}
+			}
+			result.Judges[i] = preference
+		}(i, comparer)
+	}
+	wg.Wait()
+
+	for _, preference := range result.Judges {
+		if preference.Usage != nil {
+			if result.Usage == nil {
+				result.Usage = &models.TokenUsage{}
+			}
+			result.Usage.Add(*preference.Usage)
+		}
+
+		if !preference.OK() {
+			continue
+		}
+		switch preference.Preference {
+		case models.PreferenceA:
+			result.WinsA++
+		case models.PreferenceB:
+			result.WinsB++
+		default:
+			result.Ties++
+		}
+	}
+
+	switch {
+	case result.WinsA+result.WinsB+result.Ties == 0:
+		// No judge stated a preference, there is no winner
+	case result.WinsA > result.WinsB:
+		result.Winner = models.PreferenceA
+	case result.WinsB > result.WinsA:
+		result.Winner = models.PreferenceB
+	default:
+		result.Winner = models.PreferenceTie
+	}
+
+	e.logger.Info().
+		Str("requestID", id).
+		Str("winner", string(result.Winner)).
+		Int("wins_a", result.WinsA).
+		Int("wins_b", result.WinsB).
+		Int("ties", result.Ties).
+		Msg("comparison complete")
+
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor/mocks"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"go.uber.org/mock/gomock"
+)
+
+func TestComparisonExecutor_Execute(t *testing.T) {
+	ok := func(preference models.Preference) models.PreferenceResult {
+		return models.PreferenceResult{
+			Preference: preference,
+			Status:     models.StageStatusOK,
+			Consistent: true,
+			Usage:      &models.TokenUsage{InputTokens: 100, OutputTokens: 10},
+		}
+	}
+	failed := models.PreferenceResult{Status: models.StageStatusError}
+
+	tests := []struct {
+		name         string
+		preferences  []models.PreferenceResult
+		expectWinner models.Preference
+		expectA      int
+		expectB      int
+		expectTies   int
+	}{
+		{
+			name:         "majority prefers B",
+			preferences:  []models.PreferenceResult{ok(models.PreferenceB), ok(models.PreferenceB), ok(models.PreferenceA)},
+			expectWinner: models.PreferenceB,
+			expectA:      1,
+			expectB:      2,
+		},
+		{
+			name:         "equal wins are a tie",
+			preferences:  []models.PreferenceResult{ok(models.PreferenceA), ok(models.PreferenceB), ok(models.PreferenceTie)},
+			expectWinner: models.PreferenceTie,
+			expectA:      1,
+			expectB:      1,
+			expectTies:   1,
+		},
+		{
+			name:         "failed judges are not counted",
+			preferences:  []models.PreferenceResult{ok(models.PreferenceA), failed},
+			expectWinner: models.PreferenceA,
+			expectA:      1,
+		},
+		{
+			name:         "no preference has no winner",
+			preferences:  []models.PreferenceResult{failed, failed},
+			expectWinner: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			cmpCtx := models.ComparisonContext{RequestID: "cmp-1", Query: "What is Go?"}
+
+			var comparers []judge.Comparer
+			for _, preference := range tt.preferences {
+				comparer := mocks.NewMockComparer(ctrl)
+				comparer.EXPECT().Compare(gomock.Any(), cmpCtx).Return(preference)
+				comparers = append(comparers, comparer)
+			}
+
+			factory := mocks.NewMockComparerFactory(ctrl)
+			factory.EXPECT().Comparers([]string{"relevance"}).Return(comparers, nil)
+
+			exec := NewComparisonExecutor(factory, testLogger())
+			result, err := exec.Execute(context.Background(), []string{"relevance"}, cmpCtx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.ID != "cmp-1" {
+				t.Errorf("expected ID cmp-1, got %s", result.ID)
+			}
+			if result.Winner != tt.expectWinner {
+				t.Errorf("expected winner %q, got %q", tt.expectWinner, result.Winner)
+			}
+			if result.WinsA != tt.expectA || result.WinsB != tt.expectB || result.Ties != tt.expectTies {
+				t.Errorf("expected %d/%d/%d, got %d/%d/%d", tt.expectA, tt.expectB, tt.expectTies, result.WinsA, result.WinsB, result.Ties)
+			}
+			if len(result.Judges) != len(tt.preferences) {
+				t.Errorf("expected %d judge results, got %d", len(tt.preferences), len(result.Judges))
+			}
+		})
+	}
+}
+
+func TestComparisonExecutor_Execute_SumsUsage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	comparer := mocks.NewMockComparer(ctrl)
+	comparer.EXPECT().Compare(gomock.Any(), gomock.Any()).Return(models.PreferenceResult{
+		Preference: models.PreferenceA,
+		Status:     models.StageStatusOK,
+		Usage:      &models.TokenUsage{InputTokens: 100, OutputTokens: 10, Cost: 0.5},
+	}).Times(2)
+
+	factory := mocks.NewMockComparerFactory(ctrl)
+	factory.EXPECT().Comparers(nil).Return([]judge.Comparer{comparer, comparer}, nil)
+
+	exec := NewComparisonExecutor(factory, testLogger())
+	result, err := exec.Execute(context.Background(), nil, models.ComparisonContext{RequestID: "cmp-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Usage == nil || result.Usage.InputTokens != 200 || result.Usage.Cost != 1.0 {
+		t.Errorf("expected usage summed across judges, got %+v", result.Usage)
+	}
+}
+
+func TestComparisonExecutor_Execute_UnknownJudge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	factory := mocks.NewMockComparerFactory(ctrl)
+	factory.EXPECT().Comparers([]string{"missing"}).Return(nil, errors.New("judge not found: missing"))
+
+	exec := NewComparisonExecutor(factory, testLogger())
+	_, err := exec.Execute(context.Background(), []string{"missing"}, models.ComparisonContext{RequestID: "cmp-1"})
+
+	if !errors.Is(err, ErrJudgeNotFound) {
+		t.Errorf("expected ErrJudgeNotFound, got %v", err)
+	}
+}
@@ -45,9 +45,14 @@ func (e *JudgeExecutor) Execute(ctx context.Context, judgeName string, threshold
 	judgeResponse := judge.Evaluate(ctx, evalCtx)
 
 	result.Stages = append(result.Stages, judgeResponse)
-	if judgeResponse.Score > threshold {
+	result.Usage = models.TotalUsage(result.Stages)
+	switch {
+	case !judgeResponse.OK():
+		// The judge didn't score the answer, its 0.0 is not a failing score
+		result.Verdict = models.VerdictReview
+	case judgeResponse.Score > threshold:
 		result.Verdict = models.VerdictPass
-	} else {
+	default:
 		result.Verdict = models.VerdictFail
 	}
 	result.Confidence = judgeResponse.Score
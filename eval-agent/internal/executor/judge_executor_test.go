@@ -92,6 +92,27 @@ func TestJudgeExecutor_Execute(t *testing.T) {
 			expectVerdict: models.VerdictFail,
 			expectScore:   0.75,
 		},
+		{
+			name:      "judge errored - review",
+			judgeName: "relevance",
+			threshold: 0.0,
+			stageResult: models.StageResult{
+				Name:     "relevance",
+				Score:    0.0,
+				Reason:   "Failed to call LLM",
+				Status:   models.StageStatusError,
+				Duration: 50 * time.Millisecond,
+			},
+			evalCtx: models.EvaluationContext{
+				RequestID: "test-005",
+				Query:     "What is Go?",
+				Answer:    "Go is a programming language.",
+				CreatedAt: time.Now(),
+			},
+			expectErr:     nil,
+			expectVerdict: models.VerdictReview,
+			expectScore:   0.0,
+		},
 		{
 			name:      "judge not found - error",
 			judgeName: "unknown-judge",
@@ -118,15 +118,15 @@ func (m *MockAggregator) EXPECT() *MockAggregatorMockRecorder {
 }
 
 // Aggregate mocks base method.
-func (m *MockAggregator) Aggregate(id string, stage1, stage2 []models.StageResult) models.EvaluationResult {
+func (m *MockAggregator) Aggregate(evalCtx models.EvaluationContext, stage1, stage2 []models.StageResult) models.EvaluationResult {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Aggregate", id, stage1, stage2)
+	ret := m.ctrl.Call(m, "Aggregate", evalCtx, stage1, stage2)
 	ret0, _ := ret[0].(models.EvaluationResult)
 	return ret0
 }
 
 // Aggregate indicates an expected call of Aggregate.
-func (mr *MockAggregatorMockRecorder) Aggregate(id, stage1, stage2 any) *gomock.Call {
+func (mr *MockAggregatorMockRecorder) Aggregate(evalCtx, stage1, stage2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Aggregate", reflect.TypeOf((*MockAggregator)(nil).Aggregate), id, stage1, stage2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Aggregate", reflect.TypeOf((*MockAggregator)(nil).Aggregate), evalCtx, stage1, stage2)
 }
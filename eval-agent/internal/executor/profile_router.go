@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"context"
+	"path"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Evaluator runs the full evaluation pipeline for a single request
+type Evaluator interface {
+	Execute(ctx context.Context, evalCtx models.EvaluationContext) models.EvaluationResult
+}
+
+// AgentPattern holds glob patterns for the agent fields, empty fields match anything
+type AgentPattern struct {
+	Name    string
+	Type    string
+	Version string
+}
+
+// Matches reports whether the agent matches every non-empty pattern
+func (p AgentPattern) Matches(agent models.Agent) bool {
+	return matchPattern(p.Name, agent.Name) &&
+		matchPattern(p.Type, agent.Type) &&
+		matchPattern(p.Version, agent.Version)
+}
+
+func matchPattern(pattern string, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
+
+// Profile is an executor configured for the agents matching its pattern
+type Profile struct {
+	Name     string
+	Pattern  AgentPattern
+	Executor Evaluator
+}
+
+// ProfileRouter picks the evaluation profile for each request based on its agent
+type ProfileRouter struct {
+	profiles       []Profile
+	defaultProfile Profile
+	logger         *zerolog.Logger
+}
+
+// NewProfileRouter creates a router. Profiles are tried in order and the first match wins,
+// requests that match none of them use the default profile.
+func NewProfileRouter(profiles []Profile, defaultProfile Profile, logger *zerolog.Logger) *ProfileRouter {
+	return &ProfileRouter{
+		profiles:       profiles,
+		defaultProfile: defaultProfile,
+		logger:         logger,
+	}
+}
+
+// Select returns the profile used for the given agent
+func (r *ProfileRouter) Select(agent models.Agent) Profile {
+	for _, profile := range r.profiles {
+		if profile.Pattern.Matches(agent) {
+			return profile
+		}
+	}
+	return r.defaultProfile
+}
+
+func (r *ProfileRouter) Execute(ctx context.Context, evalCtx models.EvaluationContext) models.EvaluationResult {
+	profile := r.Select(evalCtx.Agent)
+
+	r.logger.Debug().
+		Str("requestID", evalCtx.RequestID).
+		Str("agent_name", evalCtx.Agent.Name).
+		Str("profile", profile.Name).
+		Msg("evaluation profile selected")
+
+	result := profile.Executor.Execute(ctx, evalCtx)
+	result.Profile = profile.Name
+	return result
+}
@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// stubEvaluator returns a fixed verdict and records whether it was called
+type stubEvaluator struct {
+	called bool
+}
+
+func (s *stubEvaluator) Execute(ctx context.Context, evalCtx models.EvaluationContext) models.EvaluationResult {
+	s.called = true
+	return models.EvaluationResult{ID: evalCtx.RequestID, Verdict: models.VerdictPass}
+}
+
+func TestAgentPattern_Matches(t *testing.T) {
+	agent := models.Agent{Name: "chat-support", Type: "chat", Version: "2.1.0"}
+
+	tests := []struct {
+		name    string
+		pattern AgentPattern
+		want    bool
+	}{
+		{name: "empty pattern", pattern: AgentPattern{}, want: true},
+		{name: "exact name", pattern: AgentPattern{Name: "chat-support"}, want: true},
+		{name: "name glob", pattern: AgentPattern{Name: "chat-*"}, want: true},
+		{name: "type and version", pattern: AgentPattern{Type: "chat", Version: "2.*"}, want: true},
+		{name: "version mismatch", pattern: AgentPattern{Type: "chat", Version: "1.*"}, want: false},
+		{name: "type mismatch", pattern: AgentPattern{Type: "rag"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pattern.Matches(agent); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileRouter_Execute(t *testing.T) {
+	tests := []struct {
+		name        string
+		agent       models.Agent
+		wantProfile string
+	}{
+		{name: "first match wins", agent: models.Agent{Name: "rag-search", Type: "rag"}, wantProfile: "rag"},
+		{name: "second profile", agent: models.Agent{Name: "chat-support", Type: "chat"}, wantProfile: "chat"},
+		{name: "falls back to default", agent: models.Agent{Name: "billing", Type: "workflow"}, wantProfile: "default"},
+		{name: "missing agent uses default", agent: models.Agent{}, wantProfile: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executors := map[string]*stubEvaluator{
+				"rag":     {},
+				"chat":    {},
+				"default": {},
+			}
+
+			router := NewProfileRouter(
+				[]Profile{
+					{Name: "rag", Pattern: AgentPattern{Type: "rag"}, Executor: executors["rag"]},
+					{Name: "chat", Pattern: AgentPattern{Name: "chat-*"}, Executor: executors["chat"]},
+				},
+				Profile{Name: "default", Executor: executors["default"]},
+				newTestLogger(),
+			)
+
+			result := router.Execute(context.Background(), models.EvaluationContext{RequestID: "test", Agent: tt.agent})
+
+			if result.Profile != tt.wantProfile {
+				t.Errorf("expected profile %s, got %s", tt.wantProfile, result.Profile)
+			}
+			if result.ID != "test" {
+				t.Errorf("expected ID test, got %s", result.ID)
+			}
+			for name, exec := range executors {
+				if exec.called != (name == tt.wantProfile) {
+					t.Errorf("executor %s called=%v, expected only %s to run", name, exec.called, tt.wantProfile)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package judge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// Cache stores judge results by cache key
+type Cache interface {
+	Get(ctx context.Context, key string) (models.StageResult, bool, error)
+	Set(ctx context.Context, key string, result models.StageResult) error
+}
+
+// CachedJudge serves LLM judge results from a cache. Results are keyed by the rendered
+// prompt, model config and model ID, so editing a judge's prompt only invalidates that judge.
+type CachedJudge struct {
+	judge   *LLMJudge
+	cache   Cache
+	modelID string
+	logger  *zerolog.Logger
+}
+
+func NewCachedJudge(judge *LLMJudge, cache Cache, modelID string, logger *zerolog.Logger) *CachedJudge {
+	return &CachedJudge{
+		judge:   judge,
+		cache:   cache,
+		modelID: modelID,
+		logger:  logger,
+	}
+}
+
+// Evaluate returns the cached result when present, otherwise evaluates and caches
+// successful results. Failures are never cached so they are retried on the next run.
+func (c *CachedJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	now := time.Now()
+
+	// Nothing to cache if the prompt cannot be rendered or no LLM call would be made
+	prompt, err := c.judge.buildPrompt(evalCtx)
+	if err != nil || c.judge.missingInput(evalCtx.Context, evalCtx.ReferenceAnswer) != "" {
+		return c.judge.Evaluate(ctx, evalCtx)
+	}
+
+	key := CacheKey(prompt, c.judge.modelConfig, c.judge.rubric, c.modelID, c.judge.samples, c.judge.ensembleConfigs())
+
+	cached, found, err := c.cache.Get(ctx, key)
+	if err != nil {
+		c.logger.Warn().Err(err).Str("judge", c.judge.name).Msg("judge cache lookup failed")
+	}
+	if found {
+		cached.Name = fmt.Sprintf("%s-judge", c.judge.name)
+		cached.Duration = time.Since(now)
+		cached.Cached = true
+		cached.Usage = nil // No tokens were spent on this evaluation
+
+		c.logger.Debug().Str("judge", c.judge.name).Str("key", key).Msg("judge cache hit")
+		return cached
+	}
+
+	result, ok := c.judge.evaluate(ctx, evalCtx)
+	if ok {
+		if err := c.cache.Set(ctx, key, result); err != nil {
+			c.logger.Warn().Err(err).Str("judge", c.judge.name).Msg("failed to store judge result in cache")
+		}
+	}
+
+	return result
+}
+
+// Name returns the judge's name
+func (c *CachedJudge) Name() string {
+	return c.judge.Name()
+}
+
+// CacheKey returns the hex SHA-256 of the rendered prompt, model config and model ID.
+// The rubric is included because its weights and scale shape the cached score, and so
+// are the samples and ensemble models of sampled judges. A single sample leaves the key
+// of unsampled judges unchanged.
+func CacheKey(prompt string, modelConfig config.ModelConfig, rubric *config.Rubric, modelID string, samples int, ensemble []config.ModelConfig) string {
+	if samples <= 1 {
+		samples = 0
+	}
+
+	// json.Marshal of a struct is deterministic, so equal inputs always hash the same
+	data, _ := json.Marshal(struct {
+		ModelID  string               `json:"model_id"`
+		Model    config.ModelConfig   `json:"model"`
+		Rubric   *config.Rubric       `json:"rubric,omitempty"`
+		Samples  int                  `json:"samples,omitempty"`
+		Ensemble []config.ModelConfig `json:"ensemble,omitempty"`
+		Prompt   string               `json:"prompt"`
+	}{modelID, modelConfig, rubric, samples, ensemble, prompt})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
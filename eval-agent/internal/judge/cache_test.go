@@ -0,0 +1,113 @@
+package judge
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// memoryCache is an in-memory Cache for tests
+type memoryCache struct {
+	entries map[string]models.StageResult
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: make(map[string]models.StageResult)}
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string) (models.StageResult, bool, error) {
+	result, ok := m.entries[key]
+	return result, ok, nil
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, result models.StageResult) error {
+	m.entries[key] = result
+	return nil
+}
+
+func TestCachedJudge_ServesRepeatedEvaluationsFromCache(t *testing.T) {
+	logger := zerolog.Nop()
+	cache := newMemoryCache()
+	client := &MockLLMClient{ResponseToReturn: &llm.Response{
+		Content: `{"score": 0.9, "reason": "relevant"}`,
+		Usage:   llm.Usage{InputTokens: 100, OutputTokens: 20},
+	}}
+	evalCtx := models.EvaluationContext{Query: "What is Go?", Answer: "A language."}
+
+	cached := NewCachedJudge(newTestJudge(t, config.JudgeConfiguration{
+		Name:   "relevance",
+		Prompt: "Rate: {{.Answer}}",
+		Model:  &config.ModelConfig{MaxTokens: 256},
+	}, client), cache, "model-a", &logger)
+
+	first := cached.Evaluate(context.Background(), evalCtx)
+	if !client.WasCalled || first.Cached {
+		t.Fatalf("Expected the first evaluation to call the LLM, got %+v", first)
+	}
+	if first.Usage == nil || first.Usage.InputTokens != 100 {
+		t.Errorf("Expected the first evaluation to report its usage, got %+v", first.Usage)
+	}
+
+	client.WasCalled = false
+	second := cached.Evaluate(context.Background(), evalCtx)
+
+	if client.WasCalled {
+		t.Error("Expected the second evaluation to be served from cache")
+	}
+	if !second.Cached || second.Score != 0.9 || second.Reason != "relevant" || second.Name != "relevance-judge" {
+		t.Errorf("Unexpected cached result: %+v", second)
+	}
+	if second.Usage != nil {
+		t.Errorf("Expected a cache hit to consume no tokens, got %+v", second.Usage)
+	}
+}
+
+func TestCachedJudge_FailuresAreNotCached(t *testing.T) {
+	logger := zerolog.Nop()
+	cache := newMemoryCache()
+	client := &MockLLMClient{ErrorToReturn: errors.New("throttled")}
+
+	cached := NewCachedJudge(newTestJudge(t, config.JudgeConfiguration{
+		Name:   "relevance",
+		Prompt: "Rate: {{.Answer}}",
+		Model:  &config.ModelConfig{MaxTokens: 256},
+	}, client), cache, "model-a", &logger)
+	cached.Evaluate(context.Background(), models.EvaluationContext{Answer: "A language."})
+
+	if len(cache.entries) != 0 {
+		t.Errorf("Expected failed evaluation not to be cached, got %d entries", len(cache.entries))
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	model := config.ModelConfig{MaxTokens: 256, Temperature: 0.0}
+	base := CacheKey("Rate: A language.", model, nil, "model-a", 1, nil)
+
+	if base != CacheKey("Rate: A language.", model, nil, "model-a", 1, nil) {
+		t.Error("Expected equal inputs to produce equal keys")
+	}
+
+	if base != CacheKey("Rate: A language.", model, nil, "model-a", 0, nil) {
+		t.Error("Expected a single sample to produce the key of an unsampled judge")
+	}
+
+	changed := map[string]string{
+		"prompt":      CacheKey("Rate strictly: A language.", model, nil, "model-a", 1, nil),
+		"model ID":    CacheKey("Rate: A language.", model, nil, "model-b", 1, nil),
+		"temperature": CacheKey("Rate: A language.", config.ModelConfig{MaxTokens: 256, Temperature: 0.5}, nil, "model-a", 1, nil),
+		"rubric":      CacheKey("Rate: A language.", model, &config.Rubric{Criteria: []config.Criterion{{Name: "coverage", Weight: 1}}}, "model-a", 1, nil),
+		"max tokens":  CacheKey("Rate: A language.", config.ModelConfig{MaxTokens: 512}, nil, "model-a", 1, nil),
+		"samples":     CacheKey("Rate: A language.", model, nil, "model-a", 3, nil),
+		"ensemble":    CacheKey("Rate: A language.", model, nil, "model-a", 2, []config.ModelConfig{{ModelID: "model-b"}}),
+	}
+	for name, key := range changed {
+		if key == base {
+			t.Errorf("Expected a different %s to change the key", name)
+		}
+	}
+}
@@ -3,7 +3,7 @@ package judge
 import (
 	"context"
 
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/bedrock"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 )
 
@@ -15,6 +15,6 @@ type Judge interface {
 // LLMClient is an interface for invoking LLM models
 // This allows mocking in tests without making real API calls
 type LLMClient interface {
-	InvokeModel(ctx context.Context, request bedrock.ClaudeRequest) (*bedrock.ClaudeResponse, error)
-	InvokeModelWithRetry(ctx context.Context, request bedrock.ClaudeRequest) (*bedrock.ClaudeResponse, error)
+	InvokeModel(ctx context.Context, request llm.Request) (*llm.Response, error)
+	InvokeModelWithRetry(ctx context.Context, request llm.Request) (*llm.Response, error)
 }
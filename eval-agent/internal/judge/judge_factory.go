@@ -2,6 +2,7 @@ package judge
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
 	"github.com/rs/zerolog"
@@ -13,8 +14,8 @@ type JudgeFactory struct {
 	judges map[string]Judge
 }
 
-// NewJudgeFactory creates a factory with judges loaded from configuration.
-func NewJudgeFactory(llmClient LLMClient, logger *zerolog.Logger) *JudgeFactory {
+// NewJudgeFactory creates a factory with judges loaded from configuration and built by the pool.
+func NewJudgeFactory(judgePool *JudgePool, logger *zerolog.Logger) *JudgeFactory {
 	// Load judges config
 	judgesConfig, err := config.LoadJudgesConfig()
 	if err != nil {
@@ -25,7 +26,6 @@ func NewJudgeFactory(llmClient LLMClient, logger *zerolog.Logger) *JudgeFactory
 	}
 
 	// Build judges from config
-	judgePool := NewJudgePool(llmClient, logger)
 	judgesList, err := judgePool.BuildFromConfig(judgesConfig)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to build judges from config, factory will be empty")
@@ -55,3 +55,29 @@ func (f *JudgeFactory) Get(judgeName string) (Judge, error) {
 
 	return judge, nil
 }
+
+// Comparers returns the judges that can compare two answers, in name order. All of them
+// when no names are given, otherwise an error if a name is unknown.
+func (f *JudgeFactory) Comparers(judgeNames []string) ([]Comparer, error) {
+	if len(judgeNames) == 0 {
+		for name := range f.judges {
+			judgeNames = append(judgeNames, name)
+		}
+		slices.Sort(judgeNames)
+	}
+
+	comparers := make([]Comparer, 0, len(judgeNames))
+	for _, name := range judgeNames {
+		judge, err := f.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("judge not found: %s", name)
+		}
+		comparer, ok := judge.(Comparer)
+		if !ok {
+			return nil, fmt.Errorf("judge %s does not support pairwise comparison", name)
+		}
+		comparers = append(comparers, comparer)
+	}
+
+	return comparers, nil
+}
@@ -3,25 +3,41 @@ package judge
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"text/template"
 	"time"
 
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/bedrock"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
 
 // LLMJudge is a generic judge implementation that uses LLM with configurable prompts.
 type LLMJudge struct {
-	name           string
-	promptTemplate *template.Template
-	modelConfig    config.ModelConfig
+	name            string
+	description     string
+	promptTemplate  *template.Template
+	pairwiseTmpl    *template.Template
+	modelConfig     config.ModelConfig
 	requiresContext bool
-	llmClient      LLMClient
-	logger         *zerolog.Logger
+	requiresRef     bool
+	rubric          *config.Rubric
+	llmClient       LLMClient
+	price           *config.ModelPrice // Price of the judge's model, nil reports tokens without cost
+	samples         int                // Self-consistency samples, 1 is a single call
+	ensemble        []judgeModel       // Models the samples rotate through, empty uses the judge's model
+	logger          *zerolog.Logger
+}
+
+// judgeModel is a model a judge calls, with its settings and price
+type judgeModel struct {
+	client LLMClient
+	config config.ModelConfig
+	price  *config.ModelPrice
 }
 
 func NewLLMJudge(
@@ -34,37 +50,68 @@ func NewLLMJudge(
 		return nil, fmt.Errorf("failed to parse prompt template for judge %s: %w", judgeCfg.Name, err)
 	}
 
+	pairwisePrompt := judgeCfg.PairwisePrompt
+	if pairwisePrompt == "" {
+		pairwisePrompt = defaultPairwisePrompt
+	}
+	pairwiseTmpl, err := template.New(judgeCfg.Name + "-pairwise").Parse(pairwisePrompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pairwise prompt template for judge %s: %w", judgeCfg.Name, err)
+	}
+
 	if judgeCfg.Model == nil {
 		return nil, fmt.Errorf("judge %s has nil model config (should be populated by config loader)", judgeCfg.Name)
 	}
 
+	// Ensemble models share the judge's client until the pool resolves their own
+	ensemble := make([]judgeModel, 0, len(judgeCfg.Ensemble))
+	for _, member := range judgeCfg.Ensemble {
+		ensemble = append(ensemble, judgeModel{client: llmClient, config: member})
+	}
+
 	return &LLMJudge{
 		name:            judgeCfg.Name,
+		description:     judgeCfg.Description,
 		promptTemplate:  tmpl,
+		pairwiseTmpl:    pairwiseTmpl,
 		modelConfig:     *judgeCfg.Model,
 		requiresContext: judgeCfg.RequiresContext,
+		requiresRef:     judgeCfg.RequiresReference,
+		rubric:          judgeCfg.Rubric,
 		llmClient:       llmClient,
+		samples:         max(1, judgeCfg.Samples, len(judgeCfg.Ensemble)),
+		ensemble:        ensemble,
 		logger:          logger,
 	}, nil
 }
 
 // Evaluate executes the judge evaluation
 func (j *LLMJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContext) models.StageResult {
+	result, _ := j.evaluate(ctx, evalCtx)
+	return result
+}
+
+// evaluate executes the judge evaluation and reports whether the result is a score
+// returned by the model, as opposed to a failure to obtain one
+func (j *LLMJudge) evaluate(ctx context.Context, evalCtx models.EvaluationContext) (models.StageResult, bool) {
 	now := time.Now()
 
+	// The status stays error until the model returns a valid score
 	result := models.StageResult{
-		Name:  fmt.Sprintf("%s-judge", j.name),
-		Score: 0.0,
+		Name:   fmt.Sprintf("%s-judge", j.name),
+		Score:  0.0,
+		Status: models.StageStatusError,
 	}
 
-	// Check if context is required but missing
-	if j.requiresContext && evalCtx.Context == "" {
+	// Check if context or a reference answer is required but missing
+	if missing := j.missingInput(evalCtx.Context, evalCtx.ReferenceAnswer); missing != "" {
 		j.logger.Warn().
 			Str("judge", j.name).
-			Msg("judge requires context but none provided")
-		result.Reason = "Context required but not provided"
+			Msgf("judge requires %s but none provided", missing)
+		result.Reason = fmt.Sprintf("%s required but not provided", capitalize(missing))
+		result.Status = models.StageStatusSkipped
 		result.Duration = time.Since(now)
-		return result
+		return result, false
 	}
 
 	// Build prompt from template
@@ -76,80 +123,299 @@ func (j *LLMJudge) Evaluate(ctx context.Context, evalCtx models.EvaluationContex
 			Msg("failed to build prompt from template")
 		result.Reason = fmt.Sprintf("Failed to build prompt: %v", err)
 		result.Duration = time.Since(now)
-		return result
+		return result, false
 	}
 
-	// Call LLM
-	var resp *bedrock.ClaudeResponse
-	if j.modelConfig.Retry {
-		resp, err = j.llmClient.InvokeModelWithRetry(ctx, bedrock.ClaudeRequest{
-			Prompt:      prompt,
-			MaxTokens:   j.modelConfig.MaxTokens,
-			Temperature: j.modelConfig.Temperature,
-		})
+	ok := false
+	if j.samples > 1 {
+		result, ok = j.sample(ctx, prompt)
 	} else {
-		resp, err = j.llmClient.InvokeModel(ctx, bedrock.ClaudeRequest{
-			Prompt:      prompt,
-			MaxTokens:   j.modelConfig.MaxTokens,
-			Temperature: j.modelConfig.Temperature,
-		})
+		result, ok = j.score(ctx, prompt, j.model(0))
+	}
+	result.Name = fmt.Sprintf("%s-judge", j.name)
+	result.Duration = time.Since(now)
+
+	if ok {
+		j.logger.Debug().
+			Str("judge", j.name).
+			Float64("score", result.Score).
+			Int("samples", j.samples).
+			Dur("duration", result.Duration).
+			Msg("judge completed")
 	}
 
+	return result, ok
+}
+
+// missingInput returns the input the judge requires but didn't get, empty when it can run
+func (j *LLMJudge) missingInput(context string, referenceAnswer string) string {
+	switch {
+	case j.requiresContext && context == "":
+		return "context"
+	case j.requiresRef && referenceAnswer == "":
+		return "reference answer"
+	default:
+		return ""
+	}
+}
+
+// capitalize upper-cases the first letter of an ASCII message
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// model returns the model of the i-th sample
+func (j *LLMJudge) model(i int) judgeModel {
+	if len(j.ensemble) == 0 {
+		return judgeModel{client: j.llmClient, config: j.modelConfig, price: j.price}
+	}
+	return j.ensemble[i%len(j.ensemble)]
+}
+
+// ensembleConfigs returns the configs of the ensemble models, with their resolved model IDs
+func (j *LLMJudge) ensembleConfigs() []config.ModelConfig {
+	if len(j.ensemble) == 0 {
+		return nil
+	}
+
+	configs := make([]config.ModelConfig, len(j.ensemble))
+	for i, member := range j.ensemble {
+		configs[i] = member.config
+	}
+	return configs
+}
+
+// score calls the model once and turns its response into a stage result
+func (j *LLMJudge) score(ctx context.Context, prompt string, model judgeModel) (models.StageResult, bool) {
+	result := models.StageResult{
+		Score:  0.0,
+		Status: models.StageStatusError,
+	}
+
+	// Call LLM
+	resp, err := j.invoke(ctx, model, prompt)
 	if err != nil {
 		j.logger.Error().
 			Err(err).
 			Str("judge", j.name).
 			Msg("LLM call failed")
 		result.Reason = "Failed to call LLM"
-		result.Duration = time.Since(now)
-		return result
+		return result, false
 	}
+	addUsage(&result, model, resp)
 
 	// Parse LLM response
-	var llmResponse judgeResponse
-	if err := json.Unmarshal([]byte(resp.Content), &llmResponse); err != nil {
+	llmResponse, err := parseJudgeResponse(resp.Content)
+	if err != nil && model.config.Repair {
+		j.logger.Warn().
+			Err(err).
+			Str("judge", j.name).
+			Msg("failed to parse LLM response, asking the model to repair it")
+		llmResponse, err = j.repair(ctx, model, prompt, resp.Content, &result)
+	}
+	if err != nil {
 		j.logger.Error().
 			Err(err).
 			Str("judge", j.name).
 			Str("content", resp.Content).
 			Msg("failed to deserialize LLM response")
 		result.Reason = "Failed to deserialize LLM response"
-		result.Duration = time.Since(now)
-		return result
+		return result, false
+	}
+
+	// Rubric judges are scored from their criteria
+	if j.rubric != nil {
+		subScores, score, err := j.scoreRubric(llmResponse.Criteria)
+		if err != nil {
+			j.logger.Error().
+				Err(err).
+				Str("judge", j.name).
+				Msg("LLM returned invalid rubric scores")
+			result.Reason = fmt.Sprintf("Invalid LLM response: %v", err)
+			return result, false
+		}
+
+		result.Score = score
+		result.Status = models.StageStatusOK
+		result.SubScores = subScores
+		result.Reason = llmResponse.Reason
+		return result, true
 	}
 
 	// Validate response
-	if llmResponse.Score == 0.0 && llmResponse.Reason == "" {
+	score := float64(llmResponse.Score)
+	if score == 0.0 && llmResponse.Reason == "" {
 		j.logger.Error().
 			Str("judge", j.name).
 			Msg("LLM returned empty score and reason")
 		result.Reason = "Invalid LLM response: missing score and reason"
-		result.Duration = time.Since(now)
-		return result
+		return result, false
 	}
 
-	if llmResponse.Score < 0.0 || llmResponse.Score > 1.0 {
+	if score < 0.0 || score > 1.0 {
 		j.logger.Error().
 			Str("judge", j.name).
-			Float64("score", llmResponse.Score).
+			Float64("score", score).
 			Msg("LLM returned invalid score")
-		result.Reason = fmt.Sprintf("Invalid LLM response: score %f out of range [0.0, 1.0]", llmResponse.Score)
-		result.Duration = time.Since(now)
-		return result
+		result.Reason = fmt.Sprintf("Invalid LLM response: score %f out of range [0.0, 1.0]", score)
+		return result, false
 	}
 
 	// Success
-	result.Score = llmResponse.Score
+	result.Score = score
+	result.Status = models.StageStatusOK
 	result.Reason = llmResponse.Reason
-	result.Duration = time.Since(now)
+	return result, true
+}
 
-	j.logger.Debug().
-		Str("judge", j.name).
-		Float64("score", result.Score).
-		Dur("duration", result.Duration).
-		Msg("judge completed")
+// sample scores the prompt with several concurrent calls, rotating through the ensemble.
+// The score is the mean of the samples that returned one and their spread is reported
+// in the result's sample stats.
+func (j *LLMJudge) sample(ctx context.Context, prompt string) (models.StageResult, bool) {
+	samples := make([]models.StageResult, j.samples)
+	scored := make([]bool, j.samples)
 
-	return result
+	var wg sync.WaitGroup
+	for i := range j.samples {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			samples[i], scored[i] = j.score(ctx, prompt, j.model(i))
+		}(i)
+	}
+	wg.Wait()
+
+	result := models.StageResult{
+		Score:  0.0,
+		Status: models.StageStatusError,
+	}
+	stats := &models.SampleStats{}
+	var ok []models.StageResult
+
+	for i, sample := range samples {
+		if sample.Usage != nil {
+			if result.Usage == nil {
+				result.Usage = &models.TokenUsage{}
+			}
+			result.Usage.Add(*sample.Usage)
+		}
+
+		if !scored[i] {
+			stats.Failed++
+			continue
+		}
+		ok = append(ok, sample)
+		stats.Scores = append(stats.Scores, sample.Score)
+	}
+
+	if len(ok) == 0 {
+		result.Reason = fmt.Sprintf("All %d samples failed: %s", j.samples, samples[0].Reason)
+		return result, false
+	}
+
+	stats.Count = len(ok)
+	for _, score := range stats.Scores {
+		stats.Mean += score
+	}
+	stats.Mean /= float64(stats.Count)
+	for _, score := range stats.Scores {
+		stats.Variance += (score - stats.Mean) * (score - stats.Mean)
+	}
+	stats.Variance /= float64(stats.Count)
+	stats.Agreement = max(0.0, 1.0-2.0*math.Sqrt(stats.Variance))
+
+	// The reason of the sample closest to the mean stands for the others
+	closest := ok[0]
+	for _, sample := range ok[1:] {
+		if math.Abs(sample.Score-stats.Mean) < math.Abs(closest.Score-stats.Mean) {
+			closest = sample
+		}
+	}
+
+	result.Score = stats.Mean
+	result.Status = models.StageStatusOK
+	result.Reason = closest.Reason
+	result.SubScores = meanSubScores(ok, closest.SubScores)
+	result.Samples = stats
+
+	if stats.Failed > 0 {
+		j.logger.Warn().
+			Str("judge", j.name).
+			Int("failed", stats.Failed).
+			Int("samples", j.samples).
+			Msg("some judge samples failed")
+	}
+
+	return result, true
+}
+
+// meanSubScores averages the rubric sub-scores of the samples per criterion, keeping
+// the reasons of the representative sample
+func meanSubScores(samples []models.StageResult, representative []models.SubScore) []models.SubScore {
+	if len(representative) == 0 {
+		return nil
+	}
+
+	subScores := make([]models.SubScore, len(representative))
+	copy(subScores, representative)
+	for i := range subScores {
+		score, rawScore := 0.0, 0.0
+		for _, sample := range samples {
+			score += sample.SubScores[i].Score
+			rawScore += sample.SubScores[i].RawScore
+		}
+		subScores[i].Score = score / float64(len(samples))
+		subScores[i].RawScore = rawScore / float64(len(samples))
+	}
+	return subScores
+}
+
+// invoke sends the prompt to the model, with retries if it is configured for them
+func (j *LLMJudge) invoke(ctx context.Context, model judgeModel, prompt string) (*llm.Response, error) {
+	request := llm.Request{
+		Prompt:      prompt,
+		MaxTokens:   model.config.MaxTokens,
+		Temperature: model.config.Temperature,
+	}
+
+	if model.config.Retry {
+		return model.client.InvokeModelWithRetry(ctx, request)
+	}
+	return model.client.InvokeModel(ctx, request)
+}
+
+// repair re-prompts the model once with its unparseable output and asks for the JSON alone
+func (j *LLMJudge) repair(ctx context.Context, model judgeModel, prompt string, content string, result *models.StageResult) (judgeResponse, error) {
+	resp, err := j.invoke(ctx, model, fmt.Sprintf(repairPrompt, prompt, content))
+	if err != nil {
+		return judgeResponse{}, fmt.Errorf("repair call failed: %w", err)
+	}
+	addUsage(result, model, resp)
+	return parseJudgeResponse(resp.Content)
+}
+
+// addUsage adds the tokens of an LLM call to the result, priced when the model has a price.
+// Calls are counted whether or not their response could be used.
+func addUsage(result *models.StageResult, model judgeModel, resp *llm.Response) {
+	if resp.Usage.InputTokens == 0 && resp.Usage.OutputTokens == 0 {
+		return
+	}
+
+	usage := models.TokenUsage{
+		InputTokens:  resp.Usage.InputTokens,
+		OutputTokens: resp.Usage.OutputTokens,
+	}
+	if model.price != nil {
+		usage.Cost = model.price.Cost(usage.InputTokens, usage.OutputTokens)
+	}
+
+	if result.Usage == nil {
+		result.Usage = &models.TokenUsage{}
+	}
+	result.Usage.Add(usage)
 }
 
 // Name returns the judge's name
@@ -157,10 +423,49 @@ func (j *LLMJudge) Name() string {
 	return j.name
 }
 
-// buildPrompt executes the template with the evaluation context
+// scoreRubric validates the criterion scores against the rubric and returns them
+// normalized to 0.0-1.0 along with their weighted mean
+func (j *LLMJudge) scoreRubric(criteria []criterionResponse) ([]models.SubScore, float64, error) {
+	byName := make(map[string]criterionResponse, len(criteria))
+	for _, criterion := range criteria {
+		byName[criterion.Name] = criterion
+	}
+
+	scale := j.rubric.Scale
+	subScores := make([]models.SubScore, 0, len(j.rubric.Criteria))
+	weightedSum, totalWeight := 0.0, 0.0
+
+	for _, criterion := range j.rubric.Criteria {
+		response, ok := byName[criterion.Name]
+		if !ok {
+			return nil, 0.0, fmt.Errorf("missing score for criterion %s", criterion.Name)
+		}
+
+		rawScore := float64(response.Score)
+		if rawScore < scale.Min || rawScore > scale.Max {
+			return nil, 0.0, fmt.Errorf("criterion %s score %f out of range [%g, %g]", criterion.Name, rawScore, scale.Min, scale.Max)
+		}
+
+		normalized := (rawScore - scale.Min) / (scale.Max - scale.Min)
+		subScores = append(subScores, models.SubScore{
+			Name:     criterion.Name,
+			Score:    normalized,
+			RawScore: rawScore,
+			Weight:   criterion.Weight,
+			Reason:   response.Reason,
+		})
+
+		weightedSum += normalized * criterion.Weight
+		totalWeight += criterion.Weight
+	}
+
+	return subScores, weightedSum / totalWeight, nil
+}
+
+// buildPrompt executes the template with the evaluation context and rubric
 func (j *LLMJudge) buildPrompt(evalCtx models.EvaluationContext) (string, error) {
 	var buf bytes.Buffer
-	if err := j.promptTemplate.Execute(&buf, evalCtx); err != nil {
+	if err := j.promptTemplate.Execute(&buf, promptData{EvaluationContext: evalCtx, Rubric: j.rubric}); err != nil {
 		return "", fmt.Errorf("template execution failed: %w", err)
 	}
 	return buf.String(), nil
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
+	"sync"
 	"testing"
 
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/bedrock"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
@@ -91,7 +94,7 @@ func TestLLMJudge_Evaluate_Success(t *testing.T) {
 	}
 
 	mockClient := &MockLLMClient{
-		ResponseToReturn: &bedrock.ClaudeResponse{
+		ResponseToReturn: &llm.Response{
 			Content: `{"score": 0.85, "reason": "Good match"}`,
 		},
 	}
@@ -147,6 +150,44 @@ func TestLLMJudge_Evaluate_MissingContext(t *testing.T) {
 	if result.Reason != "Context required but not provided" {
 		t.Errorf("Expected context error, got '%s'", result.Reason)
 	}
+	if result.Status != models.StageStatusSkipped {
+		t.Errorf("Expected status skipped, got %q", result.Status)
+	}
+}
+
+func TestLLMJudge_Evaluate_ReferenceAnswer(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:              "correctness",
+		Prompt:            "Reference: {{.ReferenceAnswer}}\nAnswer: {{.Answer}}",
+		RequiresReference: true,
+		Model: &config.ModelConfig{
+			MaxTokens: 256,
+		},
+	}
+
+	client := &MockLLMClient{ResponseToReturn: &llm.Response{Content: `{"score": 0.9, "reason": "matches"}`}}
+	judge, err := NewLLMJudge(cfg, client, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Query: "test", Answer: "Paris"})
+	if result.Status != models.StageStatusSkipped || result.Reason != "Reference answer required but not provided" {
+		t.Errorf("Expected skip without reference, got %s (%s)", result.Status, result.Reason)
+	}
+	if client.WasCalled {
+		t.Error("Expected no LLM call without reference")
+	}
+
+	result = judge.Evaluate(context.Background(), models.EvaluationContext{Query: "test", Answer: "Paris", ReferenceAnswer: "Paris, France"})
+	if result.Status != models.StageStatusOK || result.Score != 0.9 {
+		t.Errorf("Expected scored result with reference, got %s %f (%s)", result.Status, result.Score, result.Reason)
+	}
+	if client.LastRequest == nil || !contains(client.LastRequest.Prompt, "Reference: Paris, France") {
+		t.Errorf("Expected reference answer in prompt, got %+v", client.LastRequest)
+	}
 }
 
 func TestLLMJudge_Evaluate_TemplateExecutionFails(t *testing.T) {
@@ -222,7 +263,7 @@ func TestLLMJudge_Evaluate_WithRetry(t *testing.T) {
 	}
 
 	mockClient := &MockLLMClient{
-		ResponseToReturn: &bedrock.ClaudeResponse{
+		ResponseToReturn: &llm.Response{
 			Content: `{"score": 0.9, "reason": "test"}`,
 		},
 	}
@@ -254,7 +295,7 @@ func TestLLMJudge_Evaluate_InvalidJSON(t *testing.T) {
 	}
 
 	mockClient := &MockLLMClient{
-		ResponseToReturn: &bedrock.ClaudeResponse{
+		ResponseToReturn: &llm.Response{
 			Content: `not valid json`,
 		},
 	}
@@ -287,7 +328,7 @@ func TestLLMJudge_Evaluate_EmptyScoreAndReason(t *testing.T) {
 	}
 
 	mockClient := &MockLLMClient{
-		ResponseToReturn: &bedrock.ClaudeResponse{
+		ResponseToReturn: &llm.Response{
 			Content: `{"score": 0.0, "reason": ""}`,
 		},
 	}
@@ -330,7 +371,7 @@ func TestLLMJudge_Evaluate_ScoreOutOfRange(t *testing.T) {
 			}
 
 			mockClient := &MockLLMClient{
-				ResponseToReturn: &bedrock.ClaudeResponse{
+				ResponseToReturn: &llm.Response{
 					Content: fmt.Sprintf(`{"score": %f, "reason": "test"}`, tt.score),
 				},
 			}
@@ -353,15 +394,297 @@ func TestLLMJudge_Evaluate_ScoreOutOfRange(t *testing.T) {
 	}
 }
 
+func TestLLMJudge_Evaluate_Usage(t *testing.T) {
+	tests := []struct {
+		name      string
+		price     *config.ModelPrice
+		wantUsage *models.TokenUsage
+	}{
+		{
+			name:      "priced model",
+			price:     &config.ModelPrice{InputPerMillion: 3.00, OutputPerMillion: 15.00},
+			wantUsage: &models.TokenUsage{InputTokens: 1000, OutputTokens: 200, Cost: 0.006},
+		},
+		{
+			name:      "model without price",
+			price:     nil,
+			wantUsage: &models.TokenUsage{InputTokens: 1000, OutputTokens: 200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			client := &MockLLMClient{ResponseToReturn: &llm.Response{
+				Content: `{"score": 0.9, "reason": "relevant"}`,
+				Usage:   llm.Usage{InputTokens: 1000, OutputTokens: 200},
+			}}
+
+			judge, _ := NewLLMJudge(config.JudgeConfiguration{
+				Name:   "test",
+				Prompt: "Score: {{.Answer}}",
+				Model:  &config.ModelConfig{MaxTokens: 256},
+			}, client, &logger)
+			judge.price = tt.price
+
+			result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+			if result.Usage == nil {
+				t.Fatal("Expected usage on the result")
+			}
+			if result.Usage.InputTokens != tt.wantUsage.InputTokens || result.Usage.OutputTokens != tt.wantUsage.OutputTokens {
+				t.Errorf("Expected tokens %d/%d, got %d/%d", tt.wantUsage.InputTokens, tt.wantUsage.OutputTokens, result.Usage.InputTokens, result.Usage.OutputTokens)
+			}
+			if math.Abs(result.Usage.Cost-tt.wantUsage.Cost) > 1e-12 {
+				t.Errorf("Expected cost %f, got %f", tt.wantUsage.Cost, result.Usage.Cost)
+			}
+		})
+	}
+}
+
+func TestLLMJudge_Evaluate_NoUsageReported(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &MockLLMClient{ResponseToReturn: &llm.Response{Content: `{"score": 0.9, "reason": "relevant"}`}}
+
+	judge, _ := NewLLMJudge(config.JudgeConfiguration{
+		Name:   "test",
+		Prompt: "Score: {{.Answer}}",
+		Model:  &config.ModelConfig{MaxTokens: 256},
+	}, client, &logger)
+
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Usage != nil {
+		t.Errorf("Expected no usage when the provider reports none, got %+v", result.Usage)
+	}
+}
+
+func TestLLMJudge_Evaluate_Rubric(t *testing.T) {
+	mockClient := &MockLLMClient{
+		ResponseToReturn: &llm.Response{
+			Content: `{"criteria": [
+				{"name": "coverage", "score": 5, "reason": "Both questions answered"},
+				{"name": "depth", "score": 2, "reason": "Second answer is thin"}
+			], "reason": "Complete but shallow"}`,
+		},
+	}
+
+	judge := newTestJudge(t, rubricJudgeConfig(), mockClient)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Query: "What and why?", Answer: "A and B"})
+
+	// coverage 5/5 = 1.0 (weight 2), depth 2/5 = 0.4 (weight 1) -> (2.0 + 0.4) / 3 = 0.8
+	if math.Abs(result.Score-0.8) > 1e-9 {
+		t.Errorf("Expected score=0.8, got %f (%s)", result.Score, result.Reason)
+	}
+	if result.Reason != "Complete but shallow" {
+		t.Errorf("Expected overall reason, got '%s'", result.Reason)
+	}
+	if len(result.SubScores) != 2 {
+		t.Fatalf("Expected 2 sub-scores, got %d", len(result.SubScores))
+	}
+
+	depth := result.SubScores[1]
+	if depth.Name != "depth" || depth.RawScore != 2 || math.Abs(depth.Score-0.4) > 1e-9 || depth.Reason != "Second answer is thin" {
+		t.Errorf("Unexpected depth sub-score: %+v", depth)
+	}
+
+	// The rubric is available to the prompt template
+	if !strings.Contains(mockClient.LastRequest.Prompt, "- coverage: All parts addressed") {
+		t.Errorf("Expected criteria in prompt, got: %s", mockClient.LastRequest.Prompt)
+	}
+}
+
+func TestLLMJudge_Evaluate_RubricInvalidResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing criterion",
+			content: `{"criteria": [{"name": "coverage", "score": 4}]}`,
+			wantErr: "missing score for criterion depth",
+		},
+		{
+			name:    "score outside scale",
+			content: `{"criteria": [{"name": "coverage", "score": 7}, {"name": "depth", "score": 3}]}`,
+			wantErr: "criterion coverage score 7.000000 out of range [0, 5]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockLLMClient{ResponseToReturn: &llm.Response{Content: tt.content}}
+
+			result := newTestJudge(t, rubricJudgeConfig(), mockClient).Evaluate(context.Background(), models.EvaluationContext{Answer: "A"})
+
+			if result.Score != 0.0 || len(result.SubScores) != 0 {
+				t.Errorf("Expected a failed result, got %+v", result)
+			}
+			if !contains(result.Reason, tt.wantErr) {
+				t.Errorf("Expected reason containing %q, got '%s'", tt.wantErr, result.Reason)
+			}
+		})
+	}
+}
+
+func TestLLMJudge_Evaluate_Samples(t *testing.T) {
+	client := &queueLLMClient{responses: []string{
+		`{"score": 0.6, "reason": "low"}`,
+		`{"score": 0.8, "reason": "middle"}`,
+		`{"score": 1.0, "reason": "high"}`,
+	}}
+
+	judge := newTestJudge(t, sampledJudgeConfig(3), client)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Status != models.StageStatusOK {
+		t.Fatalf("Expected status ok, got %s (%s)", result.Status, result.Reason)
+	}
+	if math.Abs(result.Score-0.8) > 1e-9 {
+		t.Errorf("Expected the mean score 0.8, got %f", result.Score)
+	}
+	if result.Reason != "middle" {
+		t.Errorf("Expected the reason of the sample closest to the mean, got %q", result.Reason)
+	}
+
+	stats := result.Samples
+	if stats == nil {
+		t.Fatal("Expected sample stats")
+	}
+	// Variance of 0.6, 0.8 and 1.0 is 0.08 / 3, its standard deviation ~0.163
+	if stats.Count != 3 || stats.Failed != 0 || len(stats.Scores) != 3 {
+		t.Errorf("Expected 3 scored samples, got %+v", stats)
+	}
+	if math.Abs(stats.Variance-0.08/3) > 1e-9 {
+		t.Errorf("Expected variance %f, got %f", 0.08/3, stats.Variance)
+	}
+	if math.Abs(stats.Agreement-(1-2*math.Sqrt(0.08/3))) > 1e-9 {
+		t.Errorf("Expected agreement %f, got %f", 1-2*math.Sqrt(0.08/3), stats.Agreement)
+	}
+
+	// Every sample is billed
+	if result.Usage == nil || result.Usage.InputTokens != 30 || result.Usage.OutputTokens != 15 {
+		t.Errorf("Expected usage summed across samples, got %+v", result.Usage)
+	}
+	if client.calls != 3 {
+		t.Errorf("Expected 3 LLM calls, got %d", client.calls)
+	}
+}
+
+func TestLLMJudge_Evaluate_SamplesPartialFailure(t *testing.T) {
+	client := &queueLLMClient{responses: []string{
+		`{"score": 0.5, "reason": "half"}`,
+		`not a score`,
+		`{"score": 0.7, "reason": "most"}`,
+	}}
+
+	judge := newTestJudge(t, sampledJudgeConfig(3), client)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Status != models.StageStatusOK {
+		t.Fatalf("Expected status ok with one failed sample, got %s (%s)", result.Status, result.Reason)
+	}
+	if math.Abs(result.Score-0.6) > 1e-9 {
+		t.Errorf("Expected the mean of the scored samples 0.6, got %f", result.Score)
+	}
+	if result.Samples.Count != 2 || result.Samples.Failed != 1 {
+		t.Errorf("Expected 2 scored and 1 failed sample, got %+v", result.Samples)
+	}
+}
+
+func TestLLMJudge_Evaluate_SamplesAllFailed(t *testing.T) {
+	client := &queueLLMClient{err: errors.New("throttled")}
+
+	judge := newTestJudge(t, sampledJudgeConfig(3), client)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Status != models.StageStatusError {
+		t.Errorf("Expected status error, got %s", result.Status)
+	}
+	if result.Score != 0.0 || result.Samples != nil {
+		t.Errorf("Expected no score and no sample stats, got %f %+v", result.Score, result.Samples)
+	}
+	if !contains(result.Reason, "All 3 samples failed") {
+		t.Errorf("Expected all samples failed reason, got %q", result.Reason)
+	}
+}
+
+func TestLLMJudge_Evaluate_SamplesRubric(t *testing.T) {
+	client := &queueLLMClient{responses: []string{
+		`{"criteria": [{"name": "coverage", "score": 5}, {"name": "depth", "score": 1}], "reason": "shallow"}`,
+		`{"criteria": [{"name": "coverage", "score": 3}, {"name": "depth", "score": 3}], "reason": "partial"}`,
+	}}
+
+	cfg := rubricJudgeConfig()
+	cfg.Samples = 2
+	judge := newTestJudge(t, cfg, client)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Query: "What and why?", Answer: "A and B"})
+
+	if result.Status != models.StageStatusOK {
+		t.Fatalf("Expected status ok, got %s (%s)", result.Status, result.Reason)
+	}
+	if len(result.SubScores) != 2 {
+		t.Fatalf("Expected 2 sub-scores, got %d", len(result.SubScores))
+	}
+
+	// coverage (5 + 3) / 2 = 4 -> 0.8, depth (1 + 3) / 2 = 2 -> 0.4
+	coverage, depth := result.SubScores[0], result.SubScores[1]
+	if coverage.RawScore != 4 || math.Abs(coverage.Score-0.8) > 1e-9 {
+		t.Errorf("Expected averaged coverage 4 (0.8), got %+v", coverage)
+	}
+	if depth.RawScore != 2 || math.Abs(depth.Score-0.4) > 1e-9 {
+		t.Errorf("Expected averaged depth 2 (0.4), got %+v", depth)
+	}
+}
+
+// newTestJudge builds an LLM judge from cfg, failing the test on a config error
+func newTestJudge(t *testing.T, cfg config.JudgeConfiguration, client LLMClient) *LLMJudge {
+	t.Helper()
+	logger := zerolog.Nop()
+
+	judge, err := NewLLMJudge(cfg, client, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+	return judge
+}
+
+// rubricJudgeConfig is a rubric judge of two weighted criteria on a 0-5 scale
+func rubricJudgeConfig() config.JudgeConfiguration {
+	return config.JudgeConfiguration{
+		Name:   "completeness",
+		Prompt: "Query: {{.Query}}\n{{range .Rubric.Criteria}}- {{.Name}}: {{.Description}}\n{{end}}",
+		Model:  &config.ModelConfig{MaxTokens: 512},
+		Rubric: &config.Rubric{
+			Scale: config.Scale{Min: 0, Max: 5},
+			Criteria: []config.Criterion{
+				{Name: "coverage", Description: "All parts addressed", Weight: 2.0},
+				{Name: "depth", Description: "Enough detail", Weight: 1.0},
+			},
+		},
+	}
+}
+
+// sampledJudgeConfig is a judge sampled the given number of times
+func sampledJudgeConfig(samples int) config.JudgeConfiguration {
+	return config.JudgeConfiguration{
+		Name:    "faithfulness",
+		Prompt:  "Score: {{.Answer}}",
+		Model:   &config.ModelConfig{MaxTokens: 256, Temperature: 0.7},
+		Samples: samples,
+	}
+}
+
 // MockLLMClient for testing
 type MockLLMClient struct {
-	ResponseToReturn *bedrock.ClaudeResponse
+	ResponseToReturn *llm.Response
 	ErrorToReturn    error
 	WasCalled        bool
-	LastRequest      *bedrock.ClaudeRequest
+	LastRequest      *llm.Request
 }
 
-func (m *MockLLMClient) InvokeModel(ctx context.Context, request bedrock.ClaudeRequest) (*bedrock.ClaudeResponse, error) {
+func (m *MockLLMClient) InvokeModel(ctx context.Context, request llm.Request) (*llm.Response, error) {
 	m.WasCalled = true
 	m.LastRequest = &request
 	if m.ErrorToReturn != nil {
@@ -370,7 +693,7 @@ func (m *MockLLMClient) InvokeModel(ctx context.Context, request bedrock.ClaudeR
 	return m.ResponseToReturn, nil
 }
 
-func (m *MockLLMClient) InvokeModelWithRetry(ctx context.Context, request bedrock.ClaudeRequest) (*bedrock.ClaudeResponse, error) {
+func (m *MockLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.Request) (*llm.Response, error) {
 	m.WasCalled = true
 	m.LastRequest = &request
 	if m.ErrorToReturn != nil {
@@ -379,6 +702,30 @@ func (m *MockLLMClient) InvokeModelWithRetry(ctx context.Context, request bedroc
 	return m.ResponseToReturn, nil
 }
 
+// queueLLMClient returns its responses in call order and is safe for concurrent samples
+type queueLLMClient struct {
+	mu        sync.Mutex
+	responses []string
+	err       error
+	calls     int
+}
+
+func (c *queueLLMClient) InvokeModel(ctx context.Context, request llm.Request) (*llm.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	content := c.responses[c.calls-1]
+	return &llm.Response{Content: content, Usage: llm.Usage{InputTokens: 10, OutputTokens: 5}}, nil
+}
+
+func (c *queueLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.Request) (*llm.Response, error) {
+	return c.InvokeModel(ctx, request)
+}
+
 // Helper
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && containsHelper(s, substr)
@@ -0,0 +1,179 @@
+package judge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// Comparer is a judge that can state which of two answers it prefers
+type Comparer interface {
+	Name() string
+	Compare(ctx context.Context, cmpCtx models.ComparisonContext) models.PreferenceResult
+}
+
+// Compare asks the model which answer is better, once in the given order and once with
+// the answers swapped. LLM judges tend to favour a position, so a preference only counts
+// when both orders agree; otherwise the judge reports a tie. Self-consistency samples are
+// not used, each order is a single call to the judge's model.
+func (j *LLMJudge) Compare(ctx context.Context, cmpCtx models.ComparisonContext) models.PreferenceResult {
+	now := time.Now()
+
+	// The status stays error until both orders returned a preference
+	result := models.PreferenceResult{
+		Name:   fmt.Sprintf("%s-judge", j.name),
+		Status: models.StageStatusError,
+	}
+
+	if missing := j.missingInput(cmpCtx.Context, cmpCtx.ReferenceAnswer); missing != "" {
+		result.Reason = fmt.Sprintf("%s required but not provided", capitalize(missing))
+		result.Status = models.StageStatusSkipped
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	orders := []models.ComparisonContext{cmpCtx, cmpCtx.Swapped()}
+	preferences := make([]models.Preference, len(orders))
+	reasons := make([]string, len(orders))
+	errs := make([]error, len(orders))
+	usages := make([]models.StageResult, len(orders))
+
+	var wg sync.WaitGroup
+	for i, order := range orders {
+		wg.Add(1)
+		go func(i int, order models.ComparisonContext) {
+			defer wg.Done()
+			preferences[i], reasons[i], errs[i] = j.prefer(ctx, order, &usages[i])
+		}(i, order)
+	}
+	wg.Wait()
+
+	for _, usage := range usages {
+		if usage.Usage == nil {
+			continue
+		}
+		if result.Usage == nil {
+			result.Usage = &models.TokenUsage{}
+		}
+		result.Usage.Add(*usage.Usage)
+	}
+	result.Duration = time.Since(now)
+
+	for _, err := range errs {
+		if err != nil {
+			j.logger.Error().
+				Err(err).
+				Str("judge", j.name).
+				Msg("pairwise comparison failed")
+			result.Reason = fmt.Sprintf("Pairwise comparison failed: %v", err)
+			return result
+		}
+	}
+
+	// The swapped order saw B in position A, map its preference back
+	forward, backward := preferences[0], swapPreference(preferences[1])
+	result.Orders = []models.Preference{forward, backward}
+	result.Status = models.StageStatusOK
+	result.Consistent = forward == backward
+
+	if result.Consistent {
+		result.Preference = forward
+		result.Reason = reasons[0]
+	} else {
+		result.Preference = models.PreferenceTie
+		result.Reason = fmt.Sprintf("Preference changed with the answer order (%s, then %s when swapped): %s", forward, backward, reasons[0])
+	}
+
+	j.logger.Debug().
+		Str("judge", j.name).
+		Str("preference", string(result.Preference)).
+		Bool("consistent", result.Consistent).
+		Dur("duration", result.Duration).
+		Msg("pairwise comparison completed")
+
+	return result
+}
+
+// prefer asks the model for its preference between the answers in the given order.
+// Token usage is added to usage whether or not the call succeeds.
+func (j *LLMJudge) prefer(ctx context.Context, cmpCtx models.ComparisonContext, usage *models.StageResult) (models.Preference, string, error) {
+	prompt, err := j.buildPairwisePrompt(cmpCtx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build prompt: %w", err)
+	}
+
+	model := j.model(0)
+	resp, err := j.invoke(ctx, model, prompt)
+	if err != nil {
+		return "", "", fmt.Errorf("LLM call failed: %w", err)
+	}
+	addUsage(usage, model, resp)
+
+	response, err := parseJudgeResponse(resp.Content)
+	if err != nil && model.config.Repair {
+		response, err = j.repair(ctx, model, prompt, resp.Content, usage)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to deserialize LLM response: %w", err)
+	}
+
+	preference, err := parsePreference(response.Preference)
+	if err != nil {
+		return "", "", err
+	}
+	return preference, response.Reason, nil
+}
+
+// buildPairwisePrompt executes the pairwise template with the comparison and the judge's criterion
+func (j *LLMJudge) buildPairwisePrompt(cmpCtx models.ComparisonContext) (string, error) {
+	criterion := j.description
+	if criterion == "" {
+		criterion = j.name
+	}
+
+	var buf bytes.Buffer
+	if err := j.pairwiseTmpl.Execute(&buf, pairwiseData{ComparisonContext: cmpCtx, Criterion: criterion}); err != nil {
+		return "", fmt.Errorf("template execution failed: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// parsePreference accepts "A", "B" and "tie" in any case, along with "Answer A" and
+// the usual spellings of a tie
+func parsePreference(preference string) (models.Preference, error) {
+	normalized := strings.ToLower(strings.TrimSpace(preference))
+	normalized = strings.TrimPrefix(normalized, "answer ")
+
+	switch normalized {
+	case "a":
+		return models.PreferenceA, nil
+	case "b":
+		return models.PreferenceB, nil
+	case "tie", "equal", "none", "neither", "both":
+		return models.PreferenceTie, nil
+	default:
+		return "", fmt.Errorf("invalid preference %q (must be A, B or tie)", preference)
+	}
+}
+
+// swapPreference maps a preference given with the answers swapped back to the original order
+func swapPreference(preference models.Preference) models.Preference {
+	switch preference {
+	case models.PreferenceA:
+		return models.PreferenceB
+	case models.PreferenceB:
+		return models.PreferenceA
+	default:
+		return preference
+	}
+}
+
+// Compare delegates to the wrapped judge, comparisons are not cached
+func (c *CachedJudge) Compare(ctx context.Context, cmpCtx models.ComparisonContext) models.PreferenceResult {
+	return c.judge.Compare(ctx, cmpCtx)
+}
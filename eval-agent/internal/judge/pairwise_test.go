@@ -0,0 +1,179 @@
+package judge
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// pairwiseJudgeConfig is a judge whose description states its criterion
+func pairwiseJudgeConfig() config.JudgeConfiguration {
+	return config.JudgeConfiguration{
+		Name:        "relevance",
+		Description: "Evaluates if the answer addresses the query",
+		Prompt:      "Score: {{.Answer}}",
+		Model:       &config.ModelConfig{MaxTokens: 256},
+	}
+}
+
+func TestLLMJudge_Compare(t *testing.T) {
+	tests := []struct {
+		name             string
+		forward          string // Response when the baseline answer is in position A
+		swapped          string // Response when the answers are swapped
+		expectPreference models.Preference
+		expectConsistent bool
+	}{
+		{
+			name:             "both orders prefer the same answer",
+			forward:          `{"preference": "B", "reason": "more relevant"}`,
+			swapped:          `{"preference": "A", "reason": "more relevant"}`,
+			expectPreference: models.PreferenceB,
+			expectConsistent: true,
+		},
+		{
+			name:             "position bias is a tie",
+			forward:          `{"preference": "A", "reason": "first is better"}`,
+			swapped:          `{"preference": "A", "reason": "first is better"}`,
+			expectPreference: models.PreferenceTie,
+			expectConsistent: false,
+		},
+		{
+			name:             "consistent tie",
+			forward:          `{"preference": "tie", "reason": "equivalent"}`,
+			swapped:          `{"preference": "Tie", "reason": "equivalent"}`,
+			expectPreference: models.PreferenceTie,
+			expectConsistent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &pairwiseLLMClient{firstAnswer: map[string]string{
+				"Go is a language.":                    tt.forward,
+				"Go is a compiled language by Google.": tt.swapped,
+			}}
+
+			judge := newTestJudge(t, pairwiseJudgeConfig(), client)
+			result := judge.Compare(context.Background(), models.ComparisonContext{
+				Query: "What is Go?",
+				A:     models.Candidate{Answer: "Go is a language."},
+				B:     models.Candidate{Answer: "Go is a compiled language by Google."},
+			})
+
+			if result.Status != models.StageStatusOK {
+				t.Fatalf("Expected status ok, got %s (%s)", result.Status, result.Reason)
+			}
+			if result.Preference != tt.expectPreference {
+				t.Errorf("Expected preference %q, got %q", tt.expectPreference, result.Preference)
+			}
+			if result.Consistent != tt.expectConsistent {
+				t.Errorf("Expected consistent=%v, got %v", tt.expectConsistent, result.Consistent)
+			}
+			if result.Name != "relevance-judge" || len(result.Orders) != 2 {
+				t.Errorf("Unexpected result: %+v", result)
+			}
+			if result.Usage == nil || result.Usage.InputTokens != 20 {
+				t.Errorf("Expected usage of both orders, got %+v", result.Usage)
+			}
+
+			// The default prompt states the judge's criterion
+			if !strings.Contains(client.prompts[0], "Evaluates if the answer addresses the query") {
+				t.Errorf("Expected the judge description in the prompt, got %q", client.prompts[0])
+			}
+		})
+	}
+}
+
+func TestLLMJudge_Compare_InvalidPreference(t *testing.T) {
+	client := &pairwiseLLMClient{firstAnswer: map[string]string{
+		"first":  `{"preference": "A", "reason": "ok"}`,
+		"second": `{"preference": "maybe", "reason": "unsure"}`,
+	}}
+
+	judge := newTestJudge(t, pairwiseJudgeConfig(), client)
+	result := judge.Compare(context.Background(), models.ComparisonContext{
+		Query: "q",
+		A:     models.Candidate{Answer: "first"},
+		B:     models.Candidate{Answer: "second"},
+	})
+
+	if result.Status != models.StageStatusError || result.Preference != "" {
+		t.Errorf("Expected an error without preference, got %s %q", result.Status, result.Preference)
+	}
+	if !contains(result.Reason, "invalid preference") {
+		t.Errorf("Expected invalid preference reason, got %q", result.Reason)
+	}
+}
+
+func TestLLMJudge_Compare_MissingContext(t *testing.T) {
+	logger := zerolog.Nop()
+	judge, err := NewLLMJudge(config.JudgeConfiguration{
+		Name:            "faithfulness",
+		Prompt:          "Context: {{.Context}}",
+		Model:           &config.ModelConfig{},
+		RequiresContext: true,
+	}, &pairwiseLLMClient{}, &logger)
+	if err != nil {
+		t.Fatalf("NewLLMJudge failed: %v", err)
+	}
+
+	result := judge.Compare(context.Background(), models.ComparisonContext{Query: "q"})
+	if result.Status != models.StageStatusSkipped {
+		t.Errorf("Expected status skipped, got %s", result.Status)
+	}
+}
+
+func TestParsePreference(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    models.Preference
+		wantErr bool
+	}{
+		{"A", models.PreferenceA, false},
+		{" b ", models.PreferenceB, false},
+		{"Answer A", models.PreferenceA, false},
+		{"TIE", models.PreferenceTie, false},
+		{"equal", models.PreferenceTie, false},
+		{"", "", true},
+		{"C", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePreference(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parsePreference(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// pairwiseLLMClient answers by the answer shown in position A, so both orders of a
+// comparison can be scripted whatever order the calls arrive in
+type pairwiseLLMClient struct {
+	mu          sync.Mutex
+	firstAnswer map[string]string
+	prompts     []string
+}
+
+func (c *pairwiseLLMClient) InvokeModel(ctx context.Context, request llm.Request) (*llm.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prompts = append(c.prompts, request.Prompt)
+	for answer, content := range c.firstAnswer {
+		if strings.Contains(request.Prompt, "Answer A: "+answer+"\n") {
+			return &llm.Response{Content: content, Usage: llm.Usage{InputTokens: 10}}, nil
+		}
+	}
+	return &llm.Response{Content: "{}"}, nil
+}
+
+func (c *pairwiseLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.Request) (*llm.Response, error) {
+	return c.InvokeModel(ctx, request)
+}
@@ -0,0 +1,135 @@
+package judge
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// flexibleFloat accepts both JSON numbers and numeric strings such as "0.8"
+type flexibleFloat float64
+
+func (f *flexibleFloat) UnmarshalJSON(data []byte) error {
+	var number float64
+	if err := json.Unmarshal(data, &number); err == nil {
+		*f = flexibleFloat(number)
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("score must be a number, got %s", data)
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		return fmt.Errorf("score must be a number, got %q", text)
+	}
+
+	*f = flexibleFloat(number)
+	return nil
+}
+
+// parseJudgeResponse extracts the judge response from the model output. Besides raw JSON it
+// accepts JSON inside markdown code fences and JSON surrounded by prose, the first candidate
+// that decodes wins.
+func parseJudgeResponse(content string) (judgeResponse, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return judgeResponse{}, errors.New("empty response")
+	}
+
+	candidates := append(fencedBlocks(content), jsonObjects(content)...)
+	if len(candidates) == 0 {
+		return judgeResponse{}, errors.New("no JSON object found in response")
+	}
+
+	var firstErr error
+	for _, candidate := range candidates {
+		var response judgeResponse
+		err := json.Unmarshal([]byte(candidate), &response)
+		if err == nil {
+			return response, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return judgeResponse{}, firstErr
+}
+
+// fencedBlocks returns the contents of markdown code fences (```json ... ``` or ``` ... ```)
+func fencedBlocks(content string) []string {
+	var blocks []string
+
+	for {
+		start := strings.Index(content, "```")
+		if start == -1 {
+			return blocks
+		}
+		rest := content[start+3:]
+
+		// Skip the language tag of the opening fence
+		if newline := strings.IndexByte(rest, '\n'); newline != -1 {
+			rest = rest[newline+1:]
+		}
+
+		end := strings.Index(rest, "```")
+		if end == -1 {
+			return blocks
+		}
+
+		blocks = append(blocks, strings.TrimSpace(rest[:end]))
+		content = rest[end+3:]
+	}
+}
+
+// jsonObjects returns every top-level balanced {...} span in order of appearance,
+// braces inside JSON strings are ignored
+func jsonObjects(content string) []string {
+	var objects []string
+
+	depth, start := 0, -1
+	inString, escaped := false, false
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			if depth > 0 {
+				inString = true
+			}
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				objects = append(objects, content[start:i+1])
+			}
+		}
+	}
+
+	return objects
+}
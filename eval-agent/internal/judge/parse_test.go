@@ -0,0 +1,182 @@
+package judge
+
+import (
+	"context"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestParseJudgeResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantScore float64
+		wantErr   bool
+	}{
+		{"raw json", `{"score": 0.8, "reason": "good"}`, 0.8, false},
+		{"surrounding whitespace", "\n  {\"score\": 0.8, \"reason\": \"good\"}\n", 0.8, false},
+		{"json fence", "```json\n{\"score\": 0.7, \"reason\": \"ok\"}\n```", 0.7, false},
+		{"plain fence", "```\n{\"score\": 0.6, \"reason\": \"ok\"}\n```", 0.6, false},
+		{"prose around object", `Here is my evaluation: {"score": 0.5, "reason": "partly {right}"} Hope this helps.`, 0.5, false},
+		{"first object that decodes", `Scale is {0 to 1}. {"score": 0.4, "reason": "meh"}`, 0.4, false},
+		{"string score", `{"score": "0.9", "reason": "good"}`, 0.9, false},
+		{"string score with spaces", `{"score": " 0.3 ", "reason": "weak"}`, 0.3, false},
+		{"non numeric string score", `{"score": "high", "reason": "good"}`, 0, true},
+		{"no json", `The answer is relevant.`, 0, true},
+		{"empty", "   ", 0, true},
+		{"unterminated object", `{"score": 0.8, "reason": "good"`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := parseJudgeResponse(tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got response %+v", response)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if float64(response.Score) != tt.wantScore {
+				t.Errorf("Expected score=%f, got %f", tt.wantScore, float64(response.Score))
+			}
+		})
+	}
+}
+
+func TestParseJudgeResponse_RubricCriteria(t *testing.T) {
+	content := "```json\n" + `{"reason": "fine", "criteria": [{"name": "coverage", "score": "4", "reason": "most parts"}]}` + "\n```"
+
+	response, err := parseJudgeResponse(content)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(response.Criteria) != 1 || float64(response.Criteria[0].Score) != 4 {
+		t.Errorf("Expected coverage criterion with score 4, got %+v", response.Criteria)
+	}
+}
+
+func TestLLMJudge_Evaluate_FencedResponse(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:   "test",
+		Prompt: "Score: {{.Answer}}",
+		Model:  &config.ModelConfig{MaxTokens: 256},
+	}
+
+	mockClient := &MockLLMClient{
+		ResponseToReturn: &llm.Response{
+			Content: "Sure! Here is the evaluation:\n```json\n{\"score\": \"0.85\", \"reason\": \"Good match\"}\n```",
+		},
+	}
+
+	judge, _ := NewLLMJudge(cfg, mockClient, &logger)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Score != 0.85 {
+		t.Errorf("Expected score=0.85, got %f", result.Score)
+	}
+	if result.Status != models.StageStatusOK {
+		t.Errorf("Expected status ok, got %q", result.Status)
+	}
+}
+
+func TestLLMJudge_Evaluate_ParseFailureStatus(t *testing.T) {
+	logger := zerolog.Nop()
+
+	cfg := config.JudgeConfiguration{
+		Name:   "test",
+		Prompt: "Score: {{.Answer}}",
+		Model:  &config.ModelConfig{MaxTokens: 256},
+	}
+
+	client := &scriptedLLMClient{responses: []string{`I think it is a 0.8`}}
+
+	judge, _ := NewLLMJudge(cfg, client, &logger)
+	result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if result.Status != models.StageStatusError {
+		t.Errorf("Expected status error, got %q", result.Status)
+	}
+	if result.Reason != "Failed to deserialize LLM response" {
+		t.Errorf("Expected deserialization error, got '%s'", result.Reason)
+	}
+	if len(client.prompts) != 1 {
+		t.Errorf("Expected no repair call without repair enabled, got %d calls", len(client.prompts))
+	}
+}
+
+func TestLLMJudge_Evaluate_Repair(t *testing.T) {
+	tests := []struct {
+		name       string
+		responses  []string
+		wantScore  float64
+		wantStatus models.StageStatus
+	}{
+		{
+			name:       "repaired",
+			responses:  []string{`The score is 0.8 because it is relevant`, `{"score": 0.8, "reason": "relevant"}`},
+			wantScore:  0.8,
+			wantStatus: models.StageStatusOK,
+		},
+		{
+			name:       "repair also invalid",
+			responses:  []string{`0.8`, `still 0.8`},
+			wantScore:  0.0,
+			wantStatus: models.StageStatusError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+
+			cfg := config.JudgeConfiguration{
+				Name:   "test",
+				Prompt: "Score: {{.Answer}}",
+				Model:  &config.ModelConfig{MaxTokens: 256, Repair: true},
+			}
+
+			client := &scriptedLLMClient{responses: tt.responses}
+
+			judge, _ := NewLLMJudge(cfg, client, &logger)
+			result := judge.Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+			if result.Score != tt.wantScore {
+				t.Errorf("Expected score=%f, got %f", tt.wantScore, result.Score)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("Expected status %q, got %q", tt.wantStatus, result.Status)
+			}
+			if len(client.prompts) != 2 {
+				t.Fatalf("Expected exactly one repair call, got %d calls", len(client.prompts))
+			}
+			if !contains(client.prompts[1], "Score: test") || !contains(client.prompts[1], tt.responses[0]) {
+				t.Errorf("Expected repair prompt to include the original prompt and response, got %q", client.prompts[1])
+			}
+		})
+	}
+}
+
+// scriptedLLMClient returns its responses in order and records the prompts it received
+type scriptedLLMClient struct {
+	responses []string
+	prompts   []string
+}
+
+func (c *scriptedLLMClient) InvokeModel(ctx context.Context, request llm.Request) (*llm.Response, error) {
+	c.prompts = append(c.prompts, request.Prompt)
+	content := c.responses[len(c.prompts)-1]
+	return &llm.Response{Content: content}, nil
+}
+
+func (c *scriptedLLMClient) InvokeModelWithRetry(ctx context.Context, request llm.Request) (*llm.Response, error) {
+	return c.InvokeModel(ctx, request)
+}
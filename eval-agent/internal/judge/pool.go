@@ -10,9 +10,18 @@ import (
 // JudgePool builds and manages a collection of judges from configuration
 type JudgePool struct {
 	llmClient LLMClient
+	clients   ClientFunc
+	pricing   *config.PricingConfig
+	cache     Cache
+	modelID   string
 	logger    *zerolog.Logger
 }
 
+// ClientFunc returns the client for the provider and model selected in a judge's model config,
+// and the resolved model ID. Empty values select the default provider and model. Implementations
+// are expected to share one client per distinct provider and model.
+type ClientFunc func(provider string, modelID string) (LLMClient, string, error)
+
 // NewJudgePool creates a new judge pool builder
 func NewJudgePool(llmClient LLMClient, logger *zerolog.Logger) *JudgePool {
 	return &JudgePool{
@@ -21,6 +30,28 @@ func NewJudgePool(llmClient LLMClient, logger *zerolog.Logger) *JudgePool {
 	}
 }
 
+// WithClients makes the pool resolve the client of every judge from its model config.
+// Without it all judges use the pool's client and may not select a provider or model.
+func (p *JudgePool) WithClients(clients ClientFunc) *JudgePool {
+	p.clients = clients
+	return p
+}
+
+// WithPricing makes judges report the estimated cost of their token usage, priced by model ID
+func (p *JudgePool) WithPricing(pricing *config.PricingConfig) *JudgePool {
+	p.pricing = pricing
+	return p
+}
+
+// WithCache makes the pool wrap every judge with a CachedJudge. The model ID is part of
+// the cache key so switching models does not serve stale results, judges resolved through
+// WithClients use the model ID of their own client instead.
+func (p *JudgePool) WithCache(cache Cache, modelID string) *JudgePool {
+	p.cache = cache
+	p.modelID = modelID
+	return p
+}
+
 func (p *JudgePool) BuildFromConfig(cfg *config.JudgesConfig) ([]Judge, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("judges config is nil")
@@ -37,20 +68,45 @@ func (p *JudgePool) BuildFromConfig(cfg *config.JudgesConfig) ([]Judge, error) {
 			continue
 		}
 
+		llmClient, modelID, err := p.client(judgeCfg.Model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for judge %s: %w", judgeCfg.Name, err)
+		}
+
 		// Create LLM judge
-		judge, err := NewLLMJudge(judgeCfg, p.llmClient, p.logger)
+		judge, err := NewLLMJudge(judgeCfg, llmClient, p.logger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create judge %s: %w", judgeCfg.Name, err)
 		}
+		judge.price = p.price(modelID)
 
-		judges = append(judges, judge)
+		// Every ensemble model resolves its own client and price
+		for i := range judge.ensemble {
+			member := &judge.ensemble[i]
+			member.client, member.config.ModelID, err = p.client(&member.config)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create client for ensemble model %d of judge %s: %w", i, judgeCfg.Name, err)
+			}
+			member.price = p.price(member.config.ModelID)
+		}
+
+		if p.cache != nil {
+			judges = append(judges, NewCachedJudge(judge, p.cache, modelID, p.logger))
+		} else {
+			judges = append(judges, judge)
+		}
 
 		p.logger.Info().
 			Str("judge", judgeCfg.Name).
+			Str("provider", judgeCfg.Model.Provider).
+			Str("model", modelID).
 			Int("max_tokens", judgeCfg.Model.MaxTokens).
 			Float64("temperature", judgeCfg.Model.Temperature).
 			Bool("retry", judgeCfg.Model.Retry).
+			Int("samples", judge.samples).
+			Int("ensemble", len(judge.ensemble)).
 			Bool("requires_context", judgeCfg.RequiresContext).
+			Bool("cached", p.cache != nil).
 			Msg("judge created successfully")
 	}
 
@@ -64,3 +120,28 @@ func (p *JudgePool) BuildFromConfig(cfg *config.JudgesConfig) ([]Judge, error) {
 
 	return judges, nil
 }
+
+// client returns the LLM client of a model config and the model ID used in its cache key
+func (p *JudgePool) client(modelCfg *config.ModelConfig) (LLMClient, string, error) {
+	var provider, modelID string
+	if modelCfg != nil {
+		provider, modelID = modelCfg.Provider, modelCfg.ModelID
+	}
+
+	if p.clients == nil {
+		if provider != "" || modelID != "" {
+			return nil, "", fmt.Errorf("judge selects provider %q and model %q but the pool has no client factory", provider, modelID)
+		}
+		return p.llmClient, p.modelID, nil
+	}
+
+	return p.clients(provider, modelID)
+}
+
+// price returns the price of a model, nil when it is not in the pricing config
+func (p *JudgePool) price(modelID string) *config.ModelPrice {
+	if price, ok := p.pricing.Price(modelID); ok {
+		return &price
+	}
+	return nil
+}
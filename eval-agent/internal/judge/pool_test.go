@@ -1,9 +1,13 @@
 package judge
 
 import (
+	"context"
+	"math"
 	"testing"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 	"github.com/rs/zerolog"
 )
 
@@ -199,3 +203,209 @@ func TestJudgePool_BuildFromConfig_InvalidJudge(t *testing.T) {
 		t.Errorf("Expected error to mention 'bad-judge', got: %v", err)
 	}
 }
+
+func TestJudgePool_BuildFromConfig_Clients(t *testing.T) {
+	logger := zerolog.Nop()
+	defaultClient := &MockLLMClient{ResponseToReturn: &llm.Response{Content: `{"score": 0.5, "reason": "default"}`}}
+	smallClient := &MockLLMClient{ResponseToReturn: &llm.Response{Content: `{"score": 0.9, "reason": "small"}`}}
+
+	var requested []string
+	clients := func(provider string, modelID string) (LLMClient, string, error) {
+		requested = append(requested, provider+"/"+modelID)
+		if modelID == "small" {
+			return smallClient, modelID, nil
+		}
+		return defaultClient, "large", nil
+	}
+
+	pool := NewJudgePool(nil, &logger).WithClients(clients)
+
+	cfg := &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{
+				{Name: "faithfulness", Enabled: true, Prompt: "Score: {{.Answer}}", Model: &config.ModelConfig{MaxTokens: 256}},
+				{Name: "coherence", Enabled: true, Prompt: "Score: {{.Answer}}", Model: &config.ModelConfig{MaxTokens: 256, Provider: llm.ProviderBedrock, ModelID: "small"}},
+			},
+		},
+	}
+
+	judges, err := pool.BuildFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+
+	faithfulness := judges[0].Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+	coherence := judges[1].Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if faithfulness.Reason != "default" {
+		t.Errorf("Expected faithfulness to use the default model, got reason %q", faithfulness.Reason)
+	}
+	if coherence.Reason != "small" {
+		t.Errorf("Expected coherence to use the small model, got reason %q", coherence.Reason)
+	}
+	if len(requested) != 2 || requested[0] != "/" || requested[1] != "bedrock/small" {
+		t.Errorf("Expected clients for the default and bedrock/small models, got %v", requested)
+	}
+}
+
+func TestJudgePool_BuildFromConfig_CacheKeyUsesJudgeModel(t *testing.T) {
+	logger := zerolog.Nop()
+	cache := newMemoryCache()
+	client := &MockLLMClient{ResponseToReturn: &llm.Response{Content: `{"score": 0.9, "reason": "ok"}`}}
+
+	clients := func(provider string, modelID string) (LLMClient, string, error) {
+		return client, modelID, nil
+	}
+
+	build := func(modelID string) Judge {
+		pool := NewJudgePool(nil, &logger).WithClients(clients).WithCache(cache, "default")
+		judges, err := pool.BuildFromConfig(&config.JudgesConfig{
+			Judges: config.Judges{
+				Evaluators: []config.JudgeConfiguration{
+					{Name: "coherence", Enabled: true, Prompt: "Score: {{.Answer}}", Model: &config.ModelConfig{ModelID: modelID}},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("BuildFromConfig failed: %v", err)
+		}
+		return judges[0]
+	}
+
+	evalCtx := models.EvaluationContext{Answer: "test"}
+	build("small").Evaluate(context.Background(), evalCtx)
+	large := build("large").Evaluate(context.Background(), evalCtx)
+	small := build("small").Evaluate(context.Background(), evalCtx)
+
+	// The second model misses the cache, the third call hits the first model's entry
+	if large.Cached || !small.Cached {
+		t.Errorf("Expected only the repeated small model evaluation to be cached, got large=%v small=%v", large.Cached, small.Cached)
+	}
+	if len(cache.entries) != 2 {
+		t.Errorf("Expected one cache entry per model, got %d", len(cache.entries))
+	}
+}
+
+func TestJudgePool_BuildFromConfig_NoClientFactory(t *testing.T) {
+	logger := zerolog.Nop()
+
+	pool := NewJudgePool(&MockLLMClient{}, &logger)
+
+	cfg := &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{
+				{Name: "coherence", Enabled: true, Prompt: "Score: {{.Answer}}", Model: &config.ModelConfig{Provider: llm.ProviderOpenAI}},
+			},
+		},
+	}
+
+	_, err := pool.BuildFromConfig(cfg)
+	if err == nil || !contains(err.Error(), "no client factory") {
+		t.Errorf("Expected missing client factory error, got: %v", err)
+	}
+}
+
+func TestJudgePool_BuildFromConfig_Pricing(t *testing.T) {
+	logger := zerolog.Nop()
+	client := &MockLLMClient{ResponseToReturn: &llm.Response{
+		Content: `{"score": 0.9, "reason": "ok"}`,
+		Usage:   llm.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000},
+	}}
+
+	clients := func(provider string, modelID string) (LLMClient, string, error) {
+		return client, modelID, nil
+	}
+	pricing := &config.PricingConfig{
+		Pricing: config.Pricing{
+			Models: map[string]config.ModelPrice{"small": {InputPerMillion: 1.0, OutputPerMillion: 2.0}},
+		},
+	}
+
+	pool := NewJudgePool(nil, &logger).WithClients(clients).WithPricing(pricing)
+
+	cfg := &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{
+				{Name: "coherence", Enabled: true, Prompt: "Score: {{.Answer}}", Model: &config.ModelConfig{ModelID: "small"}},
+				{Name: "relevance", Enabled: true, Prompt: "Score: {{.Answer}}", Model: &config.ModelConfig{ModelID: "unpriced"}},
+			},
+		},
+	}
+
+	judges, err := pool.BuildFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+
+	coherence := judges[0].Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+	relevance := judges[1].Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if coherence.Usage == nil || coherence.Usage.Cost != 3.0 {
+		t.Errorf("Expected coherence to cost 3.0, got %+v", coherence.Usage)
+	}
+	if relevance.Usage == nil || relevance.Usage.Cost != 0.0 {
+		t.Errorf("Expected relevance to report tokens without cost, got %+v", relevance.Usage)
+	}
+}
+
+func TestJudgePool_BuildFromConfig_Ensemble(t *testing.T) {
+	logger := zerolog.Nop()
+	sonnet := &MockLLMClient{ResponseToReturn: &llm.Response{
+		Content: `{"score": 1.0, "reason": "sonnet"}`,
+		Usage:   llm.Usage{InputTokens: 1_000_000},
+	}}
+	haiku := &MockLLMClient{ResponseToReturn: &llm.Response{
+		Content: `{"score": 0.6, "reason": "haiku"}`,
+		Usage:   llm.Usage{InputTokens: 1_000_000},
+	}}
+
+	clients := func(provider string, modelID string) (LLMClient, string, error) {
+		if modelID == "haiku" {
+			return haiku, modelID, nil
+		}
+		return sonnet, "sonnet", nil
+	}
+	pricing := &config.PricingConfig{
+		Pricing: config.Pricing{
+			Models: map[string]config.ModelPrice{
+				"sonnet": {InputPerMillion: 3.0},
+				"haiku":  {InputPerMillion: 1.0},
+			},
+		},
+	}
+
+	pool := NewJudgePool(nil, &logger).WithClients(clients).WithPricing(pricing)
+
+	cfg := &config.JudgesConfig{
+		Judges: config.Judges{
+			Evaluators: []config.JudgeConfiguration{
+				{
+					Name:     "faithfulness",
+					Enabled:  true,
+					Prompt:   "Score: {{.Answer}}",
+					Model:    &config.ModelConfig{MaxTokens: 256},
+					Samples:  2,
+					Ensemble: []config.ModelConfig{{MaxTokens: 256}, {MaxTokens: 256, ModelID: "haiku"}},
+				},
+			},
+		},
+	}
+
+	judges, err := pool.BuildFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+
+	result := judges[0].Evaluate(context.Background(), models.EvaluationContext{Answer: "test"})
+
+	if !sonnet.WasCalled || !haiku.WasCalled {
+		t.Errorf("Expected one sample on each ensemble model, got sonnet=%v haiku=%v", sonnet.WasCalled, haiku.WasCalled)
+	}
+	if math.Abs(result.Score-0.8) > 1e-9 || result.Samples == nil || result.Samples.Count != 2 {
+		t.Errorf("Expected mean score 0.8 over 2 samples, got %f %+v", result.Score, result.Samples)
+	}
+	// Each sample is priced by its own model: 3.0 + 1.0
+	if result.Usage == nil || math.Abs(result.Usage.Cost-4.0) > 1e-9 {
+		t.Errorf("Expected cost 4.0, got %+v", result.Usage)
+	}
+}
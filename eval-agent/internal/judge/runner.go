@@ -46,12 +46,15 @@ func (c *JudgeRunner) Run(ctx context.Context, evaluationContext models.Evaluati
 					Dur("timeout", judgeTimeout).
 					Msg("Judge evaluation timed out")
 
-				// Return a failed result instead of blocking
+				// Return a failed result instead of blocking, the tokens of the calls made are still spent
 				evalResult = models.StageResult{
 					Name:     evalResult.Name,
 					Score:    0.0,
 					Reason:   "evaluation timed out after " + judgeTimeout.String(),
+					Status:   models.StageStatusTimeout,
 					Duration: judgeTimeout,
+					Weight:   evalResult.Weight,
+					Usage:    evalResult.Usage,
 				}
 			}
 
@@ -0,0 +1,49 @@
+package judge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// slowJudge returns once its context is done, after having spent tokens
+type slowJudge struct{}
+
+func (slowJudge) Name() string { return "slow" }
+
+func (slowJudge) Evaluate(ctx context.Context, _ models.EvaluationContext) models.StageResult {
+	<-ctx.Done()
+	return models.StageResult{
+		Name:   "slow-judge",
+		Status: models.StageStatusError,
+		Weight: 2.0,
+		Usage:  &models.TokenUsage{InputTokens: 100, OutputTokens: 20},
+	}
+}
+
+func TestJudgeRunner_Run_Timeout(t *testing.T) {
+	logger := zerolog.Nop()
+	runner := NewJudgeRunner([]Judge{slowJudge{}}, &logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	results := runner.Run(ctx, models.EvaluationContext{Answer: "test"})
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+
+	result := results[0]
+	if result.Status != models.StageStatusTimeout {
+		t.Errorf("expected status timeout, got %s", result.Status)
+	}
+	if result.Weight != 2.0 {
+		t.Errorf("expected the judge weight kept, got %f", result.Weight)
+	}
+	if result.Usage == nil || result.Usage.InputTokens != 100 || result.Usage.OutputTokens != 20 {
+		t.Errorf("expected the judge usage kept, got %+v", result.Usage)
+	}
+}
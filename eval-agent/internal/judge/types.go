@@ -1,6 +1,62 @@
 package judge
 
+import (
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// repairPrompt is sent when the judge response can't be parsed. It receives the
+// original prompt and the invalid response.
+const repairPrompt = `%s
+
+Your previous response could not be parsed as JSON:
+%s
+
+Respond again with ONLY the raw JSON object requested above, with no markdown, no code blocks and no explanation.`
+
+// defaultPairwisePrompt compares two answers on the judge's description, for judges
+// without a pairwise_prompt
+const defaultPairwisePrompt = `You are an evaluation judge comparing two answers to the same query.
+Decide which answer is better on this criterion: {{.Criterion}}
+Judge only this criterion. Do not let the order of the answers or their length influence you.
+
+Query: {{.Query}}
+{{- if .Context}}
+Context: {{.Context}}
+{{- end}}
+{{- if .ReferenceAnswer}}
+Reference answer: {{.ReferenceAnswer}}
+{{- end}}
+
+Answer A: {{.A.Answer}}
+
+Answer B: {{.B.Answer}}
+
+Respond ONLY in raw JSON with no markdown, no code blocks, no explanation:
+{"preference": "A" | "B" | "tie", "reason": "<string>"}`
+
 type judgeResponse struct {
-	Score  float64 `json:"score"`
-	Reason string  `json:"reason"`
+	Score      flexibleFloat       `json:"score"` // Numeric strings such as "0.8" are accepted
+	Reason     string              `json:"reason"`
+	Criteria   []criterionResponse `json:"criteria,omitempty"`   // Only returned by rubric judges
+	Preference string              `json:"preference,omitempty"` // Only returned by pairwise comparisons
+}
+
+type criterionResponse struct {
+	Name   string        `json:"name"`
+	Score  flexibleFloat `json:"score"`
+	Reason string        `json:"reason"`
+}
+
+// promptData is the data available to prompt templates. The evaluation context is
+// embedded so templates keep using {{.Query}}, {{.Answer}} and {{.Context}}.
+type promptData struct {
+	models.EvaluationContext
+	Rubric *config.Rubric
+}
+
+// pairwiseData is the data available to pairwise prompt templates
+type pairwiseData struct {
+	models.ComparisonContext
+	Criterion string // The judge's description
 }
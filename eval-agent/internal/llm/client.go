@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/bedrock"
+)
+
+// Supported LLM providers
+const (
+	ProviderBedrock  = "bedrock"  // Claude on AWS Bedrock
+	ProviderOpenAI   = "openai"   // Any OpenAI-compatible chat completions API (OpenAI, vLLM, llama.cpp, ...)
+	ProviderScripted = "scripted" // Deterministic canned responses for tests and offline development
+)
+
+// Providers lists the supported provider names
+var Providers = []string{ProviderBedrock, ProviderOpenAI, ProviderScripted}
+
+type Request struct {
+	Prompt      string
+	MaxTokens   int
+	Temperature float64
+}
+
+type Response struct {
+	Content    string
+	StopReason string
+	Usage      Usage
+}
+
+// Usage is the number of tokens a call consumed, as reported by the provider
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// Client is the provider-neutral interface for invoking a model
+type Client interface {
+	InvokeModel(ctx context.Context, request Request) (*Response, error)
+	InvokeModelWithRetry(ctx context.Context, request Request) (*Response, error)
+}
+
+// Config selects the provider and model of a client. It is comparable so it can be
+// used as a map key to share clients.
+type Config struct {
+	Provider   string
+	ModelID    string
+	AWSRegion  string // bedrock
+	BaseURL    string // openai, e.g. http://localhost:8000/v1
+	APIKey     string // openai, optional for local servers
+	ScriptPath string // scripted
+}
+
+// Settings holds the connection settings of every provider, and which one is the default
+type Settings struct {
+	DefaultProvider string
+	AWSRegion       string
+	BedrockModelID  string
+	OpenAIBaseURL   string
+	OpenAIAPIKey    string
+	OpenAIModelID   string
+	ScriptPath      string
+}
+
+// Config returns the client configuration of a provider and model. An empty provider selects
+// the default provider, an empty model ID the provider's default model.
+func (s Settings) Config(provider string, modelID string) Config {
+	if provider == "" {
+		provider = s.DefaultProvider
+	}
+
+	var cfg Config
+	switch provider {
+	case ProviderOpenAI:
+		cfg = Config{Provider: provider, ModelID: s.OpenAIModelID, BaseURL: s.OpenAIBaseURL, APIKey: s.OpenAIAPIKey}
+	case ProviderScripted:
+		cfg = Config{Provider: provider, ModelID: ProviderScripted, ScriptPath: s.ScriptPath}
+	default:
+		cfg = Config{Provider: provider, ModelID: s.BedrockModelID, AWSRegion: s.AWSRegion}
+	}
+
+	if modelID != "" {
+		cfg.ModelID = modelID
+	}
+	return cfg
+}
+
+// NewClient creates a client for the configured provider
+func NewClient(ctx context.Context, cfg Config) (Client, error) {
+	return newClient(ctx, cfg, nil)
+}
+
+// newClient creates a client whose calls are paced by the limiter, when not nil. The
+// scripted provider is never paced.
+func newClient(ctx context.Context, cfg Config, limiter *Limiter) (Client, error) {
+	switch cfg.Provider {
+	case ProviderBedrock:
+		client, err := bedrock.NewClient(ctx, cfg.AWSRegion, cfg.ModelID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Bedrock client: %w", err)
+		}
+		if limiter != nil {
+			client.Pacer = limiter
+		}
+		return NewBedrockClient(client), nil
+	case ProviderOpenAI:
+		client := NewOpenAIClient(cfg.BaseURL, cfg.APIKey, cfg.ModelID)
+		client.Limiter = limiter
+		return client, nil
+	case ProviderScripted:
+		return LoadScriptedClient(cfg.ScriptPath)
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s (supported: bedrock, openai, scripted)", cfg.Provider)
+	}
+}
+
+// Registry creates clients on first use and shares them between callers with the same config
+type Registry struct {
+	clients map[Config]Client
+	limits  Limits
+	mu      sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		clients: make(map[Config]Client),
+	}
+}
+
+// WithLimits paces the calls of the clients created from now on. Provider quotas apply per
+// model, so every config gets a limiter of its own, shared by all of its callers.
+func (r *Registry) WithLimits(limits Limits) *Registry {
+	r.limits = limits
+	return r
+}
+
+// Get returns the client for the config, creating it if needed
+func (r *Registry) Get(ctx context.Context, cfg Config) (Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if client, ok := r.clients[cfg]; ok {
+		return client, nil
+	}
+
+	var limiter *Limiter
+	if r.limits.enabled() {
+		limiter = NewLimiter(r.limits)
+	}
+
+	client, err := newClient(ctx, cfg, limiter)
+	if err != nil {
+		return nil, err
+	}
+
+	r.clients[cfg] = client
+	return client, nil
+}
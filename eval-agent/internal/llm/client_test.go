@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScriptedClient_InvokeModel(t *testing.T) {
+	client := NewScriptedClient(Script{
+		Rules: []ScriptRule{
+			{Match: "faithful", Response: `{"score": 0.2, "reason": "hallucinated"}`},
+			{Match: "Answer:", Response: `{"score": 0.9, "reason": "relevant"}`},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		prompt  string
+		want    string
+		wantErr bool
+	}{
+		{"first matching rule wins", "Score how faithful... Answer: x", `{"score": 0.2, "reason": "hallucinated"}`, false},
+		{"second rule", "Query: q\nAnswer: a", `{"score": 0.9, "reason": "relevant"}`, false},
+		{"no match without default", "something else", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.InvokeModel(context.Background(), Request{Prompt: tt.prompt})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", resp)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if resp.Content != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, resp.Content)
+			}
+		})
+	}
+}
+
+func TestLoadScriptedClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.json")
+	script := `{"rules": [{"match": "coherent", "response": "{\"score\": 0.7, \"reason\": \"ok\"}"}], "default": "{\"score\": 1.0, \"reason\": \"scripted\"}"}`
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	client, err := NewClient(context.Background(), Config{Provider: ProviderScripted, ScriptPath: path})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	resp, err := client.InvokeModelWithRetry(context.Background(), Request{Prompt: "unmatched"})
+	if err != nil {
+		t.Fatalf("InvokeModelWithRetry failed: %v", err)
+	}
+	if resp.Content != `{"score": 1.0, "reason": "scripted"}` {
+		t.Errorf("Expected default response, got %q", resp.Content)
+	}
+
+	if _, err := LoadScriptedClient(""); err == nil {
+		t.Error("Expected error without a script file")
+	}
+}
+
+func TestNewClient_UnknownProvider(t *testing.T) {
+	if _, err := NewClient(context.Background(), Config{Provider: "azure"}); err == nil {
+		t.Error("Expected error for unknown provider")
+	}
+}
+
+func TestSettings_Config(t *testing.T) {
+	settings := Settings{
+		DefaultProvider: ProviderOpenAI,
+		AWSRegion:       "us-east-1",
+		BedrockModelID:  "claude",
+		OpenAIBaseURL:   "http://localhost:8000/v1",
+		OpenAIModelID:   "llama",
+	}
+
+	if cfg := settings.Config("", ""); cfg.Provider != ProviderOpenAI || cfg.ModelID != "llama" || cfg.BaseURL != "http://localhost:8000/v1" {
+		t.Errorf("Expected the default openai config, got %+v", cfg)
+	}
+	if cfg := settings.Config(ProviderBedrock, ""); cfg.ModelID != "claude" || cfg.AWSRegion != "us-east-1" {
+		t.Errorf("Expected the bedrock config, got %+v", cfg)
+	}
+	if cfg := settings.Config(ProviderBedrock, "haiku"); cfg.ModelID != "haiku" || cfg.AWSRegion != "us-east-1" {
+		t.Errorf("Expected the bedrock config with the model override, got %+v", cfg)
+	}
+}
+
+func TestRegistry_SharesClients(t *testing.T) {
+	registry := NewRegistry()
+	cfg := Config{Provider: ProviderOpenAI, ModelID: "llama", BaseURL: "http://localhost:8000/v1"}
+
+	first, err := registry.Get(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	second, _ := registry.Get(context.Background(), cfg)
+	if first != second {
+		t.Error("Expected the same client for the same config")
+	}
+
+	cfg.ModelID = "mistral"
+	other, _ := registry.Get(context.Background(), cfg)
+	if other == first {
+		t.Error("Expected a new client for a different model")
+	}
+}
+
+func TestRegistry_WithLimits(t *testing.T) {
+	registry := NewRegistry().WithLimits(Limits{MaxConcurrency: 4})
+	cfg := Config{Provider: ProviderOpenAI, ModelID: "llama", BaseURL: "http://localhost:8000/v1"}
+
+	first, _ := registry.Get(context.Background(), cfg)
+	cfg.ModelID = "mistral"
+	other, _ := registry.Get(context.Background(), cfg)
+
+	limiter := first.(*OpenAIClient).Limiter
+	if limiter == nil {
+		t.Fatal("Expected a paced client")
+	}
+	if other.(*OpenAIClient).Limiter == limiter {
+		t.Error("Expected a limiter per model")
+	}
+
+	unlimited, _ := NewRegistry().Get(context.Background(), cfg)
+	if unlimited.(*OpenAIClient).Limiter != nil {
+		t.Error("Expected no limiter without limits")
+	}
+}
@@ -0,0 +1,22 @@
+package llm
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/bedrock"
+)
+
+// IsThrottled reports whether the provider rejected the call for exceeding a rate or quota
+func IsThrottled(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return bedrock.ClassifyError(err) == bedrock.ErrorThrottled
+}
+
+// IsRetryable reports whether the call may succeed if sent again, whatever the provider
+func IsRetryable(err error) bool {
+	return isRetryableHTTPError(err) || bedrock.IsRetryable(err)
+}
@@ -0,0 +1,226 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limits caps the calls to one model. Zero disables a limit.
+type Limits struct {
+	RequestsPerMinute int
+	TokensPerMinute   int // Input and output tokens
+	MaxConcurrency    int // Upper bound of the adaptive concurrency
+}
+
+func (l Limits) enabled() bool {
+	return l.RequestsPerMinute > 0 || l.TokensPerMinute > 0 || l.MaxConcurrency > 0
+}
+
+// Limiter paces the calls to one model for all of its callers, each attempt of a retried
+// call included. Token buckets hold the request and token rates under the provider quota,
+// and the number of calls in flight adapts AIMD-style: halved when the provider throttles,
+// grown by one slot per window of successful calls up to MaxConcurrency. Retrying is left
+// to the clients.
+type Limiter struct {
+	mu           sync.Mutex
+	requests     *tokenBucket // nil when unlimited
+	tokens       *tokenBucket // nil when unlimited
+	max          float64      // 0 when unlimited
+	limit        float64      // Current concurrency limit
+	inflight     int
+	waiters      []chan struct{}
+	lastDecrease time.Time
+	now          func() time.Time
+}
+
+func NewLimiter(limits Limits) *Limiter {
+	l := &Limiter{
+		max:   float64(limits.MaxConcurrency),
+		limit: float64(limits.MaxConcurrency),
+		now:   time.Now,
+	}
+	if limits.RequestsPerMinute > 0 {
+		l.requests = newTokenBucket(limits.RequestsPerMinute, l.now())
+	}
+	if limits.TokensPerMinute > 0 {
+		l.tokens = newTokenBucket(limits.TokensPerMinute, l.now())
+	}
+	return l
+}
+
+// Acquire waits for a concurrency slot, then for the request and token budget of a call.
+// The call is estimated to use about four characters per prompt token plus maxTokens of
+// output. The returned release must be called with the call's outcome.
+func (l *Limiter) Acquire(ctx context.Context, prompt string, maxTokens int) (func(usedTokens int, err error), error) {
+	if err := l.acquireSlot(ctx); err != nil {
+		return nil, err
+	}
+
+	tokens := len(prompt)/4 + maxTokens
+
+	l.mu.Lock()
+	now := l.now()
+	var wait time.Duration
+	if l.requests != nil {
+		wait = max(wait, l.requests.reserve(now, 1))
+	}
+	if l.tokens != nil {
+		wait = max(wait, l.tokens.reserve(now, float64(tokens)))
+	}
+	l.mu.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			l.mu.Lock()
+			if l.requests != nil {
+				l.requests.refund(1)
+			}
+			if l.tokens != nil {
+				l.tokens.refund(float64(tokens))
+			}
+			l.releaseSlot()
+			l.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+
+	start := l.now()
+	return func(usedTokens int, err error) {
+		l.release(start, tokens, usedTokens, err)
+	}, nil
+}
+
+func (l *Limiter) acquireSlot(ctx context.Context) error {
+	l.mu.Lock()
+	if l.max == 0 || l.inflight < l.slots() {
+		l.inflight++
+		l.mu.Unlock()
+		return nil
+	}
+
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		for i, waiter := range l.waiters {
+			if waiter == ready {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// The slot was handed over while giving up, pass it on
+		l.releaseSlot()
+		return ctx.Err()
+	}
+}
+
+// slots returns the number of calls allowed in flight, at least one
+func (l *Limiter) slots() int {
+	return max(int(l.limit), 1)
+}
+
+// releaseSlot frees a slot and hands free slots to waiters in arrival order. The caller
+// holds mu.
+func (l *Limiter) releaseSlot() {
+	l.inflight--
+	for len(l.waiters) > 0 && l.inflight < l.slots() {
+		ready := l.waiters[0]
+		l.waiters = l.waiters[1:]
+		l.inflight++
+		close(ready)
+	}
+}
+
+// release frees the slot of a call, corrects the token budget by the tokens the call
+// actually used and adapts the concurrency: throttling halves it, once per round of calls
+// started after the previous decrease, a success grows it by 1/limit
+func (l *Limiter) release(start time.Time, tokens int, usedTokens int, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tokens != nil {
+		if err != nil {
+			// A failed call is not billed, or bills less than estimated
+			usedTokens = 0
+		}
+		l.tokens.refund(float64(tokens - usedTokens))
+	}
+
+	if l.max > 0 {
+		switch {
+		case IsThrottled(err):
+			if !start.Before(l.lastDecrease) {
+				l.limit = max(l.limit/2, 1)
+				l.lastDecrease = l.now()
+			}
+		case err == nil:
+			l.limit = min(l.limit+1/l.limit, l.max)
+		}
+	}
+
+	l.releaseSlot()
+}
+
+// Concurrency returns the current number of calls allowed in flight, 0 when unlimited
+func (l *Limiter) Concurrency() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.max == 0 {
+		return 0
+	}
+	return l.slots()
+}
+
+// tokenBucket refills at a steady rate and holds up to ten seconds of it, so a burst can't
+// spend a minute's quota at once. Reservations may take it below zero, later callers then
+// wait for the debt to refill, which queues them behind earlier ones.
+type tokenBucket struct {
+	perSecond float64
+	capacity  float64
+	available float64
+	last      time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	perSecond := float64(perMinute) / 60
+	capacity := max(perSecond*10, 1)
+	return &tokenBucket{
+		perSecond: perSecond,
+		capacity:  capacity,
+		available: capacity,
+		last:      now,
+	}
+}
+
+// reserve takes n from the bucket and returns how long to wait until they are refilled
+func (b *tokenBucket) reserve(now time.Time, n float64) time.Duration {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.available = min(b.available+elapsed*b.perSecond, b.capacity)
+		b.last = now
+	}
+
+	b.available -= n
+	if b.available >= 0 {
+		return 0
+	}
+	return time.Duration(-b.available / b.perSecond * float64(time.Second))
+}
+
+// refund returns n to the bucket, a negative n takes more
+func (b *tokenBucket) refund(n float64) {
+	b.available = min(b.available+n, b.capacity)
+}
@@ -0,0 +1,252 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func TestIsThrottledAndRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		throttled bool
+		retryable bool
+	}{
+		{"nil", nil, false, false},
+		{"bedrock throttling", fmt.Errorf("invoke: %w", &types.ThrottlingException{}), true, true},
+		{"bedrock quota", &types.ServiceQuotaExceededException{}, true, true},
+		{"bedrock internal error", &types.InternalServerException{}, false, true},
+		{"bedrock model not ready", &types.ModelNotReadyException{}, false, true},
+		{"bedrock validation", &types.ValidationException{}, false, false},
+		{"bedrock access denied", &types.AccessDeniedException{}, false, false},
+		{"api error code", &smithy.GenericAPIError{Code: "TooManyRequestsException"}, true, true},
+		{"api server fault", &smithy.GenericAPIError{Code: "Unknown", Fault: smithy.FaultServer}, false, true},
+		{"http 429", responseError(http.StatusTooManyRequests), true, true},
+		{"http 503", responseError(http.StatusServiceUnavailable), false, true},
+		{"http 400", responseError(http.StatusBadRequest), false, false},
+		{"openai 429", &StatusError{StatusCode: http.StatusTooManyRequests}, true, true},
+		{"openai 500", &StatusError{StatusCode: http.StatusInternalServerError}, false, true},
+		{"openai 401", &StatusError{StatusCode: http.StatusUnauthorized}, false, false},
+		{"unexpected EOF", io.ErrUnexpectedEOF, false, true},
+		{"context cancelled", context.Canceled, false, false},
+		{"message mentioning throttling", errors.New("ThrottlingException in prompt text"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsThrottled(tt.err); got != tt.throttled {
+				t.Errorf("IsThrottled() = %v, want %v", got, tt.throttled)
+			}
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+}
+
+func responseError(status int) error {
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+		Err:      errors.New("request failed"),
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Now()
+	bucket := newTokenBucket(60, start) // 1 per second, holds 10
+
+	if wait := bucket.reserve(start, 10); wait != 0 {
+		t.Errorf("Expected full bucket to admit a burst of 10, got wait %v", wait)
+	}
+	if wait := bucket.reserve(start, 2); wait != 2*time.Second {
+		t.Errorf("Expected 2s wait on an empty bucket, got %v", wait)
+	}
+
+	// The debt is refilled first, later reservations queue behind it
+	if wait := bucket.reserve(start.Add(time.Second), 1); wait != 2*time.Second {
+		t.Errorf("Expected 2s wait behind the earlier reservation, got %v", wait)
+	}
+
+	bucket.refund(3)
+	if wait := bucket.reserve(start.Add(time.Minute), 10); wait != 0 {
+		t.Errorf("Expected refilled bucket after a minute, got wait %v", wait)
+	}
+}
+
+func TestLimiter_AdaptiveConcurrency(t *testing.T) {
+	limiter := NewLimiter(Limits{MaxConcurrency: 8})
+	ctx := context.Background()
+	throttled := &types.ThrottlingException{}
+
+	call := func(err error) {
+		release, acquireErr := limiter.Acquire(ctx, "", 0)
+		if acquireErr != nil {
+			t.Fatalf("Unexpected error: %v", acquireErr)
+		}
+		release(0, err)
+	}
+
+	// Calls started before a decrease don't halve the limit again
+	var releases []func(int, error)
+	for i := 0; i < 4; i++ {
+		release, err := limiter.Acquire(ctx, "", 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		releases = append(releases, release)
+	}
+	time.Sleep(time.Millisecond)
+	for _, release := range releases {
+		release(0, throttled)
+	}
+	if got := limiter.Concurrency(); got != 4 {
+		t.Errorf("Expected one halving for a round of throttled calls, got concurrency %d", got)
+	}
+
+	call(throttled)
+	if got := limiter.Concurrency(); got != 2 {
+		t.Errorf("Expected a new throttled call to halve again, got %d", got)
+	}
+
+	// Additive increase, about one slot per window of successes
+	for i := 0; i < 3; i++ {
+		call(nil)
+	}
+	if got := limiter.Concurrency(); got != 3 {
+		t.Errorf("Expected concurrency to grow by one after a window of successes, got %d", got)
+	}
+
+	for i := 0; i < 100; i++ {
+		call(nil)
+	}
+	if got := limiter.Concurrency(); got != 8 {
+		t.Errorf("Expected concurrency capped at the maximum, got %d", got)
+	}
+
+	// Other errors leave the limit unchanged
+	call(&types.ValidationException{})
+	if got := limiter.Concurrency(); got != 8 {
+		t.Errorf("Expected non-throttling error to keep concurrency, got %d", got)
+	}
+}
+
+func TestLimiter_WaitsForSlot(t *testing.T) {
+	limiter := NewLimiter(Limits{MaxConcurrency: 1})
+
+	held, err := limiter.Acquire(context.Background(), "", 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx, "", 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded while the slot is held, got %v", err)
+	}
+
+	acquired := make(chan func(int, error))
+	go func() {
+		release, _ := limiter.Acquire(context.Background(), "", 0)
+		acquired <- release
+	}()
+
+	held(0, nil)
+	select {
+	case release := <-acquired:
+		release(0, nil)
+	case <-time.After(time.Second):
+		t.Fatal("Expected waiter to get the released slot")
+	}
+}
+
+func TestLimiter_TokenBudget(t *testing.T) {
+	limiter := NewLimiter(Limits{TokensPerMinute: 6000}) // 100 per second, holds 1000
+
+	// 400 prompt characters are about 100 tokens, plus 900 of output
+	release, err := limiter.Acquire(context.Background(), strings.Repeat("word", 100), 900)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The estimate spent the budget, the next call would wait
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(ctx, "", 500); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected to wait for the token budget, got %v", err)
+	}
+
+	// Settling with the actual usage returns the unused estimate
+	release(200, nil)
+	if _, err := limiter.Acquire(context.Background(), "", 500); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestOpenAIClient_PacedRetries(t *testing.T) {
+	var calls, inflight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+
+		// The first two calls are throttled
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"choices": [{"message": {"content": "ok"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 10, "completion_tokens": 5}}`))
+	}))
+	defer server.Close()
+
+	limiter := NewLimiter(Limits{MaxConcurrency: 4})
+	client := NewOpenAIClient(server.URL, "", "model")
+	client.InitialDelay = time.Millisecond
+	client.Limiter = limiter
+
+	// Every attempt, retries included, waits for the limiter and reports to it: two
+	// throttles halve the concurrency twice, the success grows it by one
+	if _, err := client.InvokeModelWithRetry(context.Background(), Request{Prompt: "hello"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := limiter.Concurrency(); got != 2 {
+		t.Errorf("Expected concurrency 2 after two throttled attempts, got %d", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.InvokeModelWithRetry(context.Background(), Request{Prompt: "hello"}); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 4 {
+		t.Errorf("Expected at most 4 calls in flight, got %d", peak.Load())
+	}
+	if calls.Load() != 15 {
+		t.Errorf("Expected 13 calls and 2 retries, got %d calls", calls.Load())
+	}
+}
@@ -0,0 +1,204 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIClient calls an OpenAI-compatible chat completions API. Local servers such as
+// vLLM and llama.cpp expose the same API, the API key is optional for them.
+type OpenAIClient struct {
+	BaseURL      string
+	APIKey       string
+	ModelID      string
+	HTTPClient   *http.Client
+	MaxRetries   int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Limiter      *Limiter // Paces every call, nil when calls are not paced
+}
+
+func NewOpenAIClient(baseURL string, apiKey string, modelID string) *OpenAIClient {
+	return &OpenAIClient{
+		BaseURL:      strings.TrimSuffix(baseURL, "/"),
+		APIKey:       apiKey,
+		ModelID:      modelID,
+		HTTPClient:   &http.Client{Timeout: 60 * time.Second},
+		MaxRetries:   3,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     12 * time.Second,
+	}
+}
+
+type chatCompletionRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// StatusError is returned when the API answers with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("chat completions API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// InvokeModel sends one request, after the limiter admits it. Every attempt of
+// InvokeModelWithRetry goes through here, so each one is paced.
+func (c *OpenAIClient) InvokeModel(ctx context.Context, request Request) (*Response, error) {
+	if c.Limiter == nil {
+		return c.invokeModel(ctx, request)
+	}
+
+	release, err := c.Limiter.Acquire(ctx, request.Prompt, request.MaxTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.invokeModel(ctx, request)
+	var used int
+	if response != nil {
+		used = response.Usage.InputTokens + response.Usage.OutputTokens
+	}
+	release(used, err)
+
+	return response, err
+}
+
+func (c *OpenAIClient) invokeModel(ctx context.Context, request Request) (*Response, error) {
+	payload := chatCompletionRequest{
+		Model:       c.ModelID,
+		MaxTokens:   request.MaxTokens,
+		Temperature: request.Temperature,
+		Messages: []chatMessage{
+			{
+				Role:    "user",
+				Content: request.Prompt,
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal chat completions request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat completions request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	httpResp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call chat completions API: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat completions response: %w", err)
+	}
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return nil, &StatusError{StatusCode: httpResp.StatusCode, Body: truncate(string(respBody), 512)}
+	}
+
+	var response chatCompletionResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chat completions response: %w", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return nil, errors.New("chat completions response has no choices")
+	}
+
+	return &Response{
+		Content:    response.Choices[0].Message.Content,
+		StopReason: response.Choices[0].FinishReason,
+		Usage: Usage{
+			InputTokens:  response.Usage.PromptTokens,
+			OutputTokens: response.Usage.CompletionTokens,
+		},
+	}, nil
+}
+
+func (c *OpenAIClient) InvokeModelWithRetry(ctx context.Context, request Request) (*Response, error) {
+	var lastErr error
+
+	for attempt := 0; attempt < c.MaxRetries; attempt++ {
+		response, err := c.InvokeModel(ctx, request)
+		if err == nil {
+			return response, nil
+		}
+
+		lastErr = err
+
+		if !IsRetryable(err) {
+			return nil, fmt.Errorf("non-retryable error: %w", err)
+		}
+
+		// ±20% jitter, so that calls throttled together don't retry together
+		delay := min(c.InitialDelay<<attempt, c.MaxDelay)
+		delay += time.Duration(float64(delay) * 0.2 * (2*rand.Float64() - 1))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+			continue
+		}
+	}
+
+	return nil, fmt.Errorf("max retries %d exceeded: %w", c.MaxRetries, lastErr)
+}
+
+// isRetryableHTTPError reports whether the request may succeed if sent again: rate limits,
+// server errors and network failures are retried, other client errors are not
+func isRetryableHTTPError(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOpenAIClient_InvokeModel(t *testing.T) {
+	var received chatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Expected path /v1/chat/completions, got %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Expected bearer token, got %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "{\"score\": 0.9}"}, "finish_reason": "stop"}], "usage": {"prompt_tokens": 42, "completion_tokens": 7}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(server.URL+"/v1/", "secret", "llama-3-8b")
+
+	resp, err := client.InvokeModel(context.Background(), Request{Prompt: "Score this", MaxTokens: 128, Temperature: 0.2})
+	if err != nil {
+		t.Fatalf("InvokeModel failed: %v", err)
+	}
+
+	if resp.Content != `{"score": 0.9}` || resp.StopReason != "stop" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	if resp.Usage.InputTokens != 42 || resp.Usage.OutputTokens != 7 {
+		t.Errorf("Expected usage 42/7, got %+v", resp.Usage)
+	}
+	if received.Model != "llama-3-8b" || received.MaxTokens != 128 || received.Temperature != 0.2 {
+		t.Errorf("Unexpected request: %+v", received)
+	}
+	if len(received.Messages) != 1 || received.Messages[0].Role != "user" || received.Messages[0].Content != "Score this" {
+		t.Errorf("Expected a single user message, got %+v", received.Messages)
+	}
+}
+
+func TestOpenAIClient_InvokeModelWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{"succeeds first time", []int{http.StatusOK}, false, 1},
+		{"retries rate limit", []int{http.StatusTooManyRequests, http.StatusOK}, false, 2},
+		{"retries server error", []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK}, false, 3},
+		{"does not retry bad request", []int{http.StatusBadRequest, http.StatusOK}, true, 1},
+		{"gives up after max retries", []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK}, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[calls.Add(1)-1]
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					w.Write([]byte(`{"choices": [{"message": {"content": "ok"}, "finish_reason": "stop"}]}`))
+				} else {
+					w.Write([]byte(`{"error": "failed"}`))
+				}
+			}))
+			defer server.Close()
+
+			client := NewOpenAIClient(server.URL, "", "model")
+			client.InitialDelay = time.Millisecond
+
+			resp, err := client.InvokeModelWithRetry(context.Background(), Request{Prompt: "hi"})
+			if tt.wantErr && err == nil {
+				t.Errorf("Expected error, got response %+v", resp)
+			}
+			if !tt.wantErr && (err != nil || resp.Content != "ok") {
+				t.Errorf("Expected ok response, got %+v, %v", resp, err)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, calls.Load())
+			}
+		})
+	}
+}
+
+func TestOpenAIClient_NoChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices": []}`))
+	}))
+	defer server.Close()
+
+	_, err := NewOpenAIClient(server.URL, "", "model").InvokeModel(context.Background(), Request{Prompt: "hi"})
+	if err == nil {
+		t.Error("Expected error for a response without choices")
+	}
+}
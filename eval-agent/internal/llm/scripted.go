@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ScriptRule answers prompts containing Match with Response
+type ScriptRule struct {
+	Match    string `json:"match"`
+	Response string `json:"response"`
+}
+
+// Script is the content of a scripted backend file
+type Script struct {
+	Rules   []ScriptRule `json:"rules"`
+	Default string       `json:"default,omitempty"` // Used when no rule matches
+}
+
+// ScriptedClient returns canned responses without calling a model. The first rule whose
+// match is contained in the prompt wins, so the same prompt always gets the same response.
+type ScriptedClient struct {
+	script Script
+}
+
+func NewScriptedClient(script Script) *ScriptedClient {
+	return &ScriptedClient{
+		script: script,
+	}
+}
+
+// LoadScriptedClient reads the script from a JSON file
+func LoadScriptedClient(path string) (*ScriptedClient, error) {
+	if path == "" {
+		return nil, fmt.Errorf("scripted provider requires a script file")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script file %s: %w", path, err)
+	}
+
+	var script Script
+	if err := json.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse script file %s: %w", path, err)
+	}
+
+	return NewScriptedClient(script), nil
+}
+
+func (c *ScriptedClient) InvokeModel(ctx context.Context, request Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range c.script.Rules {
+		if strings.Contains(request.Prompt, rule.Match) {
+			return &Response{Content: rule.Response, StopReason: "end_turn"}, nil
+		}
+	}
+
+	if c.script.Default != "" {
+		return &Response{Content: c.script.Default, StopReason: "end_turn"}, nil
+	}
+
+	return nil, fmt.Errorf("no scripted response matches the prompt")
+}
+
+// InvokeModelWithRetry is the same as InvokeModel, scripted responses never fail transiently
+func (c *ScriptedClient) InvokeModelWithRetry(ctx context.Context, request Request) (*Response, error) {
+	return c.InvokeModel(ctx, request)
+}
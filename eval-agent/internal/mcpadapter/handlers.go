@@ -2,6 +2,7 @@ package mcpadapter
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -15,21 +16,29 @@ type EvaluateInput struct {
 	Query   string `json:"user_query" jsonschema:"user's original query"`
 	Answer  string `json:"answer" jsonschema:"agent response to evaluate"`
 	Context string `json:"context,omitempty" jsonschema:"optional context or retrieved documents"`
+
+	ReferenceAnswer string `json:"reference_answer,omitempty" jsonschema:"optional ground-truth answer for reference metrics and judges"`
+	OutputSchema    string `json:"output_schema,omitempty" jsonschema:"optional JSON Schema, as a JSON string, that a JSON answer must match"`
+
+	AgentName    string `json:"agent_name,omitempty" jsonschema:"optional name of the agent, used to select the evaluation profile"`
+	AgentType    string `json:"agent_type,omitempty" jsonschema:"optional type of the agent"`
+	AgentVersion string `json:"agent_version,omitempty" jsonschema:"optional version of the agent"`
 }
 
 // EvaluateSingleJudgeInput is the MCP tool input schema for single judge evaluation.
 type EvaluateSingleJudgeInput struct {
-	EventID   string  `json:"event_id" jsonschema:"unique event identifier"`
-	Query     string  `json:"user_query" jsonschema:"user's original query"`
-	Answer    string  `json:"answer" jsonschema:"agent response to evaluate"`
-	Context   string  `json:"context,omitempty" jsonschema:"optional context or retrieved documents"`
-	JudgeName string  `json:"judge_name" jsonschema:"judge name: relevance, faithfulness, coherence, completeness, or instruction"`
-	Threshold float64 `json:"threshold,omitempty" jsonschema:"pass/fail threshold (0.0-1.0, default: 0.7)"`
+	EventID         string  `json:"event_id" jsonschema:"unique event identifier"`
+	Query           string  `json:"user_query" jsonschema:"user's original query"`
+	Answer          string  `json:"answer" jsonschema:"agent response to evaluate"`
+	Context         string  `json:"context,omitempty" jsonschema:"optional context or retrieved documents"`
+	JudgeName       string  `json:"judge_name" jsonschema:"judge name: relevance, faithfulness, coherence, completeness, or instruction"`
+	ReferenceAnswer string  `json:"reference_answer,omitempty" jsonschema:"optional ground-truth answer for judges that use one"`
+	Threshold       float64 `json:"threshold,omitempty" jsonschema:"pass/fail threshold (0.0-1.0, default: 0.7)"`
 }
 
 // NewEvaluateHandler returns a tool handler that uses the given executor.
 // Pass the returned function to mcp.AddTool.
-func NewEvaluateHandler(exec *executor.Executor) func(context.Context, *mcp.CallToolRequest, EvaluateInput) (*mcp.CallToolResult, models.EvaluationResult, error) {
+func NewEvaluateHandler(exec executor.Evaluator) func(context.Context, *mcp.CallToolRequest, EvaluateInput) (*mcp.CallToolResult, models.EvaluationResult, error) {
 	return func(ctx context.Context, req *mcp.CallToolRequest, input EvaluateInput) (*mcp.CallToolResult, models.EvaluationResult, error) {
 		return EvaluateResponse(ctx, exec, req, input)
 	}
@@ -38,15 +47,22 @@ func NewEvaluateHandler(exec *executor.Executor) func(context.Context, *mcp.Call
 // EvaluateResponse runs the full evaluation pipeline and returns the result.
 func EvaluateResponse(
 	ctx context.Context,
-	exec *executor.Executor,
+	exec executor.Evaluator,
 	req *mcp.CallToolRequest,
 	input EvaluateInput,
 ) (*mcp.CallToolResult, models.EvaluationResult, error) {
 	evalCtx := models.EvaluationContext{
-		RequestID: input.EventID,
-		Query:     input.Query,
-		Context:   input.Context,
-		Answer:    input.Answer,
+		RequestID:       input.EventID,
+		Query:           input.Query,
+		Context:         input.Context,
+		Answer:          input.Answer,
+		ReferenceAnswer: input.ReferenceAnswer,
+		OutputSchema:    outputSchema(input.OutputSchema),
+		Agent: models.Agent{
+			Name:    input.AgentName,
+			Type:    input.AgentType,
+			Version: input.AgentVersion,
+		},
 		CreatedAt: time.Now(),
 	}
 
@@ -70,11 +86,12 @@ func EvaluateSingleJudge(
 	input EvaluateSingleJudgeInput,
 ) (*mcp.CallToolResult, models.EvaluationResult, error) {
 	evalCtx := models.EvaluationContext{
-		RequestID: input.EventID,
-		Query:     input.Query,
-		Context:   input.Context,
-		Answer:    input.Answer,
-		CreatedAt: time.Now(),
+		RequestID:       input.EventID,
+		Query:           input.Query,
+		Context:         input.Context,
+		Answer:          input.Answer,
+		ReferenceAnswer: input.ReferenceAnswer,
+		CreatedAt:       time.Now(),
 	}
 
 	// Default threshold to 0.7 if not provided
@@ -87,3 +104,56 @@ func EvaluateSingleJudge(
 
 	return nil, result, err
 }
+
+// CompareInput is the MCP tool input schema for pairwise comparison.
+type CompareInput struct {
+	EventID string   `json:"event_id" jsonschema:"unique event identifier"`
+	Query   string   `json:"user_query" jsonschema:"user's original query"`
+	Context string   `json:"context,omitempty" jsonschema:"optional context or retrieved documents"`
+	AnswerA string   `json:"answer_a" jsonschema:"first agent response, e.g. of the current version"`
+	AnswerB string   `json:"answer_b" jsonschema:"second agent response, e.g. of the new version"`
+	Judges  []string `json:"judges,omitempty" jsonschema:"optional judges to compare with, all judges when empty"`
+
+	ReferenceAnswer string `json:"reference_answer,omitempty" jsonschema:"optional ground-truth answer for judges that use one"`
+
+	VersionA string `json:"version_a,omitempty" jsonschema:"optional agent version that produced answer_a"`
+	VersionB string `json:"version_b,omitempty" jsonschema:"optional agent version that produced answer_b"`
+}
+
+// NewCompareHandler returns a tool handler for pairwise comparison.
+// Pass the returned function to mcp.AddTool.
+func NewCompareHandler(cmpExec *executor.ComparisonExecutor) func(context.Context, *mcp.CallToolRequest, CompareInput) (*mcp.CallToolResult, models.ComparisonResult, error) {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input CompareInput) (*mcp.CallToolResult, models.ComparisonResult, error) {
+		return CompareResponses(ctx, cmpExec, req, input)
+	}
+}
+
+// CompareResponses asks the judges which of two answers is better and returns their preferences.
+func CompareResponses(
+	ctx context.Context,
+	cmpExec *executor.ComparisonExecutor,
+	req *mcp.CallToolRequest,
+	input CompareInput,
+) (*mcp.CallToolResult, models.ComparisonResult, error) {
+	cmpCtx := models.ComparisonContext{
+		RequestID:       input.EventID,
+		Query:           input.Query,
+		Context:         input.Context,
+		ReferenceAnswer: input.ReferenceAnswer,
+		A:               models.Candidate{Agent: models.Agent{Version: input.VersionA}, Answer: input.AnswerA},
+		B:               models.Candidate{Agent: models.Agent{Version: input.VersionB}, Answer: input.AnswerB},
+		CreatedAt:       time.Now(),
+	}
+
+	result, err := cmpExec.Execute(ctx, input.Judges, cmpCtx)
+
+	return nil, result, err
+}
+
+// outputSchema converts the schema passed as a JSON string, an empty string means no schema
+func outputSchema(schema string) json.RawMessage {
+	if schema == "" {
+		return nil
+	}
+	return json.RawMessage(schema)
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -26,42 +27,239 @@ type Agent struct {
 }
 
 type Interaction struct {
-	UserQuery string `json:"user_query"`
-	Context   string `json:"context"`
-	Answer    string `json:"answer"`
+	UserQuery       string          `json:"user_query"`
+	Context         string          `json:"context"`
+	Answer          string          `json:"answer"`
+	ReferenceAnswer string          `json:"reference_answer,omitempty"` // Optional ground-truth answer, e.g. from a golden dataset
+	OutputSchema    json.RawMessage `json:"output_schema,omitempty"`    // Optional JSON Schema a JSON answer must match
 }
 
 // Input message
 
 type EvaluationRequest struct {
-	EventID          string      `json:"event_id"`
-	EventType        EventType   `json:"event_type"`
-	Agent            Agent       `json:"agent"`
-	Interaction      Interaction `json:"interaction"`
-	HumanAnnotation  *string     `json:"human_annotation,omitempty"` // Optional: for validation mode
+	EventID         string      `json:"event_id"`
+	EventType       EventType   `json:"event_type"`
+	Agent           Agent       `json:"agent"`
+	Interaction     Interaction `json:"interaction"`
+	HumanAnnotation *string     `json:"human_annotation,omitempty"` // Optional: for validation mode
 }
 
 // Normalized internal object
 type EvaluationContext struct {
-	RequestID string    `json:"request_id" jsonschema:"required,description=Unique event identifier"`
-	Query     string    `json:"user_query" jsonschema:"required,description=User's original query"`
-	Context   string    `json:"context,omitempty" jsonschema:"description=Optional context or retrieved documents"`
-	Answer    string    `json:"answer" jsonschema:"required,description=Agent response to evaluate"`
-	CreatedAt time.Time `json:"created_at" jsonschema:"description=Time when the evaluation context was created"`
+	RequestID       string          `json:"request_id" jsonschema:"required,description=Unique event identifier"`
+	Query           string          `json:"user_query" jsonschema:"required,description=User's original query"`
+	Context         string          `json:"context,omitempty" jsonschema:"description=Optional context or retrieved documents"`
+	Answer          string          `json:"answer" jsonschema:"required,description=Agent response to evaluate"`
+	Agent           Agent           `json:"agent,omitempty" jsonschema:"description=Agent that produced the answer"`
+	CreatedAt       time.Time       `json:"created_at" jsonschema:"description=Time when the evaluation context was created"`
+	ReferenceAnswer string          `json:"reference_answer,omitempty" jsonschema:"description=Optional ground-truth answer the answer is compared with"`
+	OutputSchema    json.RawMessage `json:"output_schema,omitempty" jsonschema:"description=Optional JSON Schema a JSON answer must match"`
 }
 
+// StageStatus tells whether a stage produced a score. A score of 0.0 with a non-ok
+// status means the evaluator didn't judge the answer, not that the answer is bad.
+type StageStatus string
+
+const (
+	StageStatusOK      StageStatus = "ok"
+	StageStatusSkipped StageStatus = "skipped" // Not applicable, e.g. a judge requiring context without one
+	StageStatusError   StageStatus = "error"   // LLM call failed or returned an invalid response
+	StageStatusTimeout StageStatus = "timeout"
+)
+
 // One evaluator's output
 type StageResult struct {
-	Name     string        `json:"name"`
-	Score    float64       `json:"score"`
-	Reason   string        `json:"reason"`
-	Duration time.Duration `json:"duration_ns"`
+	Name      string        `json:"name"`
+	Score     float64       `json:"score"`
+	Reason    string        `json:"reason"`
+	Status    StageStatus   `json:"status,omitempty"`
+	Duration  time.Duration `json:"duration_ns"`
+	Weight    float64       `json:"weight,omitempty"`     // Relative weight within its stage, unset means 1.0
+	Cached    bool          `json:"cached,omitempty"`     // Served from the judge cache
+	SubScores []SubScore    `json:"sub_scores,omitempty"` // Per-criterion scores of rubric judges
+	Usage     *TokenUsage   `json:"usage,omitempty"`      // Tokens consumed by LLM judges, nil for prechecks and cache hits
+	Samples   *SampleStats  `json:"samples,omitempty"`    // Spread of the scores of judges sampled more than once
+	Findings  []Finding     `json:"findings,omitempty"`   // What a checker found in the answer, e.g. PII
+	Veto      bool          `json:"veto,omitempty"`       // The stage fails the evaluation whatever the other scores
+}
+
+// Finding locates something a checker detected in the answer. Start and End are byte
+// offsets into the answer; the matched text is not included so results don't repeat it.
+type Finding struct {
+	Category string `json:"category"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
+
+// Scores of a judge sampled several times for self-consistency. Score is their mean.
+type SampleStats struct {
+	Count     int       `json:"count"`            // Samples that returned a score
+	Failed    int       `json:"failed,omitempty"` // Samples that errored
+	Mean      float64   `json:"mean"`
+	Variance  float64   `json:"variance"`
+	Agreement float64   `json:"agreement"` // 1 - 2 * standard deviation: 1.0 when all samples agree, 0.0 when split between 0 and 1
+	Scores    []float64 `json:"scores"`
+}
+
+// Tokens consumed by LLM calls and their estimated cost
+type TokenUsage struct {
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost,omitempty"` // Estimated in USD from the price table, 0 when the model has no price
+}
+
+// Add accumulates other into the usage
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.Cost += other.Cost
+}
+
+// TotalTokens returns the sum of input and output tokens
+func (u TokenUsage) TotalTokens() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// TotalUsage sums the usage of the stages, nil when no stage consumed tokens
+func TotalUsage(stages []StageResult) *TokenUsage {
+	var total *TokenUsage
+	for _, stage := range stages {
+		if stage.Usage == nil {
+			continue
+		}
+		if total == nil {
+			total = &TokenUsage{}
+		}
+		total.Add(*stage.Usage)
+	}
+	return total
+}
+
+// Score of a single rubric criterion
+type SubScore struct {
+	Name     string  `json:"name"`
+	Score    float64 `json:"score"`     // Normalized to 0.0-1.0
+	RawScore float64 `json:"raw_score"` // As returned on the rubric scale
+	Weight   float64 `json:"weight"`
+	Reason   string  `json:"reason,omitempty"`
+}
+
+// EffectiveWeight returns the stage weight, treating an unset weight as 1.0
+func (s StageResult) EffectiveWeight() float64 {
+	if s.Weight == 0.0 {
+		return 1.0
+	}
+	return s.Weight
+}
+
+// OK reports whether the stage produced a score, results without a status are treated as ok
+func (s StageResult) OK() bool {
+	return s.Status == "" || s.Status == StageStatusOK
+}
+
+// Failed reports whether the stage was expected to produce a score but errored or timed out
+func (s StageResult) Failed() bool {
+	return s.Status == StageStatusError || s.Status == StageStatusTimeout
 }
 
-// Final output emitted to Kafka
+// Final output of the evaluation pipeline
 type EvaluationResult struct {
 	ID         string        `json:"id"`
 	Stages     []StageResult `json:"stages"`
 	Confidence float64       `json:"confidence"`
 	Verdict    Verdict       `json:"verdict"`
+	Profile    string        `json:"profile,omitempty"`   // Evaluation profile selected for the agent
+	Policy     string        `json:"policy,omitempty"`    // Aggregation policy that produced the verdict
+	VetoedBy   string        `json:"vetoed_by,omitempty"` // Stage whose veto rule overrode the verdict
+	Usage      *TokenUsage   `json:"usage,omitempty"`     // Sum of the stage usages
+
+	// Set by batch runs, for grouping the results of a run
+	Agent     *Agent        `json:"agent,omitempty"`
+	EventType EventType     `json:"event_type,omitempty"`
+	Duration  time.Duration `json:"duration_ns,omitempty"` // Wall time of the whole evaluation
+}
+
+// Pairwise comparison
+
+// Preference is the answer a judge prefers in a pairwise comparison
+type Preference string
+
+const (
+	PreferenceA   Preference = "a"
+	PreferenceB   Preference = "b"
+	PreferenceTie Preference = "tie"
+)
+
+// Answer of one side of a pairwise comparison
+type Candidate struct {
+	Agent  Agent  `json:"agent"`
+	Answer string `json:"answer"`
+}
+
+// Input of a pairwise comparison: one query and context answered twice, e.g. by two agent versions
+type ComparisonRequest struct {
+	EventID         string    `json:"event_id"`
+	UserQuery       string    `json:"user_query"`
+	Context         string    `json:"context,omitempty"`
+	ReferenceAnswer string    `json:"reference_answer,omitempty"` // Optional ground-truth answer
+	A               Candidate `json:"a"`
+	B               Candidate `json:"b"`
+	Judges          []string  `json:"judges,omitempty"` // Judges to compare with, all when empty
+}
+
+// Normalized pairwise comparison
+type ComparisonContext struct {
+	RequestID       string    `json:"request_id"`
+	Query           string    `json:"user_query"`
+	Context         string    `json:"context,omitempty"`
+	ReferenceAnswer string    `json:"reference_answer,omitempty"`
+	A               Candidate `json:"a"`
+	B               Candidate `json:"b"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Swapped returns the comparison with the answers in the opposite positions
+func (c ComparisonContext) Swapped() ComparisonContext {
+	c.A, c.B = c.B, c.A
+	return c
+}
+
+// One judge's preference. The judge is asked twice with the answers swapped and only
+// a preference both orders agree on counts, otherwise the result is a tie.
+type PreferenceResult struct {
+	Name       string        `json:"name"`
+	Preference Preference    `json:"preference,omitempty"`
+	Reason     string        `json:"reason"`
+	Status     StageStatus   `json:"status"`
+	Consistent bool          `json:"consistent"`       // Both orders agreed, false means the judge followed the position
+	Orders     []Preference  `json:"orders,omitempty"` // Preference in the original and in the swapped order, mapped back to a/b
+	Duration   time.Duration `json:"duration_ns"`
+	Usage      *TokenUsage   `json:"usage,omitempty"`
+}
+
+// OK reports whether the judge stated a preference
+func (p PreferenceResult) OK() bool {
+	return p.Status == StageStatusOK
+}
+
+// Final output of a pairwise comparison
+type ComparisonResult struct {
+	ID     string             `json:"id"`
+	Judges []PreferenceResult `json:"judges"`
+	Winner Preference         `json:"winner,omitempty"` // Majority preference of the judges, empty when no judge stated one
+	WinsA  int                `json:"wins_a"`
+	WinsB  int                `json:"wins_b"`
+	Ties   int                `json:"ties"`
+	Usage  *TokenUsage        `json:"usage,omitempty"`
+}
+
+// Output message published for each evaluated event
+type EvaluationOutput struct {
+	EventID     string           `json:"event_id"`
+	EventType   EventType        `json:"event_type"`
+	Agent       Agent            `json:"agent"`
+	Result      EvaluationResult `json:"result"`
+	SourceID    string           `json:"source_id,omitempty"` // ID of the input message, e.g. the Redis stream entry
+	ReceivedAt  time.Time        `json:"received_at"`
+	EvaluatedAt time.Time        `json:"evaluated_at"`
 }
@@ -8,11 +8,14 @@ import (
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 )
 
+const DefaultMinWords = 2
+
 type FormatChecker struct {
+	MinWords int
 }
 
 func NewFormatChecker() *FormatChecker {
-	return &FormatChecker{}
+	return &FormatChecker{MinWords: DefaultMinWords}
 }
 
 var repeatedPunctuation = regexp.MustCompile(`[!?.]{3,}`)
@@ -22,10 +25,16 @@ func (c *FormatChecker) Check(evaluationContext models.EvaluationContext) models
 	result := models.StageResult{
 		Name:     "format-checker",
 		Score:    0.0,
+		Status:   models.StageStatusOK,
 		Reason:   "",
 		Duration: 0,
 	}
 
+	minWords := c.MinWords
+	if minWords == 0 {
+		minWords = DefaultMinWords
+	}
+
 	now := time.Now()
 	answer := strings.TrimSpace(evaluationContext.Answer)
 
@@ -35,7 +44,8 @@ func (c *FormatChecker) Check(evaluationContext models.EvaluationContext) models
 		return result
 	}
 
-	if len(strings.Fields(answer)) < 2 {
+	// Each character of Chinese, Japanese or Thai counts as a word, they don't separate words by spaces
+	if countWords(answer) < minWords {
 		result.Reason = "Short answer"
 		result.Duration = time.Since(now)
 		return result
@@ -39,6 +39,12 @@ func TestFormatChecker(t *testing.T) {
 			score:  1.0,
 			reason: "Valid Answer",
 		},
+		{
+			name:   "Chinese characters count as words",
+			answer: "巴黎",
+			score:  1.0,
+			reason: "Valid Answer",
+		},
 		{
 			name:   "Exact two words check",
 			answer: "Hi there",
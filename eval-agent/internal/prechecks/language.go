@@ -0,0 +1,200 @@
+package prechecks
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LanguageUnknown is returned when the text is too short or has no recognizable words
+const LanguageUnknown = ""
+
+// Stop words per ISO 639-1 language code. They are dropped before comparing keywords and,
+// for languages written in the Latin script, tell the languages apart.
+var stopWordsByLanguage = map[string]map[string]bool{
+	"en": stopWords,
+	"es": wordSet("el", "la", "los", "las", "un", "una", "es", "son", "de", "del", "en", "y", "que", "por", "para", "con", "como", "se", "su", "al", "lo", "qué", "cuál", "cómo", "está", "muy"),
+	"fr": wordSet("le", "la", "les", "un", "une", "des", "est", "sont", "de", "du", "et", "que", "qui", "pour", "dans", "avec", "sur", "pas", "au", "aux", "ce", "il", "elle", "nous", "vous", "quel", "quelle", "comment"),
+	"de": wordSet("der", "die", "das", "den", "dem", "ein", "eine", "ist", "sind", "und", "zu", "mit", "für", "auf", "von", "nicht", "sich", "auch", "es", "wie", "was", "wer", "ich", "sie", "wir", "im"),
+	"it": wordSet("il", "lo", "la", "gli", "le", "un", "una", "è", "sono", "di", "del", "della", "e", "che", "per", "con", "non", "come", "nel", "alla", "qual", "quale", "cosa", "questo"),
+	"pt": wordSet("o", "os", "as", "um", "uma", "é", "são", "de", "do", "da", "dos", "das", "e", "que", "para", "com", "não", "como", "em", "no", "na", "qual", "isso", "você"),
+	"nl": wordSet("de", "het", "een", "is", "zijn", "van", "en", "dat", "die", "voor", "met", "niet", "op", "te", "hoe", "wat", "wie", "ook", "maar", "ik", "je", "we"),
+}
+
+// latinLanguages is the order stop word profiles are tried in, the first wins a tie
+var latinLanguages = []string{"en", "es", "fr", "de", "it", "pt", "nl"}
+
+func wordSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[word] = true
+	}
+	return set
+}
+
+// DetectLanguage returns the ISO 639-1 code of the text's language. Non-Latin scripts map
+// to their main language (Cyrillic is Russian unless it has Ukrainian letters); Latin text
+// is told apart by its stop words and is unknown when it has none.
+func DetectLanguage(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	ukrainian := false
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			counts["han"]++
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["cyrillic"]++
+			ukrainian = ukrainian || strings.ContainsRune("іїєґІЇЄҐ", r)
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		case unicode.Is(unicode.Latin, r):
+			counts["latin"]++
+		}
+	}
+
+	if letters == 0 {
+		return LanguageUnknown
+	}
+
+	// Japanese mixes kana with kanji, Chinese has no kana
+	if counts["ja"] > 0 && counts["ja"]+counts["han"] > letters/2 {
+		return "ja"
+	}
+
+	script, most := "", 0
+	for name, count := range counts {
+		if count > most || (count == most && name < script) {
+			script, most = name, count
+		}
+	}
+
+	switch script {
+	case "han":
+		return "zh"
+	case "cyrillic":
+		if ukrainian {
+			return "uk"
+		}
+		return "ru"
+	case "latin":
+		return detectLatinLanguage(text)
+	default:
+		return script
+	}
+}
+
+// detectLatinLanguage picks the language whose stop words occur most often in the text
+func detectLatinLanguage(text string) string {
+	words := splitWords(strings.ToLower(text))
+
+	best, bestHits := LanguageUnknown, 0
+	for _, language := range latinLanguages {
+		hits := 0
+		for _, word := range words {
+			if stopWordsByLanguage[language][word] {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = language, hits
+		}
+	}
+	return best
+}
+
+// splitWords splits the text into runs of letters and digits
+func splitWords(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.In(r, unicode.Mn, unicode.Mc)
+	})
+}
+
+// unspaced reports whether the rune belongs to a script written without spaces between words
+func unspaced(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai)
+}
+
+// tokenize lowercases the text and splits it into words. Scripts written without spaces
+// have no word boundaries to split on, so their runs are split into character bigrams.
+func tokenize(text string) []string {
+	var tokens []string
+
+	for _, word := range splitWords(strings.ToLower(text)) {
+		var run []rune
+		flush := func() {
+			if len(run) == 1 {
+				tokens = append(tokens, string(run))
+			}
+			for i := 0; i+2 <= len(run); i++ {
+				tokens = append(tokens, string(run[i:i+2]))
+			}
+			run = run[:0]
+		}
+
+		start := 0
+		for i, r := range word {
+			if !unspaced(r) {
+				continue
+			}
+			if start < i {
+				flush()
+				tokens = append(tokens, word[start:i])
+			}
+			run = append(run, r)
+			start = i + utf8.RuneLen(r)
+		}
+		flush()
+		if start < len(word) {
+			tokens = append(tokens, word[start:])
+		}
+	}
+
+	return tokens
+}
+
+// countWords counts the words of the text, each character of unspaced scripts counting as one
+func countWords(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		characters := 0
+		for _, r := range field {
+			if unspaced(r) {
+				characters++
+			}
+		}
+
+		if characters > 0 {
+			count += characters
+		} else {
+			count++
+		}
+	}
+	return count
+}
+
+// stopWordsFor returns the stop words of the language, English when it has none
+func stopWordsFor(language string) map[string]bool {
+	if words, ok := stopWordsByLanguage[language]; ok {
+		return words
+	}
+	return stopWords
+}
@@ -0,0 +1,45 @@
+package prechecks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// LanguageChecker scores 1.0 when the answer is written in the language of the query and
+// 0.0 when the agent answered in another language. It is skipped when the language of
+// either can't be detected, e.g. for a one-word query.
+type LanguageChecker struct{}
+
+func NewLanguageChecker() *LanguageChecker {
+	return &LanguageChecker{}
+}
+
+func (c *LanguageChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := models.StageResult{
+		Name:   "language-checker",
+		Status: models.StageStatusOK,
+	}
+	now := time.Now()
+
+	queryLanguage := DetectLanguage(evaluationContext.Query)
+	answerLanguage := DetectLanguage(evaluationContext.Answer)
+	result.Duration = time.Since(now)
+
+	switch {
+	case queryLanguage == LanguageUnknown:
+		result.Status = models.StageStatusSkipped
+		result.Reason = "Could not detect the language of the query"
+	case answerLanguage == LanguageUnknown:
+		result.Status = models.StageStatusSkipped
+		result.Reason = "Could not detect the language of the answer"
+	case queryLanguage != answerLanguage:
+		result.Reason = fmt.Sprintf("Answer is in %s but the query is in %s", answerLanguage, queryLanguage)
+	default:
+		result.Score = 1.0
+		result.Reason = fmt.Sprintf("Answer is in the language of the query (%s)", queryLanguage)
+	}
+
+	return result
+}
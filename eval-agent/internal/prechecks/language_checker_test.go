@@ -0,0 +1,111 @@
+package prechecks
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"What is the capital of France?", "en"},
+		{"¿Cuál es la capital de Francia?", "es"},
+		{"Quelle est la capitale de la France ?", "fr"},
+		{"Was ist die Hauptstadt von Frankreich?", "de"},
+		{"Qual è la capitale della Francia?", "it"},
+		{"Qual é a capital da França?", "pt"},
+		{"Wat is de hoofdstad van Frankrijk?", "nl"},
+		{"Какая столица Франции?", "ru"},
+		{"Яка столиця Франції?", "uk"},
+		{"法国的首都是哪里？", "zh"},
+		{"フランスの首都はどこですか？", "ja"},
+		{"프랑스의 수도는 어디입니까?", "ko"},
+		{"ما هي عاصمة فرنسا؟", "ar"},
+		{"Ποια είναι η πρωτεύουσα της Γαλλίας;", "el"},
+		{"Paris", LanguageUnknown},
+		{"42 + 7", LanguageUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLanguageChecker(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		answer string
+		score  float64
+		status models.StageStatus
+		reason string
+	}{
+		{
+			name:   "Same language",
+			query:  "¿Cuál es la capital de Francia?",
+			answer: "La capital de Francia es París.",
+			score:  1.0,
+			status: models.StageStatusOK,
+			reason: "Answer is in the language of the query (es)",
+		},
+		{
+			name:   "Wrong language",
+			query:  "¿Cuál es la capital de Francia?",
+			answer: "The capital of France is Paris.",
+			score:  0.0,
+			status: models.StageStatusOK,
+			reason: "Answer is in en but the query is in es",
+		},
+		{
+			name:   "Different script",
+			query:  "フランスの首都はどこですか？",
+			answer: "The capital of France is Paris.",
+			score:  0.0,
+			status: models.StageStatusOK,
+			reason: "Answer is in en but the query is in ja",
+		},
+		{
+			name:   "Undetectable answer",
+			query:  "What is the capital of France?",
+			answer: "Paris",
+			status: models.StageStatusSkipped,
+			reason: "Could not detect the language of the answer",
+		},
+	}
+
+	checker := NewLanguageChecker()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.Check(models.EvaluationContext{Query: tt.query, Answer: tt.answer})
+
+			if result.Score != tt.score || result.Status != tt.status || result.Reason != tt.reason {
+				t.Errorf("Got %f %s %q, want %f %s %q", result.Score, result.Status, result.Reason, tt.score, tt.status, tt.reason)
+			}
+		})
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello, World! It's Go.", []string{"hello", "world", "it", "s", "go"}},
+		{"Über größe", []string{"über", "größe"}},
+		{"東京都", []string{"東京", "京都"}},
+		{"Go言語", []string{"go", "言語"}},
+	}
+
+	for _, tt := range tests {
+		if got := tokenize(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -3,30 +3,49 @@ package prechecks
 import (
 	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 )
 
+const (
+	DefaultMinRatio = 0.5
+	DefaultMaxRatio = 10.0
+)
+
 type LengthChecker struct {
+	MinRatio float64
+	MaxRatio float64
 }
 
 func NewLengthChecker() *LengthChecker {
-	return &LengthChecker{}
+	return &LengthChecker{
+		MinRatio: DefaultMinRatio,
+		MaxRatio: DefaultMaxRatio,
+	}
 }
 
 // LengthChecker scores an answer based on its length relative to the query.
-// It computes the character ratio between answer and query, penalizing answers
+// It computes the character (rune) ratio between answer and query, penalizing answers
 // that are too short (score 0.0) or excessively long (score 0.5).
+// Unset ratios fall back to DefaultMinRatio and DefaultMaxRatio.
 func (c *LengthChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
-	const minRatio = 0.5
-	const maxRatio = 10.0
+	minRatio := c.MinRatio
+	if minRatio == 0.0 {
+		minRatio = DefaultMinRatio
+	}
+	maxRatio := c.MaxRatio
+	if maxRatio == 0.0 {
+		maxRatio = DefaultMaxRatio
+	}
 
-	answerLength := len(evaluationContext.Answer)
-	queryLength := len(evaluationContext.Query)
+	answerLength := utf8.RuneCountInString(evaluationContext.Answer)
+	queryLength := utf8.RuneCountInString(evaluationContext.Query)
 
 	result := models.StageResult{
 		Name:     "length-checker",
 		Score:    0.0,
+		Status:   models.StageStatusOK,
 		Reason:   "",
 		Duration: 0,
 	}
@@ -31,6 +31,13 @@ func TestLengthChecker(t *testing.T) {
 			wantScore:  0,
 			wantReason: "fewer characters",
 		},
+		{
+			name:       "ratio counts characters, not bytes",
+			query:      "日本の首都は",
+			answer:     "東京",
+			wantScore:  0,
+			wantReason: "fewer characters",
+		},
 		{
 			name:       "no overlap",
 			query:      "hi",
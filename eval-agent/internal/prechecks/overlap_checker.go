@@ -2,12 +2,14 @@ package prechecks
 
 import (
 	"fmt"
-	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
 )
 
+const DefaultMinOverlapThreshold = 0.1
+
 type OverlapChecker struct {
 	MinOverlapThreshold float64
 }
@@ -19,16 +21,17 @@ func NewOverlapChecker() *OverlapChecker {
 // OverlapChecker scores an answer based on keyword overlap with the query.
 // It tokenizes both strings, computes the ratio of shared unique words,
 // and returns a low score if the answer doesn't share enough terms with the query.
+// Stop words are those of the detected language of each string.
 func (c *OverlapChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
-
-	if c.MinOverlapThreshold == 0.0 {
-		// set default value
-		c.MinOverlapThreshold = 0.1
+	minOverlap := c.MinOverlapThreshold
+	if minOverlap == 0.0 {
+		minOverlap = DefaultMinOverlapThreshold
 	}
 
 	result := models.StageResult{
 		Name:     "overlap-checker",
 		Score:    0.0,
+		Status:   models.StageStatusOK,
 		Reason:   "",
 		Duration: 0,
 	}
@@ -60,7 +63,7 @@ func (c *OverlapChecker) Check(evaluationContext models.EvaluationContext) model
 	}
 
 	score := float64(count) / float64(len(uniqueQueryTokens))
-	if score < c.MinOverlapThreshold {
+	if score < minOverlap {
 		result.Reason = fmt.Sprintf("Low keyword overlap: %.0f%% of query terms found in answer", score*100)
 		result.Score = score
 	} else {
@@ -81,6 +84,7 @@ func extractUniqueTokens(tokens []string) map[string]bool {
 	return unique
 }
 
+// English stop words, also used for text of undetected language
 var stopWords = map[string]bool{
 	"a": true, "an": true, "the": true, "is": true, "are": true,
 	"was": true, "were": true, "be": true, "been": true, "being": true,
@@ -93,24 +97,14 @@ var stopWords = map[string]bool{
 }
 
 func (c *OverlapChecker) stringTokenizer(s string) []string {
-	s = strings.ToLower(s)
-	s = removePunctuation(s)
+	stop := stopWordsFor(DetectLanguage(s))
 
 	tokens := []string{}
-	for word := range strings.FieldsSeq(s) {
-		if !stopWords[word] && len(word) > 1 {
+	for _, word := range tokenize(s) {
+		if !stop[word] && utf8.RuneCountInString(word) > 1 {
 			tokens = append(tokens, word)
 		}
 	}
 	return tokens
 
 }
-
-func removePunctuation(s string) string {
-	return strings.Map(func(r rune) rune {
-		if strings.ContainsRune(".,!?;:()[]{}\"'", r) {
-			return -1 // Remove this rune
-		}
-		return r
-	}, s)
-}
@@ -47,6 +47,20 @@ func TestOverlapChecker(t *testing.T) {
 			score:  1.0,
 			reason: "There is a good overlap",
 		},
+		{
+			name:   "Spanish stop words",
+			query:  "¿Cuál es la capital de Francia?",
+			answer: "La capital de Francia es París.",
+			score:  1.0,
+			reason: "There is a good overlap",
+		},
+		{
+			name:   "Chinese without spaces",
+			query:  "法国首都",
+			answer: "法国的首都是巴黎",
+			score:  2.0 / 3.0, // Bigrams 法国 and 首都 are found, 国首 is not
+			reason: "There is a good overlap",
+		},
 		{
 			name:   "Partial overlap",
 			query:  "foo bar baz",
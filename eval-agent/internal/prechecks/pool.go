@@ -0,0 +1,180 @@
+package prechecks
+
+import (
+	"fmt"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+// CheckerPool builds the stage 1 checkers from configuration
+type CheckerPool struct {
+	embedder embedding.Embedder
+	logger   *zerolog.Logger
+}
+
+// NewCheckerPool creates a new checker pool builder
+func NewCheckerPool(logger *zerolog.Logger) *CheckerPool {
+	return &CheckerPool{
+		logger: logger,
+	}
+}
+
+// WithEmbedder sets the embedder used by the semantic checker
+func (p *CheckerPool) WithEmbedder(embedder embedding.Embedder) *CheckerPool {
+	p.embedder = embedder
+	return p
+}
+
+func (p *CheckerPool) BuildFromConfig(cfg *config.PrechecksConfig) ([]Checker, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("prechecks config is nil")
+	}
+
+	var checkers []Checker
+
+	for _, checkerCfg := range cfg.Prechecks.Checkers {
+		// Skip disabled checkers
+		if !checkerCfg.Enabled {
+			p.logger.Info().
+				Str("precheck", checkerCfg.Name).
+				Msg("precheck disabled in config, skipping")
+			continue
+		}
+
+		checker, err := p.newChecker(checkerCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create precheck %s: %w", checkerCfg.Name, err)
+		}
+
+		checkers = append(checkers, &weightedChecker{
+			checker: checker,
+			weight:  checkerCfg.Weight,
+		})
+
+		p.logger.Info().
+			Str("precheck", checkerCfg.Name).
+			Float64("weight", checkerCfg.Weight).
+			Interface("thresholds", checkerCfg.Thresholds).
+			Msg("precheck created successfully")
+	}
+
+	if len(checkers) == 0 {
+		return nil, fmt.Errorf("no enabled prechecks found in config")
+	}
+
+	p.logger.Info().
+		Int("total_prechecks", len(checkers)).
+		Msg("precheck pool built successfully")
+
+	return checkers, nil
+}
+
+func (p *CheckerPool) newChecker(cfg config.PrecheckConfiguration) (Checker, error) {
+	switch cfg.Name {
+	case "length":
+		if err := allowThresholds(cfg, "min_ratio", "max_ratio"); err != nil {
+			return nil, err
+		}
+		checker := &LengthChecker{
+			MinRatio: cfg.Threshold("min_ratio", DefaultMinRatio),
+			MaxRatio: cfg.Threshold("max_ratio", DefaultMaxRatio),
+		}
+		if checker.MinRatio >= checker.MaxRatio {
+			return nil, fmt.Errorf("min_ratio %f must be below max_ratio %f", checker.MinRatio, checker.MaxRatio)
+		}
+		return checker, nil
+	case "overlap":
+		if err := allowThresholds(cfg, "min_overlap"); err != nil {
+			return nil, err
+		}
+		minOverlap := cfg.Threshold("min_overlap", DefaultMinOverlapThreshold)
+		if minOverlap > 1.0 {
+			return nil, fmt.Errorf("min_overlap %f out of range [0.0, 1.0]", minOverlap)
+		}
+		return &OverlapChecker{MinOverlapThreshold: minOverlap}, nil
+	case "format":
+		if err := allowThresholds(cfg, "min_words"); err != nil {
+			return nil, err
+		}
+		return &FormatChecker{MinWords: int(cfg.Threshold("min_words", DefaultMinWords))}, nil
+	case MetricExactMatch:
+		if err := allowThresholds(cfg, "contains"); err != nil {
+			return nil, err
+		}
+		return &ReferenceChecker{Metric: MetricExactMatch, Contains: cfg.Threshold("contains", 0) > 0}, nil
+	case MetricTokenF1, MetricRougeL:
+		if err := allowThresholds(cfg); err != nil {
+			return nil, err
+		}
+		return NewReferenceChecker(cfg.Name), nil
+	case MetricBLEU:
+		if err := allowThresholds(cfg, "max_n"); err != nil {
+			return nil, err
+		}
+		maxN := int(cfg.Threshold("max_n", DefaultMaxN))
+		if maxN < 1 {
+			return nil, fmt.Errorf("max_n %d must be at least 1", maxN)
+		}
+		return &ReferenceChecker{Metric: MetricBLEU, MaxN: maxN}, nil
+	case "language":
+		if err := allowThresholds(cfg); err != nil {
+			return nil, err
+		}
+		return NewLanguageChecker(), nil
+	case "pii":
+		if err := allowThresholds(cfg, "veto"); err != nil {
+			return nil, err
+		}
+		return &PIIChecker{Veto: cfg.Threshold("veto", 0) > 0}, nil
+	case "structure":
+		if err := allowThresholds(cfg); err != nil {
+			return nil, err
+		}
+		return &StructureChecker{Format: cfg.OutputFormat}, nil
+	case "semantic":
+		if err := allowThresholds(cfg, "min_query_similarity", "min_context_similarity"); err != nil {
+			return nil, err
+		}
+		if p.embedder == nil {
+			return nil, fmt.Errorf("semantic precheck requires an embedder")
+		}
+		checker := NewSemanticChecker(p.embedder)
+		checker.MinQuerySimilarity = cfg.Threshold("min_query_similarity", DefaultMinQuerySimilarity)
+		checker.MinContextSimilarity = cfg.Threshold("min_context_similarity", DefaultMinContextSimilarity)
+		if checker.MinQuerySimilarity > 1.0 || checker.MinContextSimilarity > 1.0 {
+			return nil, fmt.Errorf("similarity thresholds out of range [0.0, 1.0]")
+		}
+		return checker, nil
+	default:
+		return nil, fmt.Errorf("unknown precheck type")
+	}
+}
+
+func allowThresholds(cfg config.PrecheckConfiguration, keys ...string) error {
+	allowed := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		allowed[key] = true
+	}
+
+	for key := range cfg.Thresholds {
+		if !allowed[key] {
+			return fmt.Errorf("unknown threshold %q", key)
+		}
+	}
+	return nil
+}
+
+// weightedChecker stamps the configured weight on every result of the wrapped checker
+type weightedChecker struct {
+	checker Checker
+	weight  float64
+}
+
+func (c *weightedChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := c.checker.Check(evaluationContext)
+	result.Weight = c.weight
+	return result
+}
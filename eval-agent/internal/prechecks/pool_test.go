@@ -0,0 +1,186 @@
+package prechecks
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestCheckerPool_BuildFromConfig_Success(t *testing.T) {
+	logger := zerolog.Nop()
+	pool := NewCheckerPool(&logger)
+
+	cfg := &config.PrechecksConfig{
+		Prechecks: config.Prechecks{
+			Checkers: []config.PrecheckConfiguration{
+				{Name: "length", Enabled: true, Weight: 2.0, Thresholds: map[string]float64{"min_ratio": 0.1, "max_ratio": 50}},
+				{Name: "overlap", Enabled: false, Weight: 1.0},
+				{Name: "format", Enabled: true, Weight: 1.0},
+			},
+		},
+	}
+
+	checkers, err := pool.BuildFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+
+	if len(checkers) != 2 {
+		t.Fatalf("Expected 2 enabled checkers, got %d", len(checkers))
+	}
+
+	// A 30x longer answer is acceptable with max_ratio 50 but not with the default 10
+	result := checkers[0].Check(models.EvaluationContext{
+		Query:  "hi",
+		Answer: strings.Repeat("a", 60),
+	})
+	if result.Score != 1.0 {
+		t.Errorf("Expected configured ratios to accept answer, got score %f (%s)", result.Score, result.Reason)
+	}
+	if result.Weight != 2.0 {
+		t.Errorf("Expected weight=2.0 on result, got %f", result.Weight)
+	}
+}
+
+func TestCheckerPool_BuildFromConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		checkers []config.PrecheckConfiguration
+		wantErr  string
+	}{
+		{
+			name:     "unknown checker",
+			checkers: []config.PrecheckConfiguration{{Name: "spelling", Enabled: true}},
+			wantErr:  "unknown precheck type",
+		},
+		{
+			name:     "unknown threshold",
+			checkers: []config.PrecheckConfiguration{{Name: "format", Enabled: true, Thresholds: map[string]float64{"max_words": 3}}},
+			wantErr:  "unknown threshold",
+		},
+		{
+			name:     "overlap out of range",
+			checkers: []config.PrecheckConfiguration{{Name: "overlap", Enabled: true, Thresholds: map[string]float64{"min_overlap": 1.5}}},
+			wantErr:  "out of range",
+		},
+		{
+			name:     "bleu without n-grams",
+			checkers: []config.PrecheckConfiguration{{Name: "bleu", Enabled: true, Thresholds: map[string]float64{"max_n": 0}}},
+			wantErr:  "must be at least 1",
+		},
+		{
+			name:     "threshold on token f1",
+			checkers: []config.PrecheckConfiguration{{Name: "token_f1", Enabled: true, Thresholds: map[string]float64{"min_f1": 0.5}}},
+			wantErr:  "unknown threshold",
+		},
+		{
+			name:     "threshold on structure",
+			checkers: []config.PrecheckConfiguration{{Name: "structure", Enabled: true, Thresholds: map[string]float64{"items": 3}}},
+			wantErr:  "unknown threshold",
+		},
+		{
+			name:     "semantic without embedder",
+			checkers: []config.PrecheckConfiguration{{Name: "semantic", Enabled: true}},
+			wantErr:  "requires an embedder",
+		},
+		{
+			name:     "no enabled checkers",
+			checkers: []config.PrecheckConfiguration{{Name: "format", Enabled: false}},
+			wantErr:  "no enabled prechecks found in config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			pool := NewCheckerPool(&logger)
+
+			_, err := pool.BuildFromConfig(&config.PrechecksConfig{
+				Prechecks: config.Prechecks{Checkers: tt.checkers},
+			})
+			if err == nil {
+				t.Fatalf("Expected error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected %q error, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckerPool_BuildFromConfig_NilConfig(t *testing.T) {
+	logger := zerolog.Nop()
+	pool := NewCheckerPool(&logger)
+
+	_, err := pool.BuildFromConfig(nil)
+	if err == nil || err.Error() != "prechecks config is nil" {
+		t.Errorf("Expected 'prechecks config is nil' error, got: %v", err)
+	}
+}
+
+func TestCheckerPool_BuildFromConfig_Semantic(t *testing.T) {
+	logger := zerolog.Nop()
+	pool := NewCheckerPool(&logger).WithEmbedder(embedding.NewHashEmbedder(0))
+
+	checkers, err := pool.BuildFromConfig(&config.PrechecksConfig{
+		Prechecks: config.Prechecks{
+			Checkers: []config.PrecheckConfiguration{
+				{Name: "semantic", Enabled: true, Weight: 1.0, Thresholds: map[string]float64{"min_query_similarity": 0.9}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+
+	result := checkers[0].Check(models.EvaluationContext{Query: "What is Go?", Answer: "Go is a programming language"})
+	if !strings.Contains(result.Reason, "min 0.90") {
+		t.Errorf("Expected configured threshold in reason, got %q", result.Reason)
+	}
+}
+
+func TestCheckerPool_BuildFromConfig_PIIVeto(t *testing.T) {
+	logger := zerolog.Nop()
+	pool := NewCheckerPool(&logger)
+
+	checkers, err := pool.BuildFromConfig(&config.PrechecksConfig{
+		Prechecks: config.Prechecks{
+			Checkers: []config.PrecheckConfiguration{
+				{Name: "pii", Enabled: true, Weight: 1.0, Thresholds: map[string]float64{"veto": 1}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+
+	result := checkers[0].Check(models.EvaluationContext{Query: "q", Answer: "Mail me at jane@example.com"})
+	if !result.Veto {
+		t.Errorf("Expected configured veto on finding, got %+v", result)
+	}
+}
+
+func TestCheckerPool_BuildFromConfig_StructureFormat(t *testing.T) {
+	logger := zerolog.Nop()
+	pool := NewCheckerPool(&logger)
+
+	checkers, err := pool.BuildFromConfig(&config.PrechecksConfig{
+		Prechecks: config.Prechecks{
+			Checkers: []config.PrecheckConfiguration{
+				{Name: "structure", Enabled: true, Weight: 1.0, OutputFormat: &config.OutputFormat{Type: "list", Items: 2}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BuildFromConfig failed: %v", err)
+	}
+
+	result := checkers[0].Check(models.EvaluationContext{Query: "How do I start?", Answer: "1. Install\n2. Run\n3. Deploy"})
+	if result.Reason != "List has 3 items, 2 requested" {
+		t.Errorf("Expected configured format to be checked, got %q", result.Reason)
+	}
+}
@@ -0,0 +1,237 @@
+package prechecks
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// Reference metrics compare the answer to the request's reference answer
+const (
+	MetricExactMatch = "exact_match"
+	MetricTokenF1    = "token_f1"
+	MetricRougeL     = "rouge_l"
+	MetricBLEU       = "bleu"
+)
+
+const DefaultMaxN = 4
+
+// ReferenceChecker scores the answer against the reference answer with a lexical metric.
+// Both texts are normalized the way SQuAD does: lowercased, punctuation and the articles
+// a, an and the removed. The checker is skipped when the request has no reference answer.
+type ReferenceChecker struct {
+	Metric string
+	// Contains makes exact_match score 1.0 when the normalized reference appears in the
+	// answer, for short answers such as Natural Questions' short_answer
+	Contains bool
+	// MaxN is the highest n-gram order used by BLEU, DefaultMaxN when unset
+	MaxN int
+}
+
+func NewReferenceChecker(metric string) *ReferenceChecker {
+	return &ReferenceChecker{Metric: metric}
+}
+
+func (c *ReferenceChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := models.StageResult{
+		Name:   fmt.Sprintf("%s-checker", c.Metric),
+		Status: models.StageStatusOK,
+	}
+	now := time.Now()
+
+	if strings.TrimSpace(evaluationContext.ReferenceAnswer) == "" {
+		result.Status = models.StageStatusSkipped
+		result.Reason = "No reference answer provided"
+		return result
+	}
+
+	answer := normalizeAnswer(evaluationContext.Answer)
+	reference := normalizeAnswer(evaluationContext.ReferenceAnswer)
+
+	switch c.Metric {
+	case MetricExactMatch:
+		result.Score = exactMatch(answer, reference, c.Contains)
+	case MetricTokenF1:
+		result.Score = tokenF1(answer, reference)
+	case MetricRougeL:
+		result.Score = rougeL(answer, reference)
+	case MetricBLEU:
+		maxN := c.MaxN
+		if maxN == 0 {
+			maxN = DefaultMaxN
+		}
+		result.Score = bleu(answer, reference, maxN)
+	default:
+		result.Status = models.StageStatusError
+		result.Reason = fmt.Sprintf("Unknown reference metric %q", c.Metric)
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	result.Reason = fmt.Sprintf("%s against the reference answer: %.2f", c.Metric, result.Score)
+	result.Duration = time.Since(now)
+	return result
+}
+
+var articles = map[string]bool{"a": true, "an": true, "the": true}
+
+// normalizeAnswer lowercases the text, drops punctuation and articles and splits it into tokens
+func normalizeAnswer(s string) []string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+
+	tokens := []string{}
+	for word := range strings.FieldsSeq(s) {
+		if !articles[word] {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+// exactMatch is 1.0 when the token sequences are equal, or with contains when the
+// reference is a contiguous run of answer tokens
+func exactMatch(answer []string, reference []string, contains bool) float64 {
+	if contains && len(reference) > 0 {
+		for i := 0; i+len(reference) <= len(answer); i++ {
+			if equalTokens(answer[i:i+len(reference)], reference) {
+				return 1.0
+			}
+		}
+		return 0.0
+	}
+
+	if equalTokens(answer, reference) {
+		return 1.0
+	}
+	return 0.0
+}
+
+func equalTokens(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenF1 is the harmonic mean of precision and recall over the tokens shared by
+// answer and reference, counting repeated tokens as often as both contain them
+func tokenF1(answer []string, reference []string) float64 {
+	if len(answer) == 0 || len(reference) == 0 {
+		if len(answer) == len(reference) {
+			return 1.0
+		}
+		return 0.0
+	}
+
+	counts := make(map[string]int, len(reference))
+	for _, token := range reference {
+		counts[token]++
+	}
+
+	common := 0
+	for _, token := range answer {
+		if counts[token] > 0 {
+			counts[token]--
+			common++
+		}
+	}
+	if common == 0 {
+		return 0.0
+	}
+
+	precision := float64(common) / float64(len(answer))
+	recall := float64(common) / float64(len(reference))
+	return 2 * precision * recall / (precision + recall)
+}
+
+// rougeL is the F-measure of the longest common subsequence of answer and reference
+func rougeL(answer []string, reference []string) float64 {
+	if len(answer) == 0 || len(reference) == 0 {
+		return 0.0
+	}
+
+	lcs := longestCommonSubsequence(answer, reference)
+	if lcs == 0 {
+		return 0.0
+	}
+
+	precision := float64(lcs) / float64(len(answer))
+	recall := float64(lcs) / float64(len(reference))
+	return 2 * precision * recall / (precision + recall)
+}
+
+func longestCommonSubsequence(a []string, b []string) int {
+	// Two rows of the dynamic programming table are enough for the length
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				curr[j] = prev[j-1] + 1
+			} else {
+				curr[j] = max(prev[j], curr[j-1])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// bleu is the sentence-level BLEU score with a single reference: the geometric mean of
+// the clipped n-gram precisions up to maxN times the brevity penalty. Precisions above
+// unigrams use add-one smoothing so short answers don't score 0 for a missing 4-gram.
+func bleu(answer []string, reference []string, maxN int) float64 {
+	if len(answer) == 0 || len(reference) == 0 {
+		return 0.0
+	}
+
+	logPrecision := 0.0
+	for n := 1; n <= maxN; n++ {
+		referenceCounts := ngramCounts(reference, n)
+
+		matches, total := 0, 0
+		for ngram, count := range ngramCounts(answer, n) {
+			matches += min(count, referenceCounts[ngram])
+			total += count
+		}
+
+		if n == 1 {
+			if matches == 0 {
+				return 0.0
+			}
+			logPrecision += math.Log(float64(matches) / float64(total))
+			continue
+		}
+		logPrecision += math.Log(float64(matches+1) / float64(total+1))
+	}
+
+	brevityPenalty := 1.0
+	if len(answer) < len(reference) {
+		brevityPenalty = math.Exp(1 - float64(len(reference))/float64(len(answer)))
+	}
+
+	return brevityPenalty * math.Exp(logPrecision/float64(maxN))
+}
+
+func ngramCounts(tokens []string, n int) map[string]int {
+	counts := make(map[string]int)
+	for i := 0; i+n <= len(tokens); i++ {
+		counts[strings.Join(tokens[i:i+n], " ")]++
+	}
+	return counts
+}
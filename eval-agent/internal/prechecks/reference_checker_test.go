@@ -0,0 +1,113 @@
+package prechecks
+
+import (
+	"math"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+func TestReferenceChecker(t *testing.T) {
+	tests := []struct {
+		name      string
+		checker   *ReferenceChecker
+		answer    string
+		reference string
+		score     float64
+	}{
+		{
+			name:      "exact match ignores case, punctuation and articles",
+			checker:   NewReferenceChecker(MetricExactMatch),
+			answer:    "The Eiffel Tower!",
+			reference: "eiffel tower",
+			score:     1.0,
+		},
+		{
+			name:      "exact match of a longer answer",
+			checker:   NewReferenceChecker(MetricExactMatch),
+			answer:    "It is the Eiffel Tower",
+			reference: "Eiffel Tower",
+			score:     0.0,
+		},
+		{
+			name:      "exact match contained in the answer",
+			checker:   &ReferenceChecker{Metric: MetricExactMatch, Contains: true},
+			answer:    "It is the Eiffel Tower in Paris",
+			reference: "Eiffel Tower",
+			score:     1.0,
+		},
+		{
+			name:      "token f1 with partial overlap",
+			checker:   NewReferenceChecker(MetricTokenF1),
+			answer:    "paris france",
+			reference: "paris",
+			score:     2.0 / 3.0,
+		},
+		{
+			name:      "token f1 without overlap",
+			checker:   NewReferenceChecker(MetricTokenF1),
+			answer:    "london",
+			reference: "paris",
+			score:     0.0,
+		},
+		{
+			name:      "rouge-l follows word order",
+			checker:   NewReferenceChecker(MetricRougeL),
+			answer:    "police killed the gunman",
+			reference: "the police kill the gunman",
+			// LCS "police gunman" of 3 tokens each once the articles are dropped
+			score: 2.0 / 3.0,
+		},
+		{
+			name:      "bleu of identical texts",
+			checker:   NewReferenceChecker(MetricBLEU),
+			answer:    "go is a statically typed compiled language",
+			reference: "Go is a statically typed, compiled language.",
+			score:     1.0,
+		},
+		{
+			name:      "bleu without shared words",
+			checker:   NewReferenceChecker(MetricBLEU),
+			answer:    "completely different",
+			reference: "go is a language",
+			score:     0.0,
+		},
+		{
+			name:      "bleu unigrams with brevity penalty",
+			checker:   &ReferenceChecker{Metric: MetricBLEU, MaxN: 1},
+			answer:    "go language",
+			reference: "go is a compiled language",
+			// 4 reference tokens after dropping "a", precision 1
+			score: math.Exp(1 - 4.0/2.0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.checker.Check(models.EvaluationContext{
+				Query:           "q",
+				Answer:          tt.answer,
+				ReferenceAnswer: tt.reference,
+			})
+
+			if result.Status != models.StageStatusOK {
+				t.Fatalf("Expected status ok, got %s (%s)", result.Status, result.Reason)
+			}
+			if math.Abs(result.Score-tt.score) > 1e-9 {
+				t.Errorf("Expected score %f, got %f", tt.score, result.Score)
+			}
+			if result.Name != tt.checker.Metric+"-checker" {
+				t.Errorf("Unexpected name %s", result.Name)
+			}
+		})
+	}
+}
+
+func TestReferenceChecker_NoReference(t *testing.T) {
+	for _, metric := range []string{MetricExactMatch, MetricTokenF1, MetricRougeL, MetricBLEU} {
+		result := NewReferenceChecker(metric).Check(models.EvaluationContext{Query: "q", Answer: "a"})
+		if result.Status != models.StageStatusSkipped {
+			t.Errorf("%s: expected status skipped without a reference, got %s", metric, result.Status)
+		}
+	}
+}
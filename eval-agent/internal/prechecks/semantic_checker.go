@@ -0,0 +1,110 @@
+package prechecks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+const (
+	DefaultMinQuerySimilarity   = 0.2
+	DefaultMinContextSimilarity = 0.2
+	DefaultEmbeddingTimeout     = 5 * time.Second
+)
+
+// SemanticChecker scores an answer by the embedding similarity to the query and, when the
+// request has one, to the context. Unlike the OverlapChecker it doesn't need the answer to
+// repeat the query's words, so paraphrased answers still score well with a model embedder.
+type SemanticChecker struct {
+	Embedder             embedding.Embedder
+	MinQuerySimilarity   float64
+	MinContextSimilarity float64
+	Timeout              time.Duration
+}
+
+func NewSemanticChecker(embedder embedding.Embedder) *SemanticChecker {
+	return &SemanticChecker{
+		Embedder:             embedder,
+		MinQuerySimilarity:   DefaultMinQuerySimilarity,
+		MinContextSimilarity: DefaultMinContextSimilarity,
+		Timeout:              DefaultEmbeddingTimeout,
+	}
+}
+
+// Check scores the mean of the query–answer and answer–context cosine similarities,
+// negative similarities count as 0.0. The reason names the similarities below their minimum.
+func (c *SemanticChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := models.StageResult{
+		Name:   "semantic-checker",
+		Status: models.StageStatusOK,
+	}
+	now := time.Now()
+
+	if strings.TrimSpace(evaluationContext.Query) == "" {
+		result.Reason = "Empty query"
+		return result
+	}
+	if strings.TrimSpace(evaluationContext.Answer) == "" {
+		result.Reason = "Empty answer"
+		return result
+	}
+
+	texts := []string{evaluationContext.Answer, evaluationContext.Query}
+	hasContext := strings.TrimSpace(evaluationContext.Context) != ""
+	if hasContext {
+		texts = append(texts, evaluationContext.Context)
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultEmbeddingTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	vectors, err := c.Embedder.Embed(ctx, texts)
+	result.Duration = time.Since(now)
+	if err != nil {
+		result.Status = models.StageStatusError
+		if ctx.Err() != nil {
+			result.Status = models.StageStatusTimeout
+		}
+		result.Reason = fmt.Sprintf("Embedding failed: %v", err)
+		return result
+	}
+
+	querySimilarity := max(embedding.Cosine(vectors[0], vectors[1]), 0.0)
+	similarities := []float64{querySimilarity}
+
+	var low []string
+	if querySimilarity < c.MinQuerySimilarity {
+		low = append(low, fmt.Sprintf("query %.2f (min %.2f)", querySimilarity, c.MinQuerySimilarity))
+	}
+
+	if hasContext {
+		contextSimilarity := max(embedding.Cosine(vectors[0], vectors[2]), 0.0)
+		similarities = append(similarities, contextSimilarity)
+		if contextSimilarity < c.MinContextSimilarity {
+			low = append(low, fmt.Sprintf("context %.2f (min %.2f)", contextSimilarity, c.MinContextSimilarity))
+		}
+	}
+
+	for _, similarity := range similarities {
+		result.Score += similarity
+	}
+	result.Score /= float64(len(similarities))
+
+	if len(low) > 0 {
+		result.Reason = fmt.Sprintf("Low semantic similarity to the %s", strings.Join(low, " and "))
+	} else if hasContext {
+		result.Reason = fmt.Sprintf("Answer is semantically similar to the query (%.2f) and context (%.2f)", similarities[0], similarities[1])
+	} else {
+		result.Reason = fmt.Sprintf("Answer is semantically similar to the query (%.2f)", similarities[0])
+	}
+
+	return result
+}
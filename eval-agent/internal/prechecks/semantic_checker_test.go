@@ -0,0 +1,120 @@
+package prechecks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+// fixedEmbedder returns the configured vector of each text
+type fixedEmbedder map[string][]float64
+
+func (e fixedEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = e[text]
+	}
+	return vectors, nil
+}
+
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestSemanticChecker(t *testing.T) {
+	embedder := fixedEmbedder{
+		"query":     {1, 0},
+		"answer":    {1, 1},
+		"context":   {0, 1},
+		"unrelated": {-1, 0},
+	}
+
+	tests := []struct {
+		name    string
+		evalCtx models.EvaluationContext
+		score   float64
+		reason  string
+	}{
+		{
+			name:    "Empty query",
+			evalCtx: models.EvaluationContext{Answer: "answer"},
+			score:   0.0,
+			reason:  "Empty query",
+		},
+		{
+			name:    "Empty answer",
+			evalCtx: models.EvaluationContext{Query: "query"},
+			score:   0.0,
+			reason:  "Empty answer",
+		},
+		{
+			name:    "Query only",
+			evalCtx: models.EvaluationContext{Query: "query", Answer: "answer"},
+			score:   0.7071067811865475,
+			reason:  "Answer is semantically similar to the query (0.71)",
+		},
+		{
+			name:    "Query and context",
+			evalCtx: models.EvaluationContext{Query: "query", Answer: "answer", Context: "context"},
+			score:   0.7071067811865475,
+			reason:  "Answer is semantically similar to the query (0.71) and context (0.71)",
+		},
+		{
+			name:    "Opposite meaning counts as zero",
+			evalCtx: models.EvaluationContext{Query: "query", Answer: "unrelated"},
+			score:   0.0,
+			reason:  "Low semantic similarity to the query 0.00 (min 0.20)",
+		},
+	}
+
+	checker := NewSemanticChecker(embedder)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.Check(tt.evalCtx)
+
+			if result.Status != models.StageStatusOK {
+				t.Errorf("Expected status ok, got %s", result.Status)
+			}
+			if diff := result.Score - tt.score; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Expected score %f, got %f", tt.score, result.Score)
+			}
+			if result.Reason != tt.reason {
+				t.Errorf("Expected reason %q, got %q", tt.reason, result.Reason)
+			}
+			if result.Name != "semantic-checker" {
+				t.Errorf("Expected name semantic-checker, got %s", result.Name)
+			}
+		})
+	}
+}
+
+func TestSemanticChecker_EmbeddingFails(t *testing.T) {
+	result := NewSemanticChecker(failingEmbedder{}).Check(models.EvaluationContext{Query: "q", Answer: "a"})
+
+	if result.Status != models.StageStatusError {
+		t.Errorf("Expected status error, got %s", result.Status)
+	}
+}
+
+func TestSemanticChecker_Paraphrase(t *testing.T) {
+	checker := NewSemanticChecker(embedding.NewHashEmbedder(0))
+
+	// Shares no query keyword exactly, so the overlap checker scores it 0.0
+	evalCtx := models.EvaluationContext{
+		Query:  "How does encryption work?",
+		Answer: "Encrypting scrambles data so only holders of the key can read it.",
+	}
+
+	if overlap := NewOverlapChecker().Check(evalCtx); overlap.Score != 0.0 {
+		t.Fatalf("Expected no keyword overlap, got %f", overlap.Score)
+	}
+	if result := checker.Check(evalCtx); result.Score <= 0.0 {
+		t.Errorf("Expected a positive semantic similarity, got %f (%s)", result.Score, result.Reason)
+	}
+}
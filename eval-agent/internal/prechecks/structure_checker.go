@@ -0,0 +1,425 @@
+package prechecks
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// StructureChecker parses the answer in the format the query asks for, e.g. "reply in JSON"
+// or "list 5 reasons", and scores 1.0 when it is well formed, 0.5 when it is close (wrong
+// number of items, schema mismatch, untagged code block) and 0.0 when it doesn't parse.
+// A configured Format is required for every answer instead. JSON answers are validated
+// against the request's output schema when it has one.
+type StructureChecker struct {
+	Format *config.OutputFormat
+}
+
+func NewStructureChecker() *StructureChecker {
+	return &StructureChecker{}
+}
+
+func (c *StructureChecker) Check(evaluationContext models.EvaluationContext) models.StageResult {
+	result := models.StageResult{
+		Name:   "structure-checker",
+		Status: models.StageStatusOK,
+	}
+	now := time.Now()
+
+	format := c.Format
+	if format == nil {
+		format = RequestedFormat(evaluationContext.Query)
+	}
+	if format == nil && len(evaluationContext.OutputSchema) > 0 {
+		format = &config.OutputFormat{Type: config.FormatJSON}
+	}
+	if format == nil {
+		result.Status = models.StageStatusSkipped
+		result.Reason = "No output format requested"
+		result.Duration = time.Since(now)
+		return result
+	}
+
+	var schema *jsonschema.Resolved
+	if format.Type == config.FormatJSON && len(evaluationContext.OutputSchema) > 0 {
+		var err error
+		if schema, err = resolveSchema(evaluationContext.OutputSchema); err != nil {
+			result.Status = models.StageStatusError
+			result.Reason = fmt.Sprintf("Invalid output schema: %v", err)
+			result.Duration = time.Since(now)
+			return result
+		}
+	}
+
+	answer := evaluationContext.Answer
+	switch format.Type {
+	case config.FormatJSON:
+		result.Score, result.Reason = checkJSON(answer, schema)
+	case config.FormatYAML:
+		result.Score, result.Reason = checkYAML(answer)
+	case config.FormatTable:
+		result.Score, result.Reason = checkTable(answer)
+	case config.FormatList:
+		result.Score, result.Reason = checkList(answer, format.Items)
+	case config.FormatCode:
+		result.Score, result.Reason = checkCode(answer, format.Language)
+	default:
+		result.Status = models.StageStatusError
+		result.Reason = fmt.Sprintf("Unknown output format %q", format.Type)
+	}
+	result.Duration = time.Since(now)
+
+	return result
+}
+
+var (
+	// A code request needs a verb of production, "explain Go's method sets" asks for prose
+	codeRequestPattern  = regexp.MustCompile(`(?i)\bimplement\b|\b(?:write|show|give|generate|provide|create|build)\b[^.?!\n]{0,60}?\b(?:code|snippet|function|script|program|class|method|query)\b`)
+	jsonRequestPattern  = formatRequestPattern(`json`)
+	yamlRequestPattern  = formatRequestPattern(`ya?ml`)
+	tableRequestPattern = regexp.MustCompile(`(?i)\b(?:markdown table|(?:as|in|into|with) an? table|tabular)\b`)
+	listRequestPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\blist\s+(?:of\s+)?(?:the\s+)?(?:top\s+)?(\d+|two|three|four|five|six|seven|eight|nine|ten)\b`),
+		regexp.MustCompile(`(?i)\b(?:top|give(?:\s+me)?|name|enumerate)\s+(\d+|two|three|four|five|six|seven|eight|nine|ten)\b`),
+	}
+	numberedListPattern = regexp.MustCompile(`(?i)\bnumbered list\b`)
+
+	// "Go" is only the language when capitalized, "go" is too common a word
+	codeLanguagePatterns = []struct {
+		language string
+		pattern  *regexp.Regexp
+	}{
+		{"go", regexp.MustCompile(`\bGo\b|(?i:\bgolang\b)`)},
+		{"python", regexp.MustCompile(`(?i)\bpython\b`)},
+		{"javascript", regexp.MustCompile(`(?i)\b(?:javascript|node\.?js)\b`)},
+		{"typescript", regexp.MustCompile(`(?i)\btypescript\b`)},
+		{"java", regexp.MustCompile(`(?i)\bjava\b`)},
+		{"rust", regexp.MustCompile(`(?i)\brust\b`)},
+		{"ruby", regexp.MustCompile(`(?i)\bruby\b`)},
+		{"kotlin", regexp.MustCompile(`(?i)\bkotlin\b`)},
+		{"swift", regexp.MustCompile(`(?i)\bswift\b`)},
+		{"php", regexp.MustCompile(`(?i)\bphp\b`)},
+		{"bash", regexp.MustCompile(`(?i)\b(?:bash|shell)\b`)},
+		{"sql", regexp.MustCompile(`(?i)\bsql\b`)},
+	}
+
+	numberWords = map[string]int{
+		"two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	}
+)
+
+// formatRequestPattern matches phrasing that asks for the answer in a data format, not any
+// mention of it: "as JSON", "in YAML format", "return the user ... JSON", while "what is
+// JSON?" or "how do I parse YAML in Go?" ask about the format
+func formatRequestPattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)\b(?:as|in|into|using)\s+(?:an?\s+)?(?:valid\s+)?` + name + `\b` +
+		`|\b` + name + `\s+format\b` +
+		`|(?:^|[.!?:;]\s*|\bplease\s+|\band\s+|\bthen\s+)(?:return|respond|reply|output|answer|format|produce|generate|provide|give(?:\s+me)?)\b[^.?!\n]{0,60}?\b` + name + `\b`)
+}
+
+// RequestedFormat returns the output format the query asks for, nil when it asks for none.
+// A code request in a named language wins over the data formats, so "write Python code
+// that prints JSON" asks for a python code block.
+func RequestedFormat(query string) *config.OutputFormat {
+	if codeRequestPattern.MatchString(query) {
+		if language := requestedLanguage(query); language != "" {
+			return &config.OutputFormat{Type: config.FormatCode, Language: language}
+		}
+	}
+
+	switch {
+	case jsonRequestPattern.MatchString(query):
+		return &config.OutputFormat{Type: config.FormatJSON}
+	case yamlRequestPattern.MatchString(query):
+		return &config.OutputFormat{Type: config.FormatYAML}
+	case tableRequestPattern.MatchString(query):
+		return &config.OutputFormat{Type: config.FormatTable}
+	}
+
+	for _, pattern := range listRequestPatterns {
+		if match := pattern.FindStringSubmatch(query); match != nil {
+			return &config.OutputFormat{Type: config.FormatList, Items: parseCount(match[1])}
+		}
+	}
+	if numberedListPattern.MatchString(query) {
+		return &config.OutputFormat{Type: config.FormatList}
+	}
+
+	return nil
+}
+
+// requestedLanguage returns the programming language named first in the query
+func requestedLanguage(query string) string {
+	language, at := "", -1
+	for _, candidate := range codeLanguagePatterns {
+		if loc := candidate.pattern.FindStringIndex(query); loc != nil && (at < 0 || loc[0] < at) {
+			language, at = candidate.language, loc[0]
+		}
+	}
+	return language
+}
+
+func parseCount(s string) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return numberWords[strings.ToLower(s)]
+}
+
+// codeBlock is a fenced block of the answer, Language is the normalized info string tag
+type codeBlock struct {
+	Language string
+	Body     string
+}
+
+// codeBlocks returns the fenced (``` or ~~~) blocks of the text, an unclosed fence runs to the end
+func codeBlocks(text string) []codeBlock {
+	var blocks []codeBlock
+	var current *codeBlock
+	var fence string
+	var body []string
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if current == nil {
+			for _, marker := range []string{"```", "~~~"} {
+				if strings.HasPrefix(trimmed, marker) {
+					info := strings.Fields(strings.TrimLeft(trimmed, marker[:1]))
+					current, fence, body = &codeBlock{}, marker, nil
+					if len(info) > 0 {
+						current.Language = normalizeLanguage(info[0])
+					}
+					break
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			current.Body = strings.Join(body, "\n")
+			blocks = append(blocks, *current)
+			current = nil
+			continue
+		}
+		body = append(body, line)
+	}
+
+	if current != nil {
+		current.Body = strings.Join(body, "\n")
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+var languageAliases = map[string]string{
+	"golang":  "go",
+	"py":      "python",
+	"python3": "python",
+	"js":      "javascript",
+	"node":    "javascript",
+	"ts":      "typescript",
+	"rs":      "rust",
+	"rb":      "ruby",
+	"sh":      "bash",
+	"shell":   "bash",
+	"zsh":     "bash",
+	"yml":     "yaml",
+	"kt":      "kotlin",
+}
+
+func normalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.Trim(tag, "{}."))
+	if alias, ok := languageAliases[tag]; ok {
+		return alias
+	}
+	return tag
+}
+
+// payload returns the first fenced block tagged with one of the languages, else the first
+// untagged block, else the whole answer
+func payload(answer string, languages ...string) string {
+	blocks := codeBlocks(answer)
+	for _, block := range blocks {
+		for _, language := range languages {
+			if block.Language == language {
+				return block.Body
+			}
+		}
+	}
+	for _, block := range blocks {
+		if block.Language == "" {
+			return block.Body
+		}
+	}
+	return answer
+}
+
+// resolveSchema parses the request's JSON Schema, remote $refs are not loaded
+func resolveSchema(raw json.RawMessage) (*jsonschema.Resolved, error) {
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+	return schema.Resolve(nil)
+}
+
+func checkJSON(answer string, schema *jsonschema.Resolved) (float64, string) {
+	var instance any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(payload(answer, "json"))), &instance); err != nil {
+		return 0.0, fmt.Sprintf("Answer is not valid JSON: %v", err)
+	}
+
+	if schema == nil {
+		return 1.0, "Answer is valid JSON"
+	}
+	if err := schema.Validate(instance); err != nil {
+		return 0.5, fmt.Sprintf("Answer is valid JSON but does not match the output schema: %v", err)
+	}
+
+	return 1.0, "Answer is valid JSON matching the output schema"
+}
+
+// checkYAML requires a mapping or sequence, any plain text parses as a YAML string
+func checkYAML(answer string) (float64, string) {
+	var document any
+	if err := yaml.Unmarshal([]byte(payload(answer, "yaml")), &document); err != nil {
+		return 0.0, fmt.Sprintf("Answer is not valid YAML: %v", err)
+	}
+
+	switch document.(type) {
+	case map[string]any, []any:
+		return 1.0, "Answer is valid YAML"
+	default:
+		return 0.0, "Answer is not a YAML mapping or sequence"
+	}
+}
+
+var tableSeparatorPattern = regexp.MustCompile(`^\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?$`)
+
+// checkTable looks for a markdown table: a header row, a separator row and data rows with
+// the header's number of columns
+func checkTable(answer string) (float64, string) {
+	lines := strings.Split(answer, "\n")
+
+	for i := 1; i < len(lines); i++ {
+		header := strings.TrimSpace(lines[i-1])
+		separator := strings.TrimSpace(lines[i])
+		if !strings.Contains(header, "|") || !strings.Contains(separator, "-") || !tableSeparatorPattern.MatchString(separator) {
+			continue
+		}
+
+		columns := len(tableCells(header))
+		if len(tableCells(separator)) != columns {
+			return 0.5, fmt.Sprintf("Markdown table separator has %d columns, header has %d", len(tableCells(separator)), columns)
+		}
+
+		rows := 0
+		for _, line := range lines[i+1:] {
+			row := strings.TrimSpace(line)
+			if !strings.Contains(row, "|") {
+				break
+			}
+			rows++
+			if cells := len(tableCells(row)); cells != columns {
+				return 0.5, fmt.Sprintf("Markdown table row %d has %d columns, header has %d", rows, cells, columns)
+			}
+		}
+
+		if rows == 0 {
+			return 0.5, "Markdown table has no rows"
+		}
+		return 1.0, fmt.Sprintf("Answer has a markdown table with %d rows and %d columns", rows, columns)
+	}
+
+	return 0.0, "Answer has no markdown table"
+}
+
+func tableCells(row string) []string {
+	row = strings.TrimPrefix(strings.TrimSuffix(row, "|"), "|")
+	return strings.Split(row, "|")
+}
+
+var (
+	numberedItemPattern = regexp.MustCompile(`^(\s*)\d+[.)]\s+\S`)
+	bulletItemPattern   = regexp.MustCompile(`^(\s*)[-*+]\s+\S`)
+)
+
+// checkList counts the top level items of the numbered list, or of the bullet list when the
+// answer has no numbered items. Nested items are indented deeper and don't count.
+func checkList(answer string, items int) (float64, string) {
+	count := countListItems(answer, numberedItemPattern)
+	if count == 0 {
+		count = countListItems(answer, bulletItemPattern)
+	}
+
+	switch {
+	case count == 0:
+		return 0.0, "Answer has no list"
+	case items > 0 && count != items:
+		return 0.5, fmt.Sprintf("List has %d items, %d requested", count, items)
+	default:
+		return 1.0, fmt.Sprintf("Answer has a list of %d items", count)
+	}
+}
+
+func countListItems(answer string, pattern *regexp.Regexp) int {
+	var indents []int
+	for _, line := range strings.Split(answer, "\n") {
+		if match := pattern.FindStringSubmatch(line); match != nil {
+			indents = append(indents, len(match[1]))
+		}
+	}
+	if len(indents) == 0 {
+		return 0
+	}
+
+	top := indents[0]
+	for _, indent := range indents {
+		top = min(top, indent)
+	}
+
+	count := 0
+	for _, indent := range indents {
+		if indent == top {
+			count++
+		}
+	}
+	return count
+}
+
+func checkCode(answer string, language string) (float64, string) {
+	blocks := codeBlocks(answer)
+	if len(blocks) == 0 {
+		return 0.0, "Answer has no fenced code block"
+	}
+	if language == "" {
+		return 1.0, "Answer has a fenced code block"
+	}
+
+	language = normalizeLanguage(language)
+	untagged := false
+	var others []string
+	for _, block := range blocks {
+		switch block.Language {
+		case language:
+			return 1.0, fmt.Sprintf("Answer has a fenced %s code block", language)
+		case "":
+			untagged = true
+		default:
+			others = append(others, block.Language)
+		}
+	}
+
+	if untagged {
+		return 0.5, fmt.Sprintf("Code block has no language tag, %s requested", language)
+	}
+	return 0.0, fmt.Sprintf("Code block is in %s, %s requested", strings.Join(others, ", "), language)
+}
@@ -0,0 +1,227 @@
+package prechecks
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+func TestRequestedFormat(t *testing.T) {
+	tests := []struct {
+		query string
+		want  *config.OutputFormat
+	}{
+		{"Return the user as JSON", &config.OutputFormat{Type: config.FormatJSON}},
+		{"Give me the config in YAML", &config.OutputFormat{Type: config.FormatYAML}},
+		{"Compare the plans in a markdown table", &config.OutputFormat{Type: config.FormatTable}},
+		{"List 5 reasons to learn Go", &config.OutputFormat{Type: config.FormatList, Items: 5}},
+		{"Give me three tips for sleeping better", &config.OutputFormat{Type: config.FormatList, Items: 3}},
+		{"Answer with a numbered list", &config.OutputFormat{Type: config.FormatList}},
+		{"Write a Go function that reverses a string", &config.OutputFormat{Type: config.FormatCode, Language: "go"}},
+		{"Write Python code that prints JSON", &config.OutputFormat{Type: config.FormatCode, Language: "python"}},
+		{"Write an SQL query counting users", &config.OutputFormat{Type: config.FormatCode, Language: "sql"}},
+		{"Respond with a JSON object containing the name", &config.OutputFormat{Type: config.FormatJSON}},
+		{"Please output the result in YAML format", &config.OutputFormat{Type: config.FormatYAML}},
+		{"Implement quicksort in Rust", &config.OutputFormat{Type: config.FormatCode, Language: "rust"}},
+		{"Where should I go on holiday?", nil},
+		{"What is the capital of France?", nil},
+
+		// Mentioning a format or language is not a request for it
+		{"What is JSON?", nil},
+		{"How do I parse YAML in Go?", nil},
+		{"Is YAML a superset of JSON?", nil},
+		{"Explain Go's method sets", nil},
+		{"What does an SQL query planner do?", nil},
+		{"Why do Python classes need self?", nil},
+	}
+
+	for _, tt := range tests {
+		got := RequestedFormat(tt.query)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("RequestedFormat(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestStructureChecker(t *testing.T) {
+	schema := json.RawMessage(`{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`)
+
+	tests := []struct {
+		name   string
+		query  string
+		answer string
+		schema json.RawMessage
+		score  float64
+		status models.StageStatus
+		reason string
+	}{
+		{
+			name:   "No format requested",
+			query:  "What is the capital of France?",
+			answer: "Paris",
+			status: models.StageStatusSkipped,
+			reason: "No output format requested",
+		},
+		{
+			name:   "Valid JSON",
+			query:  "Return the user as JSON",
+			answer: `{"name": "Jane"}`,
+			score:  1.0,
+			status: models.StageStatusOK,
+			reason: "Answer is valid JSON",
+		},
+		{
+			name:   "JSON in a fenced block",
+			query:  "Return the user as JSON",
+			answer: "Here it is:\n```json\n{\"name\": \"Jane\"}\n```",
+			score:  1.0,
+			status: models.StageStatusOK,
+			reason: "Answer is valid JSON",
+		},
+		{
+			name:   "Invalid JSON",
+			query:  "Return the user as JSON",
+			answer: `{"name": "Jane"`,
+			score:  0.0,
+			status: models.StageStatusOK,
+			reason: "Answer is not valid JSON: unexpected end of JSON input",
+		},
+		{
+			name:   "Schema implies JSON",
+			query:  "Who is the user?",
+			answer: `{"name": "Jane"}`,
+			schema: schema,
+			score:  1.0,
+			status: models.StageStatusOK,
+			reason: "Answer is valid JSON matching the output schema",
+		},
+		{
+			name:   "Schema mismatch",
+			query:  "Who is the user?",
+			answer: `{"age": 42}`,
+			schema: schema,
+			score:  0.5,
+			status: models.StageStatusOK,
+		},
+		{
+			name:   "Valid YAML",
+			query:  "Give me the config in YAML",
+			answer: "server:\n  port: 8080\n  host: localhost",
+			score:  1.0,
+			status: models.StageStatusOK,
+			reason: "Answer is valid YAML",
+		},
+		{
+			name:   "Prose instead of YAML",
+			query:  "Give me the config in YAML",
+			answer: "Set the port to 8080",
+			score:  0.0,
+			status: models.StageStatusOK,
+			reason: "Answer is not a YAML mapping or sequence",
+		},
+		{
+			name:   "Markdown table",
+			query:  "Compare the plans in a markdown table",
+			answer: "| Plan | Price |\n|------|------:|\n| Free | 0 |\n| Pro | 10 |",
+			score:  1.0,
+			status: models.StageStatusOK,
+			reason: "Answer has a markdown table with 2 rows and 2 columns",
+		},
+		{
+			name:   "Ragged table",
+			query:  "Compare the plans in a markdown table",
+			answer: "| Plan | Price |\n|---|---|\n| Free | 0 | extra |",
+			score:  0.5,
+			status: models.StageStatusOK,
+			reason: "Markdown table row 1 has 3 columns, header has 2",
+		},
+		{
+			name:   "No table",
+			query:  "Compare the plans in a markdown table",
+			answer: "Free costs nothing and Pro costs 10.",
+			score:  0.0,
+			status: models.StageStatusOK,
+			reason: "Answer has no markdown table",
+		},
+		{
+			name:   "List with requested items",
+			query:  "List 3 reasons to learn Go",
+			answer: "1. Simple\n2. Fast\n   - compiles quickly\n3. Concurrent",
+			score:  1.0,
+			status: models.StageStatusOK,
+			reason: "Answer has a list of 3 items",
+		},
+		{
+			name:   "List with wrong number of items",
+			query:  "List 3 reasons to learn Go",
+			answer: "- Simple\n- Fast",
+			score:  0.5,
+			status: models.StageStatusOK,
+			reason: "List has 2 items, 3 requested",
+		},
+		{
+			name:   "Code block in requested language",
+			query:  "Write a Go function that reverses a string",
+			answer: "```golang\nfunc reverse(s string) string { return s }\n```",
+			score:  1.0,
+			status: models.StageStatusOK,
+			reason: "Answer has a fenced go code block",
+		},
+		{
+			name:   "Untagged code block",
+			query:  "Write a Go function that reverses a string",
+			answer: "```\nfunc reverse(s string) string { return s }\n```",
+			score:  0.5,
+			status: models.StageStatusOK,
+			reason: "Code block has no language tag, go requested",
+		},
+		{
+			name:   "Code block in another language",
+			query:  "Write a Go function that reverses a string",
+			answer: "```py\ndef reverse(s): return s[::-1]\n```",
+			score:  0.0,
+			status: models.StageStatusOK,
+			reason: "Code block is in python, go requested",
+		},
+	}
+
+	checker := NewStructureChecker()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.Check(models.EvaluationContext{Query: tt.query, Answer: tt.answer, OutputSchema: tt.schema})
+
+			if result.Score != tt.score || result.Status != tt.status {
+				t.Errorf("Got %f %s, want %f %s (%q)", result.Score, result.Status, tt.score, tt.status, result.Reason)
+			}
+			if tt.reason != "" && result.Reason != tt.reason {
+				t.Errorf("Expected reason %q, got %q", tt.reason, result.Reason)
+			}
+		})
+	}
+}
+
+func TestStructureChecker_ConfiguredFormat(t *testing.T) {
+	checker := &StructureChecker{Format: &config.OutputFormat{Type: config.FormatJSON}}
+
+	result := checker.Check(models.EvaluationContext{Query: "Who is the user?", Answer: "The user is Jane"})
+	if result.Status != models.StageStatusOK || result.Score != 0.0 {
+		t.Errorf("Expected configured format required without a request in the query, got %+v", result)
+	}
+}
+
+func TestStructureChecker_InvalidSchema(t *testing.T) {
+	checker := NewStructureChecker()
+
+	result := checker.Check(models.EvaluationContext{
+		Query:        "Return the user as JSON",
+		Answer:       `{"name": "Jane"}`,
+		OutputSchema: json.RawMessage(`{"type": 42}`),
+	})
+	if result.Status != models.StageStatusError || !strings.HasPrefix(result.Reason, "Invalid output schema") {
+		t.Errorf("Expected invalid schema error, got %s %q", result.Status, result.Reason)
+	}
+}
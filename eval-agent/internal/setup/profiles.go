@@ -0,0 +1,112 @@
+package setup
+
+import (
+	"fmt"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/aggregator"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/prechecks"
+	"github.com/rs/zerolog"
+)
+
+// profileBuilder creates the executor for each evaluation profile
+type profileBuilder struct {
+	prechecksConfig   *config.PrechecksConfig
+	judgesConfig      *config.JudgesConfig
+	aggregationConfig *config.PoliciesConfig
+	checkerPool       *prechecks.CheckerPool
+	judgePool         *judge.JudgePool
+	aggregator        *aggregator.Aggregator // Shared aggregator, selects the policy by agent name
+	policies          map[string]aggregator.Policy
+	earlyExit         float64
+	logger            *zerolog.Logger
+}
+
+func (b *profileBuilder) build(profileCfg config.EvaluationProfile) (executor.Profile, error) {
+	prechecksConfig, err := b.prechecksConfig.Only(profileCfg.Prechecks)
+	if err != nil {
+		return executor.Profile{}, err
+	}
+	prechecksConfig, err = prechecksConfig.WithThresholds(profileCfg.Thresholds)
+	if err != nil {
+		return executor.Profile{}, err
+	}
+	prechecksConfig, err = prechecksConfig.WithOutputFormat(profileCfg.OutputFormat)
+	if err != nil {
+		return executor.Profile{}, err
+	}
+	checkers, err := b.checkerPool.BuildFromConfig(prechecksConfig)
+	if err != nil {
+		return executor.Profile{}, fmt.Errorf("failed to build prechecks: %w", err)
+	}
+
+	judgesConfig, err := b.judgesConfig.Only(profileCfg.Judges)
+	if err != nil {
+		return executor.Profile{}, err
+	}
+	judges, err := b.judgePool.BuildFromConfig(judgesConfig)
+	if err != nil {
+		return executor.Profile{}, fmt.Errorf("failed to build judges: %w", err)
+	}
+
+	agg, err := b.buildAggregator(profileCfg)
+	if err != nil {
+		return executor.Profile{}, err
+	}
+
+	earlyExit := b.earlyExit
+	if profileCfg.EarlyExitThreshold != nil {
+		earlyExit = *profileCfg.EarlyExitThreshold
+	}
+
+	b.logger.Info().
+		Str("profile", profileCfg.Name).
+		Int("prechecks", len(checkers)).
+		Int("judges", len(judges)).
+		Float64("early_exit_threshold", earlyExit).
+		Msg("evaluation profile built")
+
+	return executor.Profile{
+		Name: profileCfg.Name,
+		Pattern: executor.AgentPattern{
+			Name:    profileCfg.Match.Name,
+			Type:    profileCfg.Match.Type,
+			Version: profileCfg.Match.Version,
+		},
+		Executor: executor.NewExecutor(
+			prechecks.NewStageRunner(checkers),
+			judge.NewJudgeRunner(judges, b.logger),
+			agg,
+			earlyExit,
+			b.logger,
+		),
+	}, nil
+}
+
+// buildAggregator returns the shared aggregator unless the profile pins a policy or its weights
+func (b *profileBuilder) buildAggregator(profileCfg config.EvaluationProfile) (*aggregator.Aggregator, error) {
+	if profileCfg.Policy == "" && profileCfg.PrecheckWeight == nil && profileCfg.JudgeWeight == nil {
+		return b.aggregator, nil
+	}
+
+	policyName := profileCfg.Policy
+	if policyName == "" {
+		policyName = b.aggregationConfig.Aggregation.DefaultPolicy
+	}
+
+	policy, ok := b.policies[policyName]
+	if !ok {
+		return nil, fmt.Errorf("unknown aggregation policy: %s", policyName)
+	}
+
+	if profileCfg.PrecheckWeight != nil {
+		policy.Weights.PreChecks = *profileCfg.PrecheckWeight
+	}
+	if profileCfg.JudgeWeight != nil {
+		policy.Weights.LLMJudge = *profileCfg.JudgeWeight
+	}
+
+	return aggregator.NewPolicyAggregator(policy, nil, b.logger), nil
+}
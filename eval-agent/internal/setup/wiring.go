@@ -5,53 +5,98 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/aggregator"
-	"github.com/povarna/generative-ai-agents/eval-agent/internal/bedrock"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/cache"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/config"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/embedding"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/judge"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/prechecks"
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/redis"
 	"github.com/rs/zerolog"
 )
 
 type Config struct {
 	AWSRegion          string
 	ClaudeModelID      string
-	PrecheckWeight     float64
-	LLMJudgeWeight     float64
+	LLMProvider        string // Default LLM provider: bedrock, openai or scripted
+	OpenAIBaseURL      string
+	OpenAIAPIKey       string
+	OpenAIModelID      string
+	LLMScriptPath      string     // Responses of the scripted provider
+	LLMLimits          llm.Limits // Per-model request rate, token rate and concurrency caps
 	EarlyExitThreshold float64
+	JudgeCache         string        // Judge result cache backend: "" (disabled), "file" or "redis"
+	JudgeCacheDir      string        // Directory of the file cache
+	JudgeCacheTTL      time.Duration // Expiry of redis cache entries, 0 keeps them forever
+	EmbeddingProvider  string        // Embedder of the semantic precheck: hash or openai
+	EmbeddingModelID   string        // Embedding model of the openai provider
+	EmbeddingCacheSize int           // Embeddings kept in memory, 0 disables the cache
+
+	// Deprecated: set precheck_weight and judge_weight in aggregation.yaml. When set, they
+	// override the weights of every aggregation policy.
+	PrecheckWeight *float64
+	LLMJudgeWeight *float64
 }
 
 type Dependencies struct {
-	Executor      *executor.Executor
-	JudgeExecutor *executor.JudgeExecutor
-	Logger        *zerolog.Logger
+	Executor           *executor.ProfileRouter
+	JudgeExecutor      *executor.JudgeExecutor
+	ComparisonExecutor *executor.ComparisonExecutor
+	Logger             *zerolog.Logger
 }
 
 func LoadConfig() *Config {
 	return &Config{
-		AWSRegion:          getEnv("AWS_REGION", "us-east-1"),
-		ClaudeModelID:      getEnv("CLAUDE_MODEL_ID", ""),
-		PrecheckWeight:     getEnvFloat("PRECHECK_WEIGHT", 0.3),
-		LLMJudgeWeight:     getEnvFloat("LLM_JUDGE_WEIGHT", 0.7),
+		AWSRegion:     getEnv("AWS_REGION", "us-east-1"),
+		ClaudeModelID: getEnv("CLAUDE_MODEL_ID", ""),
+		LLMProvider:   getEnv("LLM_PROVIDER", llm.ProviderBedrock),
+		OpenAIBaseURL: getEnv("OPENAI_BASE_URL", "http://localhost:8000/v1"),
+		OpenAIAPIKey:  getEnv("OPENAI_API_KEY", ""),
+		OpenAIModelID: getEnv("OPENAI_MODEL_ID", ""),
+		LLMScriptPath: getEnv("LLM_SCRIPT_PATH", ""),
+		LLMLimits: llm.Limits{
+			RequestsPerMinute: getEnvInt("LLM_REQUESTS_PER_MINUTE", 0),
+			TokensPerMinute:   getEnvInt("LLM_TOKENS_PER_MINUTE", 0),
+			MaxConcurrency:    getEnvInt("LLM_MAX_CONCURRENCY", 10),
+		},
 		EarlyExitThreshold: getEnvFloat("EARLY_EXIT_THRESHOLD", 0.2),
+		JudgeCache:         getEnv("JUDGE_CACHE", ""),
+		JudgeCacheDir:      getEnv("JUDGE_CACHE_DIR", ".cache/judges"),
+		JudgeCacheTTL:      getEnvDuration("JUDGE_CACHE_TTL", 0),
+		EmbeddingProvider:  getEnv("EMBEDDING_PROVIDER", embedding.ProviderHash),
+		EmbeddingModelID:   getEnv("EMBEDDING_MODEL_ID", ""),
+		EmbeddingCacheSize: getEnvInt("EMBEDDING_CACHE_SIZE", 10000),
+		PrecheckWeight:     lookupEnvFloat("PRECHECK_WEIGHT"),
+		LLMJudgeWeight:     lookupEnvFloat("LLM_JUDGE_WEIGHT"),
 	}
 }
 
 func Wire(ctx context.Context, cfg *Config, logger *zerolog.Logger) (*Dependencies, error) {
-	// Bedrock client
-	bedrockClient, err := bedrock.NewClient(ctx, cfg.AWSRegion, cfg.ClaudeModelID)
+	// Default LLM client, judges may select another provider in their model config
+	llmSettings := cfg.llmSettings()
+	llmClients := llm.NewRegistry().WithLimits(cfg.LLMLimits)
+	defaultLLMConfig := llmSettings.Config("", "")
+	llmClient, err := llmClients.Get(ctx, defaultLLMConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Bedrock client: %w", err)
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
 	}
+	logger.Info().
+		Str("provider", defaultLLMConfig.Provider).
+		Str("model", defaultLLMConfig.ModelID).
+		Int("requests_per_minute", cfg.LLMLimits.RequestsPerMinute).
+		Int("tokens_per_minute", cfg.LLMLimits.TokensPerMinute).
+		Int("max_concurrency", cfg.LLMLimits.MaxConcurrency).
+		Msg("LLM client initialized")
 
-	// PreChecks
-	stageRunner := prechecks.NewStageRunner([]prechecks.Checker{
-		&prechecks.LengthChecker{},
-		&prechecks.OverlapChecker{MinOverlapThreshold: 0.3},
-		&prechecks.FormatChecker{},
-	})
+	// Load prechecks configuration from YAML
+	prechecksConfig, err := config.LoadPrechecksConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prechecks config: %w", err)
+	}
 
 	// Load judges configuration from YAML
 	judgesConfig, err := config.LoadJudgesConfig()
@@ -59,37 +104,171 @@ func Wire(ctx context.Context, cfg *Config, logger *zerolog.Logger) (*Dependenci
 		return nil, fmt.Errorf("failed to load judges config: %w", err)
 	}
 
-	// Create judge pool and build judges from config
-	judgePool := judge.NewJudgePool(bedrockClient, logger)
-	judges, err := judgePool.BuildFromConfig(judgesConfig)
+	// Load aggregation policies from YAML
+	aggregationConfig, err := config.LoadAggregationConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to build judges from config: %w", err)
+		return nil, fmt.Errorf("failed to load aggregation config: %w", err)
+	}
+	if err := cfg.applyDeprecatedWeights(aggregationConfig, logger); err != nil {
+		return nil, fmt.Errorf("failed to load aggregation config: %w", err)
 	}
 
-	// Create judge runner with config-driven judges
-	judgeRunner := judge.NewJudgeRunner(judges, logger)
+	// Load evaluation profiles from YAML
+	profilesConfig, err := config.LoadProfilesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profiles config: %w", err)
+	}
 
-	// Judge factory for single judge execution (used by JudgeExecutor)
-	judgeFactory := judge.NewJudgeFactory(bedrockClient, logger)
+	// Load model price table from YAML
+	pricingConfig, err := config.LoadPricingConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing config: %w", err)
+	}
 
-	// Aggregator
-	agg := aggregator.NewAggregator(aggregator.Weights{
-		PreChecks: cfg.PrecheckWeight,
-		LLMJudge:  cfg.LLMJudgeWeight,
-	}, logger)
+	// Embedder of the semantic precheck, OpenAI-compatible servers share the LLM connection settings
+	embedder, err := embedding.NewEmbedder(embedding.Config{
+		Provider:  cfg.EmbeddingProvider,
+		ModelID:   cfg.EmbeddingModelID,
+		BaseURL:   cfg.OpenAIBaseURL,
+		APIKey:    cfg.OpenAIAPIKey,
+		CacheSize: cfg.EmbeddingCacheSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedder: %w", err)
+	}
+
+	// Judge pool, optionally serving results from the judge cache
+	judgePool := newJudgePool(ctx, llmClient, llmClients, llmSettings, pricingConfig, logger)
+	judgeCache, err := newJudgeCache(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create judge cache: %w", err)
+	}
+	if judgeCache != nil {
+		judgePool.WithCache(judgeCache, defaultLLMConfig.ModelID)
+		logger.Info().Str("backend", cfg.JudgeCache).Msg("judge cache enabled")
+	}
+
+	builder := &profileBuilder{
+		prechecksConfig:   prechecksConfig,
+		judgesConfig:      judgesConfig,
+		aggregationConfig: aggregationConfig,
+		checkerPool:       prechecks.NewCheckerPool(logger).WithEmbedder(embedder),
+		judgePool:         judgePool,
+		aggregator:        aggregator.NewAggregatorFromConfig(aggregationConfig, logger),
+		policies:          aggregator.PoliciesFromConfig(aggregationConfig),
+		earlyExit:         cfg.EarlyExitThreshold,
+		logger:            logger,
+	}
+
+	// One executor per evaluation profile
+	var profiles []executor.Profile
+	var defaultProfile executor.Profile
+	for _, profileCfg := range profilesConfig.Evaluation.Profiles {
+		profile, err := builder.build(profileCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build profile %s: %w", profileCfg.Name, err)
+		}
+
+		if profileCfg.Name == profilesConfig.Evaluation.DefaultProfile {
+			defaultProfile = profile
+		} else {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	// Judge factory for single judge execution and pairwise comparison
+	judgeFactory := judge.NewJudgeFactory(newJudgePool(ctx, llmClient, llmClients, llmSettings, pricingConfig, logger), logger)
 
 	// Executors
-	exec := executor.NewExecutor(stageRunner, judgeRunner, agg, cfg.EarlyExitThreshold, logger)
+	exec := executor.NewProfileRouter(profiles, defaultProfile, logger)
 	judgeExec := executor.NewJudgeExecutor(judgeFactory, logger)
+	comparisonExec := executor.NewComparisonExecutor(judgeFactory, logger)
 
 	return &Dependencies{
-		Executor:      exec,
-		JudgeExecutor: judgeExec,
-		Logger:        logger,
+		Executor:           exec,
+		JudgeExecutor:      judgeExec,
+		ComparisonExecutor: comparisonExec,
+		Logger:             logger,
 	}, nil
 
 }
 
+func (cfg *Config) llmSettings() llm.Settings {
+	return llm.Settings{
+		DefaultProvider: cfg.LLMProvider,
+		AWSRegion:       cfg.AWSRegion,
+		BedrockModelID:  cfg.ClaudeModelID,
+		OpenAIBaseURL:   cfg.OpenAIBaseURL,
+		OpenAIAPIKey:    cfg.OpenAIAPIKey,
+		OpenAIModelID:   cfg.OpenAIModelID,
+		ScriptPath:      cfg.LLMScriptPath,
+	}
+}
+
+// newJudgePool creates a judge pool whose judges select their provider and model in the
+// model config. Clients come from the registry, so judges on the same model share one.
+func newJudgePool(
+	ctx context.Context,
+	llmClient llm.Client,
+	llmClients *llm.Registry,
+	llmSettings llm.Settings,
+	pricingConfig *config.PricingConfig,
+	logger *zerolog.Logger,
+) *judge.JudgePool {
+	clients := func(provider string, modelID string) (judge.LLMClient, string, error) {
+		llmConfig := llmSettings.Config(provider, modelID)
+		client, err := llmClients.Get(ctx, llmConfig)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to create %s LLM client for model %s: %w", llmConfig.Provider, llmConfig.ModelID, err)
+		}
+		return client, llmConfig.ModelID, nil
+	}
+
+	return judge.NewJudgePool(llmClient, logger).WithClients(clients).WithPricing(pricingConfig)
+}
+
+func newJudgeCache(ctx context.Context, cfg *Config) (judge.Cache, error) {
+	switch cfg.JudgeCache {
+	case "":
+		return nil, nil
+	case "file":
+		return cache.NewFileCache(cfg.JudgeCacheDir)
+	case "redis":
+		client, err := redis.ConnectRedis(ctx, getEnv("REDIS_ADDR", "localhost:6379"), os.Getenv("REDIS_PASSWORD"), 3)
+		if err != nil {
+			return nil, err
+		}
+		return cache.NewRedisCache(client, "eval:judge-cache:", cfg.JudgeCacheTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown judge cache backend: %s (supported: file, redis)", cfg.JudgeCache)
+	}
+}
+
+// applyDeprecatedWeights maps PRECHECK_WEIGHT and LLM_JUDGE_WEIGHT, which predate the
+// aggregation policies, onto the weights of every policy
+func (cfg *Config) applyDeprecatedWeights(policies *config.PoliciesConfig, logger *zerolog.Logger) error {
+	if cfg.PrecheckWeight == nil && cfg.LLMJudgeWeight == nil {
+		return nil
+	}
+
+	for i := range policies.Aggregation.Policies {
+		policy := &policies.Aggregation.Policies[i]
+		if cfg.PrecheckWeight != nil {
+			policy.PrecheckWeight = *cfg.PrecheckWeight
+		}
+		if cfg.LLMJudgeWeight != nil {
+			policy.JudgeWeight = *cfg.LLMJudgeWeight
+		}
+	}
+
+	logger.Warn().
+		Interface("precheck_weight", cfg.PrecheckWeight).
+		Interface("judge_weight", cfg.LLMJudgeWeight).
+		Msg("PRECHECK_WEIGHT and LLM_JUDGE_WEIGHT are deprecated and override the weights of every aggregation policy, set precheck_weight and judge_weight in aggregation.yaml instead")
+
+	return policies.Validate()
+}
+
 func getEnv(key string, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -108,3 +287,30 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 
 	return value
 }
+
+// lookupEnvFloat returns nil when the variable is unset or not a number
+func lookupEnvFloat(key string) *float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		value = defaultValue
+	}
+
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		value = defaultValue
+	}
+
+	return value
+}
@@ -1,17 +1,91 @@
 package stream
 
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
 type StreamConfig struct {
-	RedisAddr    string
-	Stream       string
-	Group        string
-	ConsumerName string
+	RedisAddr     string
+	Stream        string
+	Group         string
+	ConsumerName  string
+	ResultsStream string // Stream the evaluation results are published to
+	ResultsMaxLen int64  // Approximate cap on the results stream length, 0 means unbounded
+
+	DeadLetterStream string        // Stream receiving messages that cannot be evaluated
+	MaxDeliveries    int64         // Deliveries after which a failing message is dead-lettered
+	ClaimMinIdle     time.Duration // Pending messages idle for longer are claimed from other consumers
+	ClaimInterval    time.Duration // How often the pending entries list is checked
+
+	Concurrency  int           // Maximum number of in-flight evaluations per consumer
+	ReadCount    int64         // Maximum number of messages fetched per XREADGROUP call
+	DrainTimeout time.Duration // How long shutdown waits for in-flight evaluations
 }
 
 func NewStreamConfig(redisAddr string, stream string, group string, consumerName string) *StreamConfig {
 	return &StreamConfig{
-		RedisAddr:    redisAddr,
-		Stream:       stream,
-		Group:        group,
-		ConsumerName: consumerName,
+		RedisAddr:        redisAddr,
+		Stream:           stream,
+		Group:            group,
+		ConsumerName:     consumerName,
+		ResultsStream:    "eval-results",
+		ResultsMaxLen:    100000,
+		DeadLetterStream: stream + "-dlq",
+		MaxDeliveries:    5,
+		ClaimMinIdle:     5 * time.Minute,
+		ClaimInterval:    30 * time.Second,
+		Concurrency:      4,
+		ReadCount:        4,
+		DrainTimeout:     30 * time.Second,
+	}
+}
+
+// LoadStreamConfig reads the stream configuration from the environment
+func LoadStreamConfig() *StreamConfig {
+	cfg := NewStreamConfig(
+		getEnv("REDIS_ADDR", "localhost:6379"),
+		getEnv("REDIS_STREAM_NAME", "eval-events"),
+		getEnv("REDIS_CONSUMER_GROUP", "eval-group"),
+		getEnv("REDIS_CONSUMER_NAME", os.Getenv("HOSTNAME")),
+	)
+
+	cfg.ResultsStream = getEnv("REDIS_RESULTS_STREAM", cfg.ResultsStream)
+	if maxLen, err := strconv.ParseInt(os.Getenv("REDIS_RESULTS_MAXLEN"), 10, 64); err == nil && maxLen >= 0 {
+		cfg.ResultsMaxLen = maxLen
+	}
+
+	cfg.DeadLetterStream = getEnv("REDIS_DLQ_STREAM", cfg.DeadLetterStream)
+	if maxDeliveries, err := strconv.ParseInt(os.Getenv("REDIS_MAX_DELIVERIES"), 10, 64); err == nil && maxDeliveries > 0 {
+		cfg.MaxDeliveries = maxDeliveries
+	}
+	if minIdle, err := time.ParseDuration(os.Getenv("REDIS_CLAIM_MIN_IDLE")); err == nil && minIdle > 0 {
+		cfg.ClaimMinIdle = minIdle
 	}
+	if interval, err := time.ParseDuration(os.Getenv("REDIS_CLAIM_INTERVAL")); err == nil && interval > 0 {
+		cfg.ClaimInterval = interval
+	}
+
+	if concurrency, err := strconv.Atoi(os.Getenv("REDIS_CONSUMER_CONCURRENCY")); err == nil && concurrency > 0 {
+		cfg.Concurrency = concurrency
+		cfg.ReadCount = int64(concurrency)
+	}
+	if readCount, err := strconv.ParseInt(os.Getenv("REDIS_READ_COUNT"), 10, 64); err == nil && readCount > 0 {
+		cfg.ReadCount = readCount
+	}
+	if drainTimeout, err := time.ParseDuration(os.Getenv("REDIS_DRAIN_TIMEOUT")); err == nil && drainTimeout > 0 {
+		cfg.DrainTimeout = drainTimeout
+	}
+
+	return cfg
+}
+
+func getEnv(key string, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		value = defaultValue
+	}
+
+	return value
 }
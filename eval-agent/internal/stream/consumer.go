@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/povarna/generative-ai-agents/eval-agent/internal/executor"
@@ -13,22 +14,39 @@ import (
 )
 
 type Consumer struct {
-	client       *redis.Client
-	stream       string
-	groupID      string
-	consumerName string
-	executor     *executor.Executor
-	logger       *zerolog.Logger
+	client           *redis.Client
+	stream           string
+	groupID          string
+	consumerName     string
+	deadLetterStream string
+	maxDeliveries    int64
+	claimMinIdle     time.Duration
+	claimInterval    time.Duration
+	concurrency      int
+	readCount        int
+	drainTimeout     time.Duration
+	executor         executor.Evaluator
+	sink             ResultSink
+	logger           *zerolog.Logger
 }
 
-func NewConsumer(client *redis.Client, stream string, groupID string, consumerName string, exec *executor.Executor, logger *zerolog.Logger) *Consumer {
+// NewConsumer creates a stream consumer. Results are published to the sink, a nil sink only logs them.
+func NewConsumer(client *redis.Client, cfg *StreamConfig, exec executor.Evaluator, sink ResultSink, logger *zerolog.Logger) *Consumer {
 	return &Consumer{
-		client:       client,
-		stream:       stream,
-		groupID:      groupID,
-		consumerName: consumerName,
-		executor:     exec,
-		logger:       logger,
+		client:           client,
+		stream:           cfg.Stream,
+		groupID:          cfg.Group,
+		consumerName:     cfg.ConsumerName,
+		deadLetterStream: cfg.DeadLetterStream,
+		maxDeliveries:    cfg.MaxDeliveries,
+		claimMinIdle:     cfg.ClaimMinIdle,
+		claimInterval:    cfg.ClaimInterval,
+		concurrency:      max(cfg.Concurrency, 1),
+		readCount:        int(max(cfg.ReadCount, 1)),
+		drainTimeout:     cfg.DrainTimeout,
+		executor:         exec,
+		sink:             sink,
+		logger:           logger,
 	}
 }
 
@@ -40,66 +58,218 @@ func (c *Consumer) Setup(ctx context.Context) error {
 	return nil
 }
 
+// Start reads and evaluates messages until the context is cancelled. Up to concurrency messages
+// are evaluated at once, and no more messages are read than there are free workers. On
+// cancellation the in-flight evaluations are drained before Start returns.
 func (c *Consumer) Start(ctx context.Context) error {
 	c.logger.Info().
 		Str("stream", c.stream).
 		Str("group", c.groupID).
 		Str("consumer", c.consumerName).
+		Str("dlq", c.deadLetterStream).
+		Int("concurrency", c.concurrency).
 		Msg("Consumer started")
 
+	// In-flight evaluations outlive ctx so they can finish during the drain
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+
+	workers := newWorkerPool(c.concurrency)
+	var lastClaim time.Time
+
 	for {
 		if ctx.Err() != nil {
-			return ctx.Err()
+			return c.drain(ctx, workers, cancelWork)
+		}
+
+		// Pick up messages left pending by crashed consumers or failed evaluations
+		if time.Since(lastClaim) >= c.claimInterval {
+			c.recoverPending(ctx, workCtx, workers)
+			lastClaim = time.Now()
+		}
+
+		// Backpressure: wait for a free worker before reading more messages
+		slots := workers.acquire(ctx, c.readCount)
+		if slots == 0 {
+			continue
 		}
 
 		msgs, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
 			Group:    c.groupID,
 			Consumer: c.consumerName,
 			Streams:  []string{c.stream, ">"},
-			Count:    1,
+			Count:    int64(slots),
 			Block:    2 * time.Second,
 		}).Result()
 
 		if err != nil {
+			workers.release(slots)
+
 			if errors.Is(err, redis.Nil) {
 				// timeout, no message -> loop again
 				continue
 			}
 
 			if ctx.Err() != nil {
-				return ctx.Err() // context cancelled during block
+				continue // context cancelled during block, drain on the next iteration
 			}
 
 			c.logger.Error().Err(err).Msg("Failed to read from stream")
 			continue
 		}
 
-		for _, msg := range msgs[0].Messages {
-			c.process(ctx, msg)
+		var received []redis.XMessage
+		for _, stream := range msgs {
+			received = append(received, stream.Messages...)
 		}
+		workers.release(slots - len(received))
+
+		for _, msg := range received {
+			workers.run(func() {
+				c.process(workCtx, msg, 1)
+			})
+		}
+	}
+}
+
+// drain waits for the in-flight evaluations. Evaluations still running after the drain timeout
+// are cancelled and their messages stay pending, to be claimed after a restart.
+func (c *Consumer) drain(ctx context.Context, workers *workerPool, cancelWork context.CancelFunc) error {
+	c.logger.Info().Int("in_flight", workers.inFlight()).Msg("Draining in-flight evaluations")
+
+	done := make(chan struct{})
+	go func() {
+		workers.wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		c.logger.Info().Msg("Drain complete")
+	case <-time.After(c.drainTimeout):
+		c.logger.Warn().
+			Dur("timeout", c.drainTimeout).
+			Int("in_flight", workers.inFlight()).
+			Msg("Drain timed out, cancelling in-flight evaluations")
+		cancelWork()
+		<-done
 	}
+
+	return ctx.Err()
 }
 
-func (c *Consumer) process(ctx context.Context, msg redis.XMessage) {
+// recoverPending claims messages that have been pending for longer than claimMinIdle.
+// Messages that reached the delivery limit are dead-lettered, the others are processed again.
+func (c *Consumer) recoverPending(ctx context.Context, workCtx context.Context, workers *workerPool) {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: c.stream,
+		Group:  c.groupID,
+		Idle:   c.claimMinIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  100,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.Error().Err(err).Msg("Failed to read pending messages")
+		}
+		return
+	}
+
+	for _, entry := range pending {
+		// Only claim what can be processed right away, the rest is picked up on the next pass
+		if workers.acquire(ctx, 1) == 0 {
+			return
+		}
+
+		msgs, err := c.client.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   c.stream,
+			Group:    c.groupID,
+			Consumer: c.consumerName,
+			MinIdle:  c.claimMinIdle,
+			Messages: []string{entry.ID},
+		}).Result()
+		if err != nil || len(msgs) == 0 {
+			workers.release(1)
+			if err != nil {
+				c.logger.Error().Err(err).Str("id", entry.ID).Msg("Failed to claim pending message")
+			}
+			continue
+		}
+
+		// Claiming counts as a delivery
+		deliveries := entry.RetryCount + 1
+		msg := msgs[0]
+
+		c.logger.Info().
+			Str("id", msg.ID).
+			Str("previous_consumer", entry.Consumer).
+			Int64("deliveries", deliveries).
+			Msg("Claimed pending message")
+
+		workers.run(func() {
+			if deliveries > c.maxDeliveries {
+				c.deadLetter(workCtx, msg, fmt.Sprintf("exceeded %d deliveries without completing", c.maxDeliveries), deliveries)
+				return
+			}
+			c.process(workCtx, msg, deliveries)
+		})
+	}
+}
+
+// process evaluates a message and ACKs it on success. Permanent failures and failures on the
+// last allowed delivery are dead-lettered, other failures stay pending to be retried.
+func (c *Consumer) process(ctx context.Context, msg redis.XMessage, deliveries int64) {
 	c.logger.Info().Str("id", msg.ID).Msg("Message received")
 
+	err := c.handle(ctx, msg)
+	if err == nil {
+		c.ack(ctx, msg.ID)
+		return
+	}
+
+	// Interrupted by shutdown, leave pending for the next consumer
+	if ctx.Err() != nil {
+		c.logger.Warn().Err(err).Str("id", msg.ID).Msg("Evaluation interrupted, message left pending")
+		return
+	}
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || deliveries >= c.maxDeliveries {
+		c.deadLetter(ctx, msg, err.Error(), deliveries)
+		return
+	}
+
+	c.logger.Error().
+		Err(err).
+		Str("id", msg.ID).
+		Int64("deliveries", deliveries).
+		Msg("Evaluation failed, message left pending for retry")
+}
+
+func (c *Consumer) handle(ctx context.Context, msg redis.XMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("evaluation panicked: %v", r)
+		}
+	}()
+
 	// decode json
 	payload, ok := msg.Values["payload"].(string)
 	if !ok {
-		c.logger.Error().Str("id", msg.ID).Msg("Missing payload field")
-		c.ack(ctx, msg.ID)
-		return
+		return &permanentError{reason: "missing payload field"}
 	}
 
 	var evalRequest models.EvaluationRequest
 	if err := json.Unmarshal([]byte(payload), &evalRequest); err != nil {
-		c.logger.Error().Err(err).Str("id", msg.ID).Msg("Failed to decode message")
-		c.ack(ctx, msg.ID) // bad message — ACK to skip it
-		return
+		return &permanentError{reason: fmt.Sprintf("failed to decode payload: %v", err)}
 	}
 
 	evalCtx := normalize(evalRequest)
 	result := c.executor.Execute(ctx, evalCtx)
+	if ctx.Err() != nil {
+		return fmt.Errorf("evaluation interrupted: %w", ctx.Err())
+	}
 
 	c.logger.Info().
 		Str("id", msg.ID).
@@ -107,8 +277,23 @@ func (c *Consumer) process(ctx context.Context, msg redis.XMessage) {
 		Float64("confidence", result.Confidence).
 		Msg("Evaluation complete")
 
-	c.ack(ctx, msg.ID)
+	if c.sink != nil {
+		output := models.EvaluationOutput{
+			EventID:     evalRequest.EventID,
+			EventType:   evalRequest.EventType,
+			Agent:       evalRequest.Agent,
+			Result:      result,
+			SourceID:    msg.ID,
+			ReceivedAt:  evalCtx.CreatedAt,
+			EvaluatedAt: time.Now(),
+		}
 
+		if err := c.sink.Publish(ctx, output); err != nil {
+			return fmt.Errorf("failed to publish result: %w", err)
+		}
+	}
+
+	return nil
 }
 
 func (c *Consumer) ack(ctx context.Context, msgID string) {
@@ -119,10 +304,13 @@ func (c *Consumer) ack(ctx context.Context, msgID string) {
 
 func normalize(req models.EvaluationRequest) models.EvaluationContext {
 	return models.EvaluationContext{
-		RequestID: req.EventID,
-		Query:     req.Interaction.UserQuery,
-		Context:   req.Interaction.Context,
-		Answer:    req.Interaction.Answer,
-		CreatedAt: time.Now(),
+		RequestID:       req.EventID,
+		Query:           req.Interaction.UserQuery,
+		Context:         req.Interaction.Context,
+		Answer:          req.Interaction.Answer,
+		Agent:           req.Agent,
+		CreatedAt:       time.Now(),
+		ReferenceAnswer: req.Interaction.ReferenceAnswer,
+		OutputSchema:    req.Interaction.OutputSchema,
 	}
 }
@@ -0,0 +1,118 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+type stubEvaluator struct {
+	panics bool
+}
+
+func (s *stubEvaluator) Execute(ctx context.Context, evalCtx models.EvaluationContext) models.EvaluationResult {
+	if s.panics {
+		panic("judge crashed")
+	}
+	return models.EvaluationResult{ID: evalCtx.RequestID, Verdict: models.VerdictPass}
+}
+
+func newTestConsumer(exec *stubEvaluator, sink ResultSink) *Consumer {
+	logger := zerolog.Nop()
+	return NewConsumer(nil, NewStreamConfig("", "eval-events", "eval-group", "test"), exec, sink, &logger)
+}
+
+func TestConsumer_Handle(t *testing.T) {
+	validPayload := `{"event_id":"evt-001","agent":{"name":"my-agent"},"interaction":{"user_query":"q","answer":"a"}}`
+
+	tests := []struct {
+		name          string
+		values        map[string]any
+		panics        bool
+		sinkErr       error
+		wantErr       string
+		wantPermanent bool
+	}{
+		{
+			name:   "success",
+			values: map[string]any{"payload": validPayload},
+		},
+		{
+			name:          "missing payload",
+			values:        map[string]any{"data": validPayload},
+			wantErr:       "missing payload field",
+			wantPermanent: true,
+		},
+		{
+			name:          "malformed payload",
+			values:        map[string]any{"payload": "{not json"},
+			wantErr:       "failed to decode payload",
+			wantPermanent: true,
+		},
+		{
+			name:    "evaluation panics",
+			values:  map[string]any{"payload": validPayload},
+			panics:  true,
+			wantErr: "evaluation panicked: judge crashed",
+		},
+		{
+			name:    "publish fails",
+			values:  map[string]any{"payload": validPayload},
+			sinkErr: errors.New("connection refused"),
+			wantErr: "failed to publish result: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &recordingSink{err: tt.sinkErr}
+			consumer := newTestConsumer(&stubEvaluator{panics: tt.panics}, sink)
+
+			err := consumer.handle(context.Background(), redis.XMessage{ID: "1-0", Values: tt.values})
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got: %v", err)
+				}
+				if len(sink.outputs) != 1 || sink.outputs[0].EventID != "evt-001" || sink.outputs[0].SourceID != "1-0" {
+					t.Errorf("Expected output for evt-001 from 1-0, got %+v", sink.outputs)
+				}
+				if sink.outputs[0].Agent.Name != "my-agent" {
+					t.Errorf("Expected agent my-agent on output, got %q", sink.outputs[0].Agent.Name)
+				}
+				return
+			}
+
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error starting with %q, got: %v", tt.wantErr, err)
+			}
+
+			var permanent *permanentError
+			if errors.As(err, &permanent) != tt.wantPermanent {
+				t.Errorf("Expected permanent=%v for %v", tt.wantPermanent, err)
+			}
+		})
+	}
+}
+
+func TestConsumer_Handle_InterruptedEvaluation(t *testing.T) {
+	sink := &recordingSink{}
+	consumer := newTestConsumer(&stubEvaluator{}, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := consumer.handle(ctx, redis.XMessage{ID: "1-0", Values: map[string]any{"payload": `{"event_id":"evt-001"}`}})
+
+	if err == nil || !strings.HasPrefix(err.Error(), "evaluation interrupted") {
+		t.Fatalf("Expected interrupted error, got: %v", err)
+	}
+	if len(sink.outputs) != 0 {
+		t.Errorf("Expected no result to be published for an interrupted evaluation, got %d", len(sink.outputs))
+	}
+}
@@ -0,0 +1,59 @@
+package stream
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Fields of a dead-letter stream entry
+const (
+	DeadLetterPayload    = "payload"       // Original message payload, empty if it was missing
+	DeadLetterSourceID   = "source_id"     // ID of the message in the source stream
+	DeadLetterSource     = "source_stream" // Stream the message was read from
+	DeadLetterReason     = "reason"        // Why the message could not be evaluated
+	DeadLetterDeliveries = "deliveries"    // Number of times the message was delivered
+	DeadLetterFailedAt   = "failed_at"     // RFC 3339 time the message was dead-lettered
+)
+
+// permanentError marks failures that will fail again on redelivery, such as malformed payloads
+type permanentError struct {
+	reason string
+}
+
+func (e *permanentError) Error() string {
+	return e.reason
+}
+
+// deadLetter moves a message to the dead-letter stream and ACKs it. If the dead-letter
+// stream cannot be written the message stays pending so it is not lost.
+func (c *Consumer) deadLetter(ctx context.Context, msg redis.XMessage, reason string, deliveries int64) {
+	payload, _ := msg.Values["payload"].(string)
+
+	err := c.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: c.deadLetterStream,
+		Values: map[string]any{
+			DeadLetterPayload:    payload,
+			DeadLetterSourceID:   msg.ID,
+			DeadLetterSource:     c.stream,
+			DeadLetterReason:     reason,
+			DeadLetterDeliveries: strconv.FormatInt(deliveries, 10),
+			DeadLetterFailedAt:   time.Now().UTC().Format(time.RFC3339),
+		},
+	}).Err()
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", msg.ID).Msg("Failed to dead-letter message")
+		return
+	}
+
+	c.logger.Warn().
+		Str("id", msg.ID).
+		Str("reason", reason).
+		Int64("deliveries", deliveries).
+		Str("dlq", c.deadLetterStream).
+		Msg("Message dead-lettered")
+
+	c.ack(ctx, msg.ID)
+}
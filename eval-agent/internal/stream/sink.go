@@ -0,0 +1,70 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// ResultSink receives the output of every evaluated event
+type ResultSink interface {
+	Publish(ctx context.Context, output models.EvaluationOutput) error
+}
+
+// RedisStreamSink publishes evaluation outputs to a Redis stream
+type RedisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink creates a sink for the given stream. The stream is trimmed to roughly
+// maxLen entries, a maxLen of 0 keeps every entry.
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (s *RedisStreamSink) Publish(ctx context.Context, output models.EvaluationOutput) error {
+	payload, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	// The verdict is duplicated outside the payload so consumers can filter without decoding it
+	err = s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: s.maxLen > 0,
+		Values: map[string]any{
+			"event_id": output.EventID,
+			"verdict":  string(output.Result.Verdict),
+			"payload":  string(payload),
+		},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to publish to stream %s: %w", s.stream, err)
+	}
+
+	return nil
+}
+
+// MultiSink publishes every output to all of its sinks
+type MultiSink []ResultSink
+
+func (m MultiSink) Publish(ctx context.Context, output models.EvaluationOutput) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Publish(ctx, output); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
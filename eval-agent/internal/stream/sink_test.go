@@ -0,0 +1,33 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/povarna/generative-ai-agents/eval-agent/internal/models"
+)
+
+type recordingSink struct {
+	outputs []models.EvaluationOutput
+	err     error
+}
+
+func (s *recordingSink) Publish(ctx context.Context, output models.EvaluationOutput) error {
+	s.outputs = append(s.outputs, output)
+	return s.err
+}
+
+func TestMultiSink_PublishesToAllSinks(t *testing.T) {
+	failing := &recordingSink{err: errors.New("unavailable")}
+	healthy := &recordingSink{}
+
+	err := MultiSink{failing, healthy}.Publish(context.Background(), models.EvaluationOutput{EventID: "evt-001"})
+
+	if err == nil || err.Error() != "unavailable" {
+		t.Errorf("Expected the failing sink's error, got: %v", err)
+	}
+	if len(healthy.outputs) != 1 || healthy.outputs[0].EventID != "evt-001" {
+		t.Errorf("Expected the healthy sink to receive the output despite the failure, got %+v", healthy.outputs)
+	}
+}
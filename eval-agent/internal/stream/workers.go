@@ -0,0 +1,64 @@
+package stream
+
+import (
+	"context"
+	"sync"
+)
+
+// workerPool bounds the number of in-flight evaluations. Slots are acquired before messages
+// are read, so a consumer never holds more messages than it can evaluate.
+type workerPool struct {
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{slots: make(chan struct{}, size)}
+}
+
+// acquire blocks until at least one slot is free, then takes up to max free slots.
+// It returns the number of slots taken, 0 if the context was cancelled first.
+func (p *workerPool) acquire(ctx context.Context, max int) int {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0
+	}
+
+	acquired := 1
+	for acquired < max {
+		select {
+		case p.slots <- struct{}{}:
+			acquired++
+		default:
+			return acquired
+		}
+	}
+	return acquired
+}
+
+// release returns unused slots to the pool
+func (p *workerPool) release(n int) {
+	for range n {
+		<-p.slots
+	}
+}
+
+// run executes fn in a goroutine that holds one previously acquired slot
+func (p *workerPool) run(fn func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.release(1)
+		fn()
+	}()
+}
+
+func (p *workerPool) inFlight() int {
+	return len(p.slots)
+}
+
+// wait blocks until every running function has returned
+func (p *workerPool) wait() {
+	p.wg.Wait()
+}
@@ -0,0 +1,60 @@
+package stream
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool_AcquireTakesOnlyFreeSlots(t *testing.T) {
+	pool := newWorkerPool(3)
+
+	if got := pool.acquire(context.Background(), 2); got != 2 {
+		t.Fatalf("Expected 2 slots, got %d", got)
+	}
+	if got := pool.acquire(context.Background(), 5); got != 1 {
+		t.Fatalf("Expected the last free slot, got %d", got)
+	}
+
+	// The pool is full, acquire must block until the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if got := pool.acquire(ctx, 1); got != 0 {
+		t.Errorf("Expected 0 slots from a full pool, got %d", got)
+	}
+
+	pool.release(3)
+	if pool.inFlight() != 0 {
+		t.Errorf("Expected all slots released, %d still held", pool.inFlight())
+	}
+}
+
+func TestWorkerPool_BoundsConcurrency(t *testing.T) {
+	const size = 2
+	pool := newWorkerPool(size)
+
+	var running, peak atomic.Int32
+	for range 6 {
+		pool.acquire(context.Background(), 1)
+		pool.run(func() {
+			current := running.Add(1)
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		})
+	}
+	pool.wait()
+
+	if peak.Load() > size {
+		t.Errorf("Expected at most %d concurrent runs, saw %d", size, peak.Load())
+	}
+	if pool.inFlight() != 0 {
+		t.Errorf("Expected all slots released after wait, %d still held", pool.inFlight())
+	}
+}
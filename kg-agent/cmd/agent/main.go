@@ -13,10 +13,10 @@ import (
 	"github.com/go-openapi/spec"
 	"github.com/joho/godotenv"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/agent"
-	"github.com/povarna/generative-ai-agents/kg-agent/internal/bedrock"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/cache"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/conversation"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/guardrails"
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/middleware"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/redis"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/rewrite"
@@ -52,7 +52,6 @@ func main() {
 		log.Error().Msg("No .env file found")
 	}
 
-	region := os.Getenv("AWS_REGION")
 	modelID := os.Getenv("CLAUDE_MODEL_ID")
 	miniModelID := os.Getenv("CLAUDE_MINI_MODEL_ID")
 	port := os.Getenv("AGENT_API_PORT")
@@ -79,19 +78,29 @@ func main() {
 	}
 
 	ctx := context.Background()
-	bedrockClient, err := bedrock.NewClient(ctx, region, modelID)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Unable to initialize Bedrock Client")
-	}
-	miniClient, err := bedrock.NewClient(ctx, region, miniModelID)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Unable to initialize Mini Bedrock Client")
+
+	// LLM clients per agent component, components with the same config share a client
+	llmClients := llm.NewRegistry()
+	newLLMClient := func(component string, defaultModelID string) (llm.Client, llm.Config) {
+		llmConfig := llm.LoadConfig(component, defaultModelID)
+		client, err := llmClients.Get(ctx, llmConfig)
+		if err != nil {
+			log.Fatal().Err(err).Str("component", component).Msg("Unable to initialize LLM client")
+		}
+
+		log.Info().
+			Str("component", component).
+			Str("provider", llmConfig.Provider).
+			Str("model", llmConfig.ModelID).
+			Msg("LLM client initialized")
+		return client, llmConfig
 	}
 
-	log.Info().
-		Str("region", region).
-		Str("model", modelID).
-		Msg("Bedrock client initialized")
+	answerClient, answerConfig := newLLMClient("ANSWER", modelID)
+	miniClient, _ := newLLMClient("MINI", miniModelID)
+	guardrailsClient, _ := newLLMClient("GUARDRAILS", miniModelID)
+	rewriteClient, _ := newLLMClient("REWRITE", miniModelID)
+	strategyClient, _ := newLLMClient("STRATEGY", miniModelID)
 
 	// Connect to Redis with retries
 	redisAddr := os.Getenv("REDIS_ADDR")
@@ -122,21 +131,29 @@ func main() {
 		}
 	}
 
-	guardrailsValidator := guardrails.NewGuardrails(miniClient)
-	rewriter := rewrite.NewRewriter(miniClient)
+	guardrailsValidator := guardrails.NewGuardrails(guardrailsClient)
+	rewriter := rewrite.NewRewriter(rewriteClient)
 	searchClient := agent.NewSearchClient(searchConfig)
-	retrievalStrategy := strategy.NewRetrievalStrategy(miniClient)
+	retrievalStrategy := strategy.NewRetrievalStrategy(strategyClient)
 	conversationStore := conversation.NewRedisConversationStore(redisClient, redisTTL)
 	searchCache := cache.NewRedisSearchCache(redisClient, "search_cache:")
+
+	// Price table for the estimated cost of queries, usage is reported without cost when unset
+	prices, err := llm.LoadPrices(os.Getenv("LLM_PRICING_PATH"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Unable to load LLM price table")
+	}
+
 	service := agent.NewService(
-		bedrockClient,
+		answerClient,
 		miniClient,
-		modelID,
+		answerConfig.ModelID,
 		rewriter,
 		searchClient,
 		conversationStore,
 		retrievalStrategy,
 		searchCache,
+		prices,
 	)
 	handler := agent.NewHandler(service, guardrailsValidator)
 
@@ -9,7 +9,7 @@ import (
 	"os"
 
 	"github.com/joho/godotenv"
-	"github.com/povarna/generative-ai-agents/kg-agent/internal/bedrock"
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/rewrite"
 )
 
@@ -41,14 +41,13 @@ func main() {
 
 	ctx := context.Background()
 
-	region := os.Getenv("AWS_REGION")
 	modelID := os.Getenv("CLAUDE_MODEL_ID")
 
-	bedrockClient, err := bedrock.NewClient(ctx, region, modelID)
-	rewriter := rewrite.NewRewriter(bedrockClient)
+	llmClient, err := llm.NewClient(ctx, llm.LoadConfig("ANSWER", modelID))
 	if err != nil {
 		log.Fatal(err)
 	}
+	rewriter := rewrite.NewRewriter(llmClient)
 
 	// Query rewrite
 	rewrittenQuery, err := rewriter.RewriteQuery(ctx, finalPrompt)
@@ -58,7 +57,7 @@ func main() {
 		rewrittenQuery = finalPrompt
 	}
 
-	req := bedrock.ClaudeRequest{
+	req := llm.Request{
 		Prompt:      rewrittenQuery,
 		MaxTokens:   *maxTokens,
 		Temperature: 0.0,
@@ -67,7 +66,7 @@ func main() {
 	// Invoke client
 	if *stream {
 		fmt.Println("Streaming response:")
-		response, err := bedrockClient.InvokeModelStream(ctx, req, func(chunk string) error {
+		response, err := llmClient.InvokeModelStream(ctx, req, func(chunk string) error {
 			fmt.Print(chunk)
 			return nil
 		})
@@ -76,7 +75,7 @@ func main() {
 		}
 		fmt.Printf("\n\nStop reason: %s\n", response.StopReason)
 	} else {
-		response, err := bedrockClient.InvokeModel(ctx, req)
+		response, err := llmClient.InvokeModel(ctx, req)
 		if err != nil {
 			log.Fatalf("Unable to invoke Claude model: %v", err)
 		}
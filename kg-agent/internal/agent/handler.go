@@ -6,6 +6,7 @@ import (
 
 	"github.com/emicklei/go-restful/v3"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/guardrails"
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/middleware"
 	"github.com/rs/zerolog/log"
 )
@@ -38,8 +39,11 @@ func (h *Handler) Query(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
+	// Count the tokens of the guardrails together with the ones of the query
+	ctx, _ := llm.WithMeter(req.Request.Context())
+
 	// Validate input against guardrails
-	validation := h.guardrails.ValidateInput(req.Request.Context(), queryRequest.Prompt)
+	validation := h.guardrails.ValidateInput(ctx, queryRequest.Prompt)
 	if !validation.IsValid {
 		log.Warn().
 			Str("reason", validation.Reason).
@@ -60,8 +64,6 @@ func (h *Handler) Query(req *restful.Request, resp *restful.Response) {
 		Float64("temperature", queryRequest.Temperature).
 		Msg("Process Query")
 
-	ctx := req.Request.Context()
-
 	queryResponse, err := h.service.Query(ctx, queryRequest)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to query")
@@ -88,8 +90,11 @@ func (h *Handler) QueryStream(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
+	// Count the tokens of the guardrails together with the ones of the query
+	ctx, _ := llm.WithMeter(req.Request.Context())
+
 	// Validate input against guardrails
-	validation := h.guardrails.ValidateInput(req.Request.Context(), queryRequest.Prompt)
+	validation := h.guardrails.ValidateInput(ctx, queryRequest.Prompt)
 	if !validation.IsValid {
 		log.Warn().
 			Str("reason", validation.Reason).
@@ -110,8 +115,6 @@ func (h *Handler) QueryStream(req *restful.Request, resp *restful.Response) {
 		Float64("temperature", queryRequest.Temperature).
 		Msg("Process Query Stream")
 
-	ctx := req.Request.Context()
-
 	resp.AddHeader("Content-Type", "text/event-stream")
 	resp.AddHeader("Cache-Control", "no-cache")
 	resp.AddHeader("Connection", "keep-alive")
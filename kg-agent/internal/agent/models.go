@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/middleware"
 )
 
@@ -15,10 +16,12 @@ type QueryRequest struct {
 }
 
 type QueryResponse struct {
-	SessionID  string `json:"session_id" description:"Session ID for conversation continuity"`
-	Content    string `json:"content" description:"Claude's response text"`
-	StopReason string `json:"stop_reason" description:"Why generation stopped"`
-	Model      string `json:"model" description:"Model ID used"`
+	SessionID    string     `json:"session_id" description:"Session ID for conversation continuity"`
+	Content      string     `json:"content" description:"Claude's response text"`
+	StopReason   string     `json:"stop_reason" description:"Why generation stopped"`
+	Model        string     `json:"model" description:"Model ID used"`
+	Usage        llm.Usage  `json:"usage" description:"Tokens and estimated cost of all model calls made for the query"`
+	SessionUsage *llm.Usage `json:"session_usage,omitempty" description:"Tokens and estimated cost of the session so far"`
 }
 
 type HealthResponse struct {
@@ -73,7 +76,9 @@ type StreamChunkEvent struct {
 }
 
 type StreamDoneEvent struct {
-	StopReason string `json:"stop_reason"`
+	StopReason   string     `json:"stop_reason"`
+	Usage        llm.Usage  `json:"usage"`
+	SessionUsage *llm.Usage `json:"session_usage,omitempty"`
 }
 
 type StreamErrorEvent struct {
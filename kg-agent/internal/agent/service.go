@@ -10,36 +10,38 @@ import (
 	"strings"
 	"time"
 
-	"github.com/povarna/generative-ai-agents/kg-agent/internal/bedrock"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/cache"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/conversation"
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/rewrite"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/strategy"
 	"github.com/rs/zerolog/log"
 )
 
 type Service struct {
-	bedrockClient     *bedrock.Client
-	miniClient        *bedrock.Client
+	llmClient         llm.Client
+	miniClient        llm.Client
 	rewriter          *rewrite.Rewriter
 	modelID           string
 	searchClient      *SearchClient
 	conversationStore conversation.ConversationStore
 	retrievalStrategy *strategy.RetrievalStrategy
 	searchCache       cache.SearchCache
+	prices            llm.Prices
 }
 
 func NewService(
-	bedrockClient *bedrock.Client,
-	miniClient *bedrock.Client,
+	llmClient llm.Client,
+	miniClient llm.Client,
 	modelID string,
 	rewriter *rewrite.Rewriter,
 	searchClient *SearchClient,
 	conversationStore conversation.ConversationStore,
 	retrievalStrategy *strategy.RetrievalStrategy,
-	searchCache cache.SearchCache) *Service {
+	searchCache cache.SearchCache,
+	prices llm.Prices) *Service {
 	return &Service{
-		bedrockClient:     bedrockClient,
+		llmClient:         llmClient,
 		miniClient:        miniClient,
 		rewriter:          rewriter,
 		modelID:           modelID,
@@ -47,10 +49,14 @@ func NewService(
 		conversationStore: conversationStore,
 		retrievalStrategy: retrievalStrategy,
 		searchCache:       searchCache,
+		prices:            prices,
 	}
 }
 
 func (s *Service) Query(ctx context.Context, queryRequest QueryRequest) (QueryResponse, error) {
+	// Count the tokens of every model call made for the query
+	ctx, meter := llm.WithMeter(ctx)
+
 	// Get or create session
 	sessionID, conversationHistory := s.getOrCreateSession(ctx, queryRequest)
 
@@ -74,7 +80,7 @@ func (s *Service) Query(ctx context.Context, queryRequest QueryRequest) (QueryRe
 	selectedClient := s.selectModelForAnswer(decision, len(searchResults) > 0)
 
 	// 4. Call Claude with context
-	response, err := selectedClient.InvokeModel(ctx, bedrock.ClaudeRequest{
+	response, err := selectedClient.InvokeModel(ctx, llm.Request{
 		Prompt:      enhancedPrompt,
 		MaxTokens:   queryRequest.MaxToken,
 		Temperature: queryRequest.Temperature,
@@ -87,17 +93,24 @@ func (s *Service) Query(ctx context.Context, queryRequest QueryRequest) (QueryRe
 	// Save user message and assistant response to conversation history
 	s.saveConversationMessages(ctx, sessionID, queryRequest, response)
 
+	usage := meter.Total(s.prices)
+
 	queryResponse := QueryResponse{
-		SessionID:  sessionID,
-		Content:    response.Content,
-		StopReason: response.StopReason,
-		Model:      s.modelID,
+		SessionID:    sessionID,
+		Content:      response.Content,
+		StopReason:   response.StopReason,
+		Model:        s.modelID,
+		Usage:        usage,
+		SessionUsage: s.addSessionUsage(ctx, sessionID, usage),
 	}
 
 	return queryResponse, nil
 }
 
 func (s *Service) QueryStream(ctx context.Context, queryRequest QueryRequest, flusher http.Flusher, writer io.Writer) error {
+	// Count the tokens of every model call made for the query
+	ctx, meter := llm.WithMeter(ctx)
+
 	// Get or create session
 	sessionID, conversationHistory := s.getOrCreateSession(ctx, queryRequest)
 
@@ -136,7 +149,7 @@ func (s *Service) QueryStream(ctx context.Context, queryRequest QueryRequest, fl
 	}
 
 	// Call Claude with context (streaming)
-	response, err := selectedClient.InvokeModelStream(ctx, bedrock.ClaudeRequest{
+	response, err := selectedClient.InvokeModelStream(ctx, llm.Request{
 		Prompt:      enhancedPrompt, // ← Changed: use enhanced prompt with context
 		MaxTokens:   queryRequest.MaxToken,
 		Temperature: queryRequest.Temperature,
@@ -173,11 +186,18 @@ func (s *Service) QueryStream(ctx context.Context, queryRequest QueryRequest, fl
 		return err
 	}
 
+	// Save user message and assistant response to conversation history
+	s.saveConversationMessages(ctx, sessionID, queryRequest, response)
+
+	usage := meter.Total(s.prices)
+
 	// Send end event
 	doneEvent := SSEEvent{
 		Event: "done",
 		Data: StreamDoneEvent{
-			StopReason: response.StopReason,
+			StopReason:   response.StopReason,
+			Usage:        usage,
+			SessionUsage: s.addSessionUsage(ctx, sessionID, usage),
 		},
 	}
 	if formatEvent, ok := doneEvent.Format(); ok == nil {
@@ -185,9 +205,6 @@ func (s *Service) QueryStream(ctx context.Context, queryRequest QueryRequest, fl
 		flusher.Flush()
 	}
 
-	// Save user message and assistant response to conversation history
-	s.saveConversationMessages(ctx, sessionID, queryRequest, response)
-
 	return nil
 }
 
@@ -237,7 +254,7 @@ func (s *Service) search(ctx context.Context, originalQuery string, rewrittenQue
 
 }
 
-func (s *Service) saveConversationMessages(ctx context.Context, sessionID string, queryRequest QueryRequest, response *bedrock.ClaudeResponse) {
+func (s *Service) saveConversationMessages(ctx context.Context, sessionID string, queryRequest QueryRequest, response *llm.Response) {
 	if sessionID == "" {
 		return // No session to save to
 	}
@@ -262,6 +279,30 @@ func (s *Service) saveConversationMessages(ctx context.Context, sessionID string
 
 }
 
+// addSessionUsage adds the usage of a query to its session and returns the session total,
+// nil when there is no session or the total couldn't be updated
+func (s *Service) addSessionUsage(ctx context.Context, sessionID string, usage llm.Usage) *llm.Usage {
+	if sessionID == "" {
+		return nil
+	}
+
+	sessionUsage, err := s.conversationStore.AddUsage(ctx, sessionID, usage)
+	if err != nil {
+		log.Warn().Err(err).Str("sessionID", sessionID).Msg("Failed to update session usage")
+		return nil
+	}
+
+	log.Info().
+		Str("sessionID", sessionID).
+		Int("input_tokens", usage.InputTokens).
+		Int("output_tokens", usage.OutputTokens).
+		Float64("cost", usage.Cost).
+		Float64("session_cost", sessionUsage.Cost).
+		Msg("Query usage")
+
+	return &sessionUsage
+}
+
 func (s *Service) getOrCreateSession(ctx context.Context, queryRequest QueryRequest) (string, *conversation.Conversation) {
 	var sessionID string
 	var conversationHistory *conversation.Conversation
@@ -325,7 +366,7 @@ func (s *Service) buildPromptWithContext(userQuery string, searchResult []Search
 		historySection, docsSection, userQuery)
 }
 
-func (s *Service) selectModelForAnswer(decision strategy.Decision, hasSearchResults bool) *bedrock.Client {
+func (s *Service) selectModelForAnswer(decision strategy.Decision, hasSearchResults bool) llm.Client {
 	// Simple queries without search → Use Haiku
 	if !decision.ShouldSearch && decision.Confidence > 0.90 && !hasSearchResults {
 		log.Info().Msg("Using Haiku for simple query")
@@ -333,7 +374,7 @@ func (s *Service) selectModelForAnswer(decision strategy.Decision, hasSearchResu
 	}
 	// Complex queries or with search results → Use Sonnet
 	log.Info().Msg("Using Sonnet for complex query")
-	return s.bedrockClient
+	return s.llmClient
 }
 
 func (s *Service) generateCacheKey(query string, searchType string, limit int) string {
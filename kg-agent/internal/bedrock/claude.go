@@ -20,8 +20,16 @@ type ClaudeRequest struct {
 
 // ClaudeResponse is the Claude's response
 type ClaudeResponse struct {
-	Content    string
-	StopReason string
+	Content      string
+	StopReason   string
+	InputTokens  int
+	OutputTokens int
+}
+
+// Token counts reported by Claude
+type claudeUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
 }
 
 // Claude API request format (what Bedrock expects)
@@ -43,7 +51,8 @@ type claudeMessageResponse struct {
 		Type string `json:"type"`
 		Text string `json:"text"`
 	} `json:"content"`
-	StopReason string `json:"stop_reason"`
+	StopReason string      `json:"stop_reason"`
+	Usage      claudeUsage `json:"usage"`
 }
 
 var anthropic_version = "bedrock-2023-05-31"
@@ -90,8 +99,10 @@ func (c *Client) InvokeModel(ctx context.Context, request ClaudeRequest) (*Claud
 	}
 
 	return &ClaudeResponse{
-		Content:    content,
-		StopReason: response.StopReason,
+		Content:      content,
+		StopReason:   response.StopReason,
+		InputTokens:  response.Usage.InputTokens,
+		OutputTokens: response.Usage.OutputTokens,
 	}, nil
 }
 
@@ -134,6 +145,7 @@ func (c *Client) InvokeModelStream(ctx context.Context, req ClaudeRequest, callb
 
 	var fullContent strings.Builder
 	var stopReason string
+	var usage claudeUsage
 
 	// Read events from the stream
 	for event := range stream.Events() {
@@ -148,8 +160,10 @@ func (c *Client) InvokeModelStream(ctx context.Context, req ClaudeRequest, callb
 					Text string `json:"text"`
 				} `json:"content_block"`
 				Message struct {
-					StopReason string `json:"stop_reason"`
+					StopReason string      `json:"stop_reason"`
+					Usage      claudeUsage `json:"usage"`
 				} `json:"message"`
+				Usage claudeUsage `json:"usage"`
 			}
 
 			if err := json.Unmarshal(v.Value.Bytes, &chunkResponse); err != nil {
@@ -182,6 +196,14 @@ func (c *Client) InvokeModelStream(ctx context.Context, req ClaudeRequest, callb
 				stopReason = chunkResponse.Message.StopReason
 			}
 
+			// message_start carries the input tokens, message_delta the output tokens so far
+			if chunkResponse.Message.Usage.InputTokens > 0 {
+				usage.InputTokens = chunkResponse.Message.Usage.InputTokens
+			}
+			if chunkResponse.Usage.OutputTokens > 0 {
+				usage.OutputTokens = chunkResponse.Usage.OutputTokens
+			}
+
 		default:
 			// Ignore other event types we don't need
 			continue
@@ -194,7 +216,9 @@ func (c *Client) InvokeModelStream(ctx context.Context, req ClaudeRequest, callb
 	}
 
 	return &ClaudeResponse{
-		Content:    fullContent.String(),
-		StopReason: stopReason,
+		Content:      fullContent.String(),
+		StopReason:   stopReason,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
 	}, nil
 }
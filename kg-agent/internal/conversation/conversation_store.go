@@ -1,10 +1,15 @@
 package conversation
 
-import "context"
+import (
+	"context"
+
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
+)
 
 type ConversationStore interface {
 	CreateSession(ctx context.Context) (*Session, error)
 	GetConversation(ctx context.Context, sessionID string) (*Conversation, error)
 	AddMessage(ctx context.Context, sessionID string, message Message) error
+	AddUsage(ctx context.Context, sessionID string, usage llm.Usage) (llm.Usage, error)
 	CleanExpiredSessions(ctx context.Context) error
 }
@@ -1,6 +1,10 @@
 package conversation
 
-import "time"
+import (
+	"time"
+
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
+)
 
 type Session struct {
 	ID        string //UUID
@@ -19,4 +23,5 @@ type Conversation struct {
 	SessionID string
 	Messages  []Message
 	Metadata  map[string]any
+	Usage     llm.Usage // Tokens and estimated cost of all queries in the session
 }
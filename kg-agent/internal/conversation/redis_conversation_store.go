@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 )
@@ -105,6 +106,27 @@ func (r *RedisConversationStore) AddMessage(ctx context.Context, sessionID strin
 	return nil
 }
 
+// AddUsage adds the usage of a query to the session total and returns the new total
+func (r *RedisConversationStore) AddUsage(ctx context.Context, sessionID string, usage llm.Usage) (llm.Usage, error) {
+	conversation, err := r.GetConversation(ctx, sessionID)
+	if err != nil {
+		return llm.Usage{}, err
+	}
+
+	conversation.Usage.Add(usage)
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		return llm.Usage{}, fmt.Errorf("failed to marshal conversation. Error: %w", err)
+	}
+
+	key := r.generateKey(sessionID)
+	if err := r.client.Set(ctx, key, data, r.ttl).Err(); err != nil {
+		return llm.Usage{}, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return conversation.Usage, nil
+}
+
 func (r *RedisConversationStore) CleanExpiredSessions(ctx context.Context) error {
 	sessions, err := r.client.SMembers(ctx, "sessions").Result()
 	if err != nil {
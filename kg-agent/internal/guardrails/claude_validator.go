@@ -5,14 +5,14 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/povarna/generative-ai-agents/kg-agent/internal/bedrock"
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 )
 
 type ClaudeValidator struct {
-	client *bedrock.Client
+	client llm.Client
 }
 
-func NewClaudeValidator(client *bedrock.Client) *ClaudeValidator {
+func NewClaudeValidator(client llm.Client) *ClaudeValidator {
 	return &ClaudeValidator{
 		client: client,
 	}
@@ -21,7 +21,7 @@ func NewClaudeValidator(client *bedrock.Client) *ClaudeValidator {
 func (v *ClaudeValidator) Validate(ctx context.Context, input string) ValidationResult {
 	prompt := v.buildValidatorPrompt(input)
 
-	response, err := v.client.InvokeModel(ctx, bedrock.ClaudeRequest{
+	response, err := v.client.InvokeModel(ctx, llm.Request{
 		Prompt:      prompt,
 		MaxTokens:   200, // short response needed
 		Temperature: 0.0, // Deterministic
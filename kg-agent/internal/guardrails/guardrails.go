@@ -3,7 +3,7 @@ package guardrails
 import (
 	"context"
 
-	"github.com/povarna/generative-ai-agents/kg-agent/internal/bedrock"
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 	"github.com/rs/zerolog/log"
 )
 
@@ -14,7 +14,7 @@ type Guardrails struct {
 }
 
 func NewGuardrails(
-	claudeClient *bedrock.Client,
+	claudeClient llm.Client,
 ) *Guardrails {
 	return &Guardrails{
 		staticValidator: NewStaticValidator(DefaultBanWords),
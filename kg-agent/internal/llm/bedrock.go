@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/bedrock"
+)
+
+// BedrockClient adapts the Bedrock Claude client to the Client interface
+type BedrockClient struct {
+	client *bedrock.Client
+}
+
+func NewBedrockClient(client *bedrock.Client) *BedrockClient {
+	return &BedrockClient{
+		client: client,
+	}
+}
+
+func (c *BedrockClient) InvokeModel(ctx context.Context, request Request) (*Response, error) {
+	resp, err := c.client.InvokeModel(ctx, toClaudeRequest(request))
+	if err != nil {
+		return nil, err
+	}
+	return fromClaudeResponse(resp), nil
+}
+
+func (c *BedrockClient) InvokeModelStream(ctx context.Context, request Request, callback StreamCallback) (*Response, error) {
+	resp, err := c.client.InvokeModelStream(ctx, toClaudeRequest(request), bedrock.StreamCallback(callback))
+	if err != nil {
+		return nil, err
+	}
+	return fromClaudeResponse(resp), nil
+}
+
+func toClaudeRequest(request Request) bedrock.ClaudeRequest {
+	return bedrock.ClaudeRequest{
+		Prompt:      request.Prompt,
+		MaxTokens:   request.MaxTokens,
+		Temperature: request.Temperature,
+	}
+}
+
+func fromClaudeResponse(resp *bedrock.ClaudeResponse) *Response {
+	return &Response{
+		Content:    resp.Content,
+		StopReason: resp.StopReason,
+		Usage: Usage{
+			InputTokens:  resp.InputTokens,
+			OutputTokens: resp.OutputTokens,
+		},
+	}
+}
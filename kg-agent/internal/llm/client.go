@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/bedrock"
+)
+
+// Supported LLM providers
+const (
+	ProviderBedrock  = "bedrock"  // Claude on AWS Bedrock
+	ProviderOpenAI   = "openai"   // Any OpenAI-compatible chat completions API (OpenAI, vLLM, llama.cpp, ...)
+	ProviderScripted = "scripted" // Deterministic canned responses for tests and offline development
+)
+
+// Request is the message sent to the model
+type Request struct {
+	Prompt      string
+	MaxTokens   int
+	Temperature float64
+}
+
+// Response is the model's response
+type Response struct {
+	Content    string
+	StopReason string
+	Usage      Usage
+}
+
+// StreamCallback receives each chunk of a streamed response
+type StreamCallback func(chunk string) error
+
+// Client is the provider-neutral interface for invoking a model
+type Client interface {
+	InvokeModel(ctx context.Context, request Request) (*Response, error)
+	InvokeModelStream(ctx context.Context, request Request, callback StreamCallback) (*Response, error)
+}
+
+// Config selects the provider and model of a client. It is comparable so components
+// with the same config share a client.
+type Config struct {
+	Provider   string
+	ModelID    string
+	AWSRegion  string // bedrock
+	BaseURL    string // openai, e.g. http://localhost:8000/v1
+	APIKey     string // openai, optional for local servers
+	ScriptPath string // scripted
+}
+
+// LoadConfig reads the LLM config of an agent component (ANSWER, MINI, GUARDRAILS, ...) from
+// the environment. <COMPONENT>_LLM_PROVIDER and <COMPONENT>_LLM_MODEL_ID override LLM_PROVIDER
+// and the provider's default model, which is defaultModelID for Bedrock.
+func LoadConfig(component string, defaultModelID string) Config {
+	provider := getEnv(component+"_LLM_PROVIDER", getEnv("LLM_PROVIDER", ProviderBedrock))
+
+	modelID := os.Getenv(component + "_LLM_MODEL_ID")
+	if modelID == "" {
+		switch provider {
+		case ProviderOpenAI:
+			modelID = os.Getenv("OPENAI_MODEL_ID")
+		case ProviderScripted:
+			modelID = ProviderScripted
+		default:
+			modelID = defaultModelID
+		}
+	}
+
+	return Config{
+		Provider:   provider,
+		ModelID:    modelID,
+		AWSRegion:  os.Getenv("AWS_REGION"),
+		BaseURL:    getEnv("OPENAI_BASE_URL", "http://localhost:8000/v1"),
+		APIKey:     os.Getenv("OPENAI_API_KEY"),
+		ScriptPath: os.Getenv("LLM_SCRIPT_PATH"),
+	}
+}
+
+// NewClient creates a client for the configured provider
+func NewClient(ctx context.Context, cfg Config) (Client, error) {
+	var client Client
+	switch cfg.Provider {
+	case ProviderBedrock:
+		bedrockClient, err := bedrock.NewClient(ctx, cfg.AWSRegion, cfg.ModelID)
+		if err != nil {
+			return nil, err
+		}
+		client = NewBedrockClient(bedrockClient)
+	case ProviderOpenAI:
+		client = NewOpenAIClient(cfg.BaseURL, cfg.APIKey, cfg.ModelID)
+	case ProviderScripted:
+		scriptedClient, err := LoadScriptedClient(cfg.ScriptPath)
+		if err != nil {
+			return nil, err
+		}
+		client = scriptedClient
+	default:
+		return nil, fmt.Errorf("unknown LLM provider: %s (supported: bedrock, openai, scripted)", cfg.Provider)
+	}
+
+	// Token usage of every call is recorded on the meter of the request context
+	return &meteredClient{client: client, modelID: cfg.ModelID}, nil
+}
+
+// Registry creates clients on first use and shares them between components with the same config
+type Registry struct {
+	clients map[Config]Client
+	mu      sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		clients: make(map[Config]Client),
+	}
+}
+
+// Get returns the client for the config, creating it if needed
+func (r *Registry) Get(ctx context.Context, cfg Config) (Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if client, ok := r.clients[cfg]; ok {
+		return client, nil
+	}
+
+	client, err := NewClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	r.clients[cfg] = client
+	return client, nil
+}
+
+func getEnv(key string, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		value = defaultValue
+	}
+	return value
+}
@@ -0,0 +1,213 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAIClient calls an OpenAI-compatible chat completions API. Local servers such as
+// vLLM and llama.cpp expose the same API, the API key is optional for them.
+type OpenAIClient struct {
+	BaseURL    string
+	APIKey     string
+	ModelID    string
+	HTTPClient *http.Client
+}
+
+func NewOpenAIClient(baseURL string, apiKey string, modelID string) *OpenAIClient {
+	return &OpenAIClient{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		APIKey:  apiKey,
+		ModelID: modelID,
+		// No client timeout, streamed answers are bounded by the request context
+		HTTPClient: &http.Client{},
+	}
+}
+
+// Chat completions API request format
+type chatCompletionRequest struct {
+	Model         string         `json:"model"`
+	Messages      []chatMessage  `json:"messages"`
+	MaxTokens     int            `json:"max_tokens,omitempty"`
+	Temperature   float64        `json:"temperature"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *streamOptions `json:"stream_options,omitempty"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// Token counts reported by the API
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Chat completions API response format
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage chatUsage `json:"usage"`
+}
+
+// Streamed chunk format, sent as server-sent events
+type chatCompletionChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"` // Only set on the last chunk
+}
+
+func (c *OpenAIClient) InvokeModel(ctx context.Context, request Request) (*Response, error) {
+	httpResp, err := c.post(ctx, request, false)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	var response chatCompletionResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chat completions response: %w", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return nil, errors.New("chat completions response has no choices")
+	}
+
+	return &Response{
+		Content:    response.Choices[0].Message.Content,
+		StopReason: response.Choices[0].FinishReason,
+		Usage:      response.Usage.toUsage(),
+	}, nil
+}
+
+func (c *OpenAIClient) InvokeModelStream(ctx context.Context, request Request, callback StreamCallback) (*Response, error) {
+	httpResp, err := c.post(ctx, request, true)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	var fullContent strings.Builder
+	var stopReason string
+	var usage Usage
+
+	scanner := bufio.NewScanner(httpResp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			// Just skip chunks we can't parse
+			continue
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage.toUsage()
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		if text := chunk.Choices[0].Delta.Content; text != "" {
+			fullContent.WriteString(text)
+			if callback != nil {
+				if err := callback(text); err != nil {
+					return nil, fmt.Errorf("callback error: %w", err)
+				}
+			}
+		}
+
+		if chunk.Choices[0].FinishReason != "" {
+			stopReason = chunk.Choices[0].FinishReason
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream error: %w", err)
+	}
+
+	return &Response{
+		Content:    fullContent.String(),
+		StopReason: stopReason,
+		Usage:      usage,
+	}, nil
+}
+
+// post sends the chat completions request and returns the response of a successful call
+func (c *OpenAIClient) post(ctx context.Context, request Request, stream bool) (*http.Response, error) {
+	payload := chatCompletionRequest{
+		Model:       c.ModelID,
+		MaxTokens:   request.MaxTokens,
+		Temperature: request.Temperature,
+		Stream:      stream,
+		Messages: []chatMessage{
+			{
+				Role:    "user",
+				Content: request.Prompt,
+			},
+		},
+	}
+
+	if stream {
+		payload.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	httpResp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call chat completions API: %w", err)
+	}
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		defer httpResp.Body.Close()
+		respBody, _ := io.ReadAll(io.LimitReader(httpResp.Body, 512))
+		return nil, fmt.Errorf("chat completions API returned status %d: %s", httpResp.StatusCode, respBody)
+	}
+
+	return httpResp, nil
+}
+
+func (u chatUsage) toUsage() Usage {
+	return Usage{
+		InputTokens:  u.PromptTokens,
+		OutputTokens: u.CompletionTokens,
+	}
+}
@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Usage is the number of tokens consumed by model calls and their estimated cost
+type Usage struct {
+	InputTokens  int     `json:"input_tokens" description:"Prompt tokens"`
+	OutputTokens int     `json:"output_tokens" description:"Generated tokens"`
+	Cost         float64 `json:"cost,omitempty" description:"Estimated cost in USD, omitted when a model has no price"`
+}
+
+// Add accumulates other into the usage
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.Cost += other.Cost
+}
+
+// Price is the price of a model in USD per million tokens
+type Price struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// Prices maps model IDs to their price
+type Prices map[string]Price
+
+// Bedrock cross-region inference profiles prefix the model ID with a geography
+var inferenceProfilePrefixes = []string{"us.", "eu.", "apac.", "global."}
+
+// LoadPrices reads the price table from a JSON file. An empty path returns an empty
+// table, usage is then reported without cost.
+func LoadPrices(path string) (Prices, error) {
+	if path == "" {
+		return Prices{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price file %s: %w", path, err)
+	}
+
+	var prices Prices
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return nil, fmt.Errorf("failed to parse price file %s: %w", path, err)
+	}
+
+	return prices, nil
+}
+
+// Cost returns the estimated cost of the usage on a model, 0 when the model has no price.
+// Inference profile IDs fall back to the price of the base model.
+func (p Prices) Cost(modelID string, usage Usage) float64 {
+	price, ok := p[modelID]
+	if !ok {
+		for _, prefix := range inferenceProfilePrefixes {
+			if baseID, found := strings.CutPrefix(modelID, prefix); found {
+				price, ok = p[baseID]
+				break
+			}
+		}
+	}
+	if !ok {
+		return 0.0
+	}
+
+	return (float64(usage.InputTokens)*price.InputPerMillion + float64(usage.OutputTokens)*price.OutputPerMillion) / 1_000_000
+}
+
+// Meter accumulates the token usage of the model calls made with a context, per model
+type Meter struct {
+	usage map[string]Usage
+	mu    sync.Mutex
+}
+
+type meterKey struct{}
+
+// WithMeter returns a context whose model calls are recorded on a meter. A context that
+// already carries a meter keeps it, so calls made before and after are summed together.
+func WithMeter(ctx context.Context) (context.Context, *Meter) {
+	if meter, ok := ctx.Value(meterKey{}).(*Meter); ok {
+		return ctx, meter
+	}
+
+	meter := &Meter{usage: make(map[string]Usage)}
+	return context.WithValue(ctx, meterKey{}, meter), meter
+}
+
+func (m *Meter) record(modelID string, usage Usage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	total := m.usage[modelID]
+	total.Add(usage)
+	m.usage[modelID] = total
+}
+
+// Total returns the usage of all models, priced with the price table
+func (m *Meter) Total(prices Prices) Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var total Usage
+	for modelID, usage := range m.usage {
+		usage.Cost = prices.Cost(modelID, usage)
+		total.Add(usage)
+	}
+	return total
+}
+
+// meteredClient records the usage of each call on the meter of the context, if any
+type meteredClient struct {
+	client  Client
+	modelID string
+}
+
+func (c *meteredClient) InvokeModel(ctx context.Context, request Request) (*Response, error) {
+	resp, err := c.client.InvokeModel(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	c.record(ctx, resp.Usage)
+	return resp, nil
+}
+
+func (c *meteredClient) InvokeModelStream(ctx context.Context, request Request, callback StreamCallback) (*Response, error) {
+	resp, err := c.client.InvokeModelStream(ctx, request, callback)
+	if err != nil {
+		return nil, err
+	}
+	c.record(ctx, resp.Usage)
+	return resp, nil
+}
+
+func (c *meteredClient) record(ctx context.Context, usage Usage) {
+	if meter, ok := ctx.Value(meterKey{}).(*Meter); ok {
+		meter.record(c.modelID, usage)
+	}
+}
@@ -5,15 +5,15 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/povarna/generative-ai-agents/kg-agent/internal/bedrock"
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 	"github.com/rs/zerolog/log"
 )
 
 type Rewriter struct {
-	claudeClient *bedrock.Client
+	claudeClient llm.Client
 }
 
-func NewRewriter(client *bedrock.Client) *Rewriter {
+func NewRewriter(client llm.Client) *Rewriter {
 	return &Rewriter{
 		claudeClient: client,
 	}
@@ -34,7 +34,7 @@ Rewrite this query to be:
 
 Return ONLY the rewritten query, nothing else.`, originalQuery)
 
-	response, err := r.claudeClient.InvokeModel(ctx, bedrock.ClaudeRequest{
+	response, err := r.claudeClient.InvokeModel(ctx, llm.Request{
 		Prompt:      prompt,
 		MaxTokens:   200,
 		Temperature: 0.2, // Low temperature for consistent rewrite
@@ -67,7 +67,7 @@ func (r *Rewriter) SimplifyQuery(ctx context.Context, complexQuery string) ([]st
 
 Return only the sub-question, one per line. `, complexQuery)
 
-	response, err := r.claudeClient.InvokeModel(ctx, bedrock.ClaudeRequest{
+	response, err := r.claudeClient.InvokeModel(ctx, llm.Request{
 		Prompt:      prompt,
 		MaxTokens:   300,
 		Temperature: 0.3,
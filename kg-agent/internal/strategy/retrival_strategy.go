@@ -5,18 +5,18 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/povarna/generative-ai-agents/kg-agent/internal/bedrock"
 	"github.com/povarna/generative-ai-agents/kg-agent/internal/conversation"
+	"github.com/povarna/generative-ai-agents/kg-agent/internal/llm"
 	"github.com/rs/zerolog/log"
 )
 
 type RetrievalStrategy struct {
-	Client *bedrock.Client
+	Client llm.Client
 }
 
-func NewRetrievalStrategy(bedrockClient *bedrock.Client) *RetrievalStrategy {
+func NewRetrievalStrategy(client llm.Client) *RetrievalStrategy {
 	return &RetrievalStrategy{
-		Client: bedrockClient,
+		Client: client,
 	}
 }
 
@@ -135,7 +135,7 @@ func (r *RetrievalStrategy) hasSimplePronoun(query string) bool {
 func (r *RetrievalStrategy) llmDecide(ctx context.Context, query string, history *conversation.Conversation) (Decision, error) {
 	prompt := r.buildClassificationPrompt(query, history)
 
-	response, err := r.Client.InvokeModel(ctx, bedrock.ClaudeRequest{
+	response, err := r.Client.InvokeModel(ctx, llm.Request{
 		Prompt:      prompt,
 		MaxTokens:   100,
 		Temperature: 0.0,